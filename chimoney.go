@@ -13,20 +13,45 @@ import (
 	"github.com/chimoney/chimoney-go/modules/account"
 	"github.com/chimoney/chimoney-go/modules/info"
 	"github.com/chimoney/chimoney-go/modules/mobilemoney"
+	"github.com/chimoney/chimoney-go/modules/payments"
 	"github.com/chimoney/chimoney-go/modules/payouts"
+	"github.com/chimoney/chimoney-go/modules/payouts/dedupe"
+	"github.com/chimoney/chimoney-go/modules/policy"
 	"github.com/chimoney/chimoney-go/modules/redeem"
 	"github.com/chimoney/chimoney-go/modules/subaccount"
 	"github.com/chimoney/chimoney-go/modules/wallet"
 )
 
+// StatusError is returned when the API answers with a status outside the 2xx range.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("chimoney: request failed with status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("chimoney: request failed with status %d: %s", e.StatusCode, e.Body)
+}
+
+// HTTPStatus returns the status code; the modules match it through this method.
+func (e *StatusError) HTTPStatus() int {
+	return e.StatusCode
+}
+
 type Client struct {
 	apiKey  string
 	baseURL string
 	http    *http.Client
+	policy  *policy.Engine
+	dedupe  []dedupe.Option
+	guarded bool
 
 	Account     *account.Account
 	Info        *info.Info
 	MobileMoney *mobilemoney.MobileMoney
+	Payments    *payments.Payments
 	Payouts     *payouts.Payouts
 	Redeem      *redeem.Redeem
 	SubAccount  *subaccount.SubAccount
@@ -50,13 +75,27 @@ func New(options ...Option) *Client {
 		panic("chimoney: API key is required")
 	}
 
-	c.Account = account.New(c)
-	c.Info = info.New(c)
-	c.MobileMoney = mobilemoney.New(c)
-	c.Payouts = payouts.New(c)
-	c.Redeem = redeem.New(c)
-	c.SubAccount = subaccount.New(c)
-	c.Wallet = wallet.New(c)
+	var client policy.Client = c
+	if c.policy != nil {
+		client = policy.Wrap(client, c.policy)
+	}
+	if c.guarded {
+		client = dedupe.Wrap(client, c.dedupe...)
+	}
+
+	c.Account = account.New(client)
+	c.Info = info.New(client)
+	c.MobileMoney = mobilemoney.New(client)
+	c.Payments = payments.New(client)
+	c.Payouts = payouts.New(client)
+	c.Redeem = redeem.New(client)
+	c.SubAccount = subaccount.New(client)
+	c.Wallet = wallet.New(client)
+
+	// Modules validating against Info lookups share one cache of them.
+	cache := info.NewCache(c.Info)
+	c.MobileMoney.SetInfoCache(cache)
+	c.Payouts.SetInfoCache(cache)
 
 	return c
 }
@@ -81,12 +120,52 @@ func WithSandbox(enabled bool) Option {
 	}
 }
 
+func WithPolicy(engine *policy.Engine) Option {
+	return func(c *Client) {
+		c.policy = engine
+	}
+}
+
+func WithDuplicateGuard(options ...dedupe.Option) Option {
+	return func(c *Client) {
+		c.guarded = true
+		c.dedupe = options
+	}
+}
+
 func (c *Client) Do(ctx context.Context, method, path string, body interface{}, v interface{}, params map[string]string) error {
+	resp, err := c.send(ctx, method, path, body, params)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if v != nil {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			return fmt.Errorf("chimoney: failed to decode response: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// Stream sends a request like Do but returns the body of a successful
+// response unread, so large responses can be decoded as they arrive. The
+// caller closes it.
+func (c *Client) Stream(ctx context.Context, method, path string, body interface{}, params map[string]string) (io.ReadCloser, error) {
+	resp, err := c.send(ctx, method, path, body, params)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (c *Client) send(ctx context.Context, method, path string, body interface{}, params map[string]string) (*http.Response, error) {
 	var reqBody io.Reader
 	if body != nil {
 		b, err := json.Marshal(body)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		reqBody = bytes.NewReader(b)
 	}
@@ -95,7 +174,7 @@ func (c *Client) Do(ctx context.Context, method, path string, body interface{},
 	// Create request with base URL and path
 	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Add query parameters
@@ -124,23 +203,17 @@ func (c *Client) Do(ctx context.Context, method, path string, body interface{},
 
 	resp, err := c.http.Do(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
 		body, err := io.ReadAll(resp.Body)
 		if err != nil {
-			return fmt.Errorf("chimoney: request failed with status %d, failed to read error response: %v", resp.StatusCode, err)
+			return nil, fmt.Errorf("%w, failed to read error response: %v", &StatusError{StatusCode: resp.StatusCode}, err)
 		}
-		return fmt.Errorf("chimoney: request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
-	if v != nil {
-		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
-			return fmt.Errorf("chimoney: failed to decode response: %v", err)
-		}
-	}
-
-	return nil
+	return resp, nil
 }
@@ -0,0 +1,49 @@
+// Package apierr classifies the errors of Chimoney API calls by what they
+// say about the request: whether it was answered, refused or never sent.
+package apierr
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+)
+
+// statusCoder is implemented by the status errors of the Chimoney clients.
+type statusCoder interface {
+	HTTPStatus() int
+}
+
+// Status returns the status code of an API error, or 0 if the request got no answer.
+func Status(err error) int {
+	var sc statusCoder
+	if errors.As(err, &sc) {
+		return sc.HTTPStatus()
+	}
+	return 0
+}
+
+// Rejected reports whether the API refused a request with a 4xx status, so
+// nothing was carried out.
+func Rejected(err error) bool {
+	status := Status(err)
+	return status >= 400 && status < 500
+}
+
+// NotSent reports whether a request failed before it could reach the API: it
+// could not be encoded, or no connection was made. Other errors, such as a
+// timeout, may come after the API acted on the request.
+func NotSent(err error) bool {
+	var (
+		unsupported *json.UnsupportedTypeError
+		value       *json.UnsupportedValueError
+		marshaler   *json.MarshalerError
+		op          *net.OpError
+	)
+	switch {
+	case errors.As(err, &unsupported), errors.As(err, &value), errors.As(err, &marshaler):
+		return true
+	case errors.As(err, &op):
+		return op.Op == "dial"
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+// Package stream lets clients that wrap another client offer streamed
+// responses whether or not the wrapped client can stream.
+package stream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+)
+
+// Streamer is implemented by clients that can return a response body unread.
+type Streamer interface {
+	Stream(ctx context.Context, method, path string, body interface{}, params map[string]string) (io.ReadCloser, error)
+}
+
+// DoFunc is the Do method of a client.
+type DoFunc func(ctx context.Context, method, path string, body interface{}, v interface{}, params map[string]string) error
+
+// Buffered sends a request through do and returns the whole response body as
+// a reader, for wrapped clients that cannot stream or requests that must be
+// checked before they are sent.
+func Buffered(ctx context.Context, do DoFunc, method, path string, body interface{}, params map[string]string) (io.ReadCloser, error) {
+	var raw json.RawMessage
+	if err := do(ctx, method, path, body, &raw, params); err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(raw)), nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"strconv"
 )
 
 var (
@@ -135,3 +136,53 @@ func (a *Account) GetTransactionByID(ctx context.Context, transactionID string,
 	err := a.client.Do(ctx, "POST", "/accounts/transaction", req, resp, nil)
 	return resp, err
 }
+
+/**
+ * This function gets one page of all transactions
+ * @param {string?} subAccount The subAccount of the transaction
+ * @param {number} page The page to get, starting at 1
+ * @param {number} limit The number of transactions per page
+ * @returns The response from the Chimoney API
+ */
+func (a *Account) GetAllTransactionsPage(ctx context.Context, subAccount string, page, limit int) (*AccountResponse, error) {
+	req := map[string]string{}
+	if subAccount != "" {
+		req["subAccount"] = subAccount
+	}
+
+	resp := new(AccountResponse)
+	err := a.client.Do(ctx, "POST", "/accounts/transactions", req, resp, pageParams(page, limit))
+	return resp, err
+}
+
+/**
+ * This function gets one page of the transactions of an issue ID
+ * @param {string} issueID The ID of the issue
+ * @param {string?} subAccount The subAccount of the transaction
+ * @param {number} page The page to get, starting at 1
+ * @param {number} limit The number of transactions per page
+ * @returns The response from the Chimoney API
+ */
+func (a *Account) GetTransactionsByIssueIDPage(ctx context.Context, issueID string, subAccount string, page, limit int) (*AccountResponse, error) {
+	if issueID == "" {
+		return nil, ErrInvalidIssueID
+	}
+
+	req := map[string]string{}
+	if subAccount != "" {
+		req["subAccount"] = subAccount
+	}
+
+	resp := new(AccountResponse)
+	params := pageParams(page, limit)
+	params["issueID"] = issueID
+	err := a.client.Do(ctx, "POST", "/accounts/issue-id-transactions", req, resp, params)
+	return resp, err
+}
+
+func pageParams(page, limit int) map[string]string {
+	return map[string]string{
+		"page":  strconv.Itoa(page),
+		"limit": strconv.Itoa(limit),
+	}
+}
@@ -0,0 +1,453 @@
+package reconcile
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chimoney/chimoney-go/modules/account"
+)
+
+// LedgerEntry is a payout as recorded on our side. Entries without a ChiRef
+// or Reference are matched by amount, and by Date when it is set.
+type LedgerEntry struct {
+	Reference  string    `json:"reference,omitempty"`
+	ChiRef     string    `json:"chiRef,omitempty"`
+	IssueID    string    `json:"issueID,omitempty"`
+	AmountUSD  float64   `json:"amountUSD"`
+	Status     string    `json:"status,omitempty"`
+	SubAccount string    `json:"subAccount,omitempty"`
+	Date       time.Time `json:"date"`
+}
+
+type Outcome string
+
+const (
+	Matched        Outcome = "matched"
+	Missing        Outcome = "missing_on_chimoney"
+	Unexpected     Outcome = "unexpected_on_chimoney"
+	AmountMismatch Outcome = "amount_mismatch"
+	StatusMismatch Outcome = "status_mismatch"
+)
+
+type Record struct {
+	Outcome     Outcome              `json:"outcome"`
+	Ledger      *LedgerEntry         `json:"ledger,omitempty"`
+	Transaction *account.Transaction `json:"transaction,omitempty"`
+	Detail      string               `json:"detail,omitempty"`
+}
+
+type Report struct {
+	GeneratedAt      time.Time `json:"generatedAt"`
+	Matched          []Record  `json:"matched"`
+	Missing          []Record  `json:"missing"`
+	Unexpected       []Record  `json:"unexpected"`
+	AmountMismatches []Record  `json:"amountMismatches"`
+	StatusMismatches []Record  `json:"statusMismatches"`
+}
+
+type Reconciler struct {
+	account     *account.Account
+	subAccounts []string
+	tolerance   float64
+	window      time.Duration
+	pageSize    int
+	from, to    time.Time
+}
+
+type Option func(*Reconciler)
+
+func New(a *account.Account, options ...Option) *Reconciler {
+	r := &Reconciler{
+		account:   a,
+		tolerance: 0.01,
+		window:    24 * time.Hour,
+		pageSize:  100,
+	}
+
+	for _, opt := range options {
+		opt(r)
+	}
+	if r.pageSize <= 0 {
+		r.pageSize = 100
+	}
+
+	return r
+}
+
+// WithSubAccounts adds sub-accounts whose transactions are fetched alongside
+// the main account and any sub-account named in the ledger.
+func WithSubAccounts(ids ...string) Option {
+	return func(r *Reconciler) {
+		r.subAccounts = append(r.subAccounts, ids...)
+	}
+}
+
+func WithAmountTolerance(usd float64) Option {
+	return func(r *Reconciler) {
+		r.tolerance = usd
+	}
+}
+
+// WithDateWindow sets how far apart the ledger date and the payment date of a
+// transaction matched by amount may be, a day by default.
+func WithDateWindow(d time.Duration) Option {
+	return func(r *Reconciler) {
+		r.window = d
+	}
+}
+
+// WithPeriod limits the unexpected transactions reported to those paid from
+// from up to, but not including, to. Transactions without a payment date are
+// then not reported. Ledger entries are still matched against any transaction.
+func WithPeriod(from, to time.Time) Option {
+	return func(r *Reconciler) {
+		r.from, r.to = from, to
+	}
+}
+
+// WithPageSize sets how many transactions are asked for per request, 100 by default.
+func WithPageSize(n int) Option {
+	return func(r *Reconciler) {
+		r.pageSize = n
+	}
+}
+
+/**
+ * This function reconciles a ledger of submitted payouts against Chimoney's transactions
+ * @param {LedgerEntry[]} ledger The payouts we believe were sent
+ * @returns A report of matched, missing, unexpected and mismatched records
+ */
+func (r *Reconciler) Reconcile(ctx context.Context, ledger []LedgerEntry) (*Report, error) {
+	pool, err := r.fetch(ctx, ledger)
+	if err != nil {
+		return nil, err
+	}
+
+	byChiRef := make(map[string][]int)
+	byReference := make(map[string][]int)
+	for i, tx := range pool {
+		if tx.ChiRef != "" {
+			byChiRef[tx.ChiRef] = append(byChiRef[tx.ChiRef], i)
+		}
+		if ref := reference(tx); ref != "" {
+			byReference[ref] = append(byReference[ref], i)
+		}
+	}
+
+	used := make([]bool, len(pool))
+	// next takes the first transaction of the list that is not matched yet,
+	// so repeated references pair up in order.
+	next := func(indexes []int) int {
+		for _, idx := range indexes {
+			if !used[idx] {
+				return idx
+			}
+		}
+		return -1
+	}
+
+	claimed := make(map[string]bool)
+	matches := make([]int, len(ledger))
+	for i := range ledger {
+		entry := &ledger[i]
+		claimed[entry.ChiRef] = true
+		claimed[entry.Reference] = true
+
+		idx := -1
+		if entry.ChiRef != "" {
+			idx = next(byChiRef[entry.ChiRef])
+		}
+		if idx < 0 && entry.Reference != "" {
+			idx = next(byReference[entry.Reference])
+		}
+		if idx >= 0 {
+			used[idx] = true
+		}
+		matches[i] = idx
+	}
+	delete(claimed, "")
+
+	// Entries without a ChiRef or Reference take the closest transaction by
+	// amount and date that no other entry refers to.
+	byAmount := make([]bool, len(ledger))
+	for i := range ledger {
+		entry := &ledger[i]
+		if entry.ChiRef != "" || entry.Reference != "" {
+			continue
+		}
+		if idx := r.closest(entry, pool, used, claimed); idx >= 0 {
+			used[idx] = true
+			matches[i] = idx
+			byAmount[i] = true
+		}
+	}
+
+	report := &Report{GeneratedAt: time.Now().UTC()}
+	for i := range ledger {
+		entry := &ledger[i]
+		if matches[i] < 0 {
+			report.Missing = append(report.Missing, Record{Outcome: Missing, Ledger: entry})
+			continue
+		}
+
+		tx := &pool[matches[i]]
+		var detail string
+		if byAmount[i] {
+			detail = "matched by amount"
+		}
+		switch {
+		case math.Abs(tx.ValueInUSD-entry.AmountUSD) > r.tolerance:
+			report.AmountMismatches = append(report.AmountMismatches, Record{
+				Outcome: AmountMismatch, Ledger: entry, Transaction: tx,
+				Detail: fmt.Sprintf("ledger $%.2f, chimoney $%.2f", entry.AmountUSD, tx.ValueInUSD),
+			})
+		case entry.Status != "" && !strings.EqualFold(entry.Status, tx.Status):
+			report.StatusMismatches = append(report.StatusMismatches, Record{
+				Outcome: StatusMismatch, Ledger: entry, Transaction: tx,
+				Detail: fmt.Sprintf("ledger %q, chimoney %q", entry.Status, tx.Status),
+			})
+		default:
+			report.Matched = append(report.Matched, Record{Outcome: Matched, Ledger: entry, Transaction: tx, Detail: detail})
+		}
+	}
+
+	for i := range pool {
+		if !used[i] && r.inPeriod(pool[i]) {
+			report.Unexpected = append(report.Unexpected, Record{Outcome: Unexpected, Transaction: &pool[i]})
+		}
+	}
+
+	return report, nil
+}
+
+// closest finds the unmatched, unclaimed transaction within the tolerance of
+// the entry's amount and the date window of its date, nearest in time first.
+func (r *Reconciler) closest(entry *LedgerEntry, pool []account.Transaction, used []bool, claimed map[string]bool) int {
+	best, bestGap := -1, time.Duration(0)
+	for i, tx := range pool {
+		if used[i] || claimed[tx.ChiRef] || claimed[reference(tx)] {
+			continue
+		}
+		if entry.IssueID != "" && tx.IssueID != entry.IssueID {
+			continue
+		}
+		if math.Abs(tx.ValueInUSD-entry.AmountUSD) > r.tolerance {
+			continue
+		}
+
+		var gap time.Duration
+		if paid, ok := paymentDate(tx); ok && !entry.Date.IsZero() {
+			gap = paid.Sub(entry.Date)
+			if gap < 0 {
+				gap = -gap
+			}
+			if gap > r.window {
+				continue
+			}
+		}
+		if best < 0 || gap < bestGap {
+			best, bestGap = i, gap
+		}
+	}
+	return best
+}
+
+func (r *Reconciler) inPeriod(tx account.Transaction) bool {
+	if r.from.IsZero() && r.to.IsZero() {
+		return true
+	}
+	paid, ok := paymentDate(tx)
+	if !ok {
+		return false
+	}
+	return !paid.Before(r.from) && (r.to.IsZero() || paid.Before(r.to))
+}
+
+func paymentDate(tx account.Transaction) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, tx.PaymentDate); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// fetch lists the transactions of every relevant account, then looks up the
+// issue IDs of ledger entries that are still unaccounted for.
+func (r *Reconciler) fetch(ctx context.Context, ledger []LedgerEntry) ([]account.Transaction, error) {
+	subAccounts := []string{""}
+	seenSub := map[string]bool{"": true}
+	for _, id := range r.subAccounts {
+		if !seenSub[id] {
+			seenSub[id] = true
+			subAccounts = append(subAccounts, id)
+		}
+	}
+	for _, e := range ledger {
+		if !seenSub[e.SubAccount] {
+			seenSub[e.SubAccount] = true
+			subAccounts = append(subAccounts, e.SubAccount)
+		}
+	}
+
+	var pool []account.Transaction
+	seen := make(map[string]bool)
+	add := func(txns []account.Transaction) int {
+		added := 0
+		for _, tx := range txns {
+			key := tx.ID
+			if key == "" {
+				key = tx.ChiRef
+			}
+			if key != "" && seen[key] {
+				continue
+			}
+			seen[key] = true
+			pool = append(pool, tx)
+			added++
+		}
+		return added
+	}
+
+	for _, sub := range subAccounts {
+		sub := sub
+		err := r.pages(func(page int) (*account.AccountResponse, error) {
+			return r.account.GetAllTransactionsPage(ctx, sub, page, r.pageSize)
+		}, add)
+		if err != nil {
+			return nil, fmt.Errorf("reconcile: listing transactions for %q: %w", sub, err)
+		}
+	}
+
+	known := make(map[string]bool)
+	for _, tx := range pool {
+		known[tx.ChiRef] = true
+		known[reference(tx)] = true
+	}
+	fetchedIssue := make(map[string]bool)
+	for _, e := range ledger {
+		if e.IssueID == "" || fetchedIssue[e.IssueID] {
+			continue
+		}
+		if (e.ChiRef != "" && known[e.ChiRef]) || (e.Reference != "" && known[e.Reference]) {
+			continue
+		}
+		fetchedIssue[e.IssueID] = true
+		e := e
+		err := r.pages(func(page int) (*account.AccountResponse, error) {
+			return r.account.GetTransactionsByIssueIDPage(ctx, e.IssueID, e.SubAccount, page, r.pageSize)
+		}, add)
+		if err != nil {
+			return nil, fmt.Errorf("reconcile: fetching issue %s: %w", e.IssueID, err)
+		}
+	}
+
+	return pool, nil
+}
+
+// pages fetches pages until one is short, or adds nothing new in case the
+// API returned the same transactions again.
+func (r *Reconciler) pages(fetch func(page int) (*account.AccountResponse, error), add func([]account.Transaction) int) error {
+	for page := 1; ; page++ {
+		resp, err := fetch(page)
+		if err != nil {
+			return err
+		}
+		txns, err := resp.Transactions()
+		if err != nil {
+			return err
+		}
+		if add(txns) == 0 || len(txns) < r.pageSize {
+			return nil
+		}
+	}
+}
+
+func reference(tx account.Transaction) string {
+	if tx.Reference != "" {
+		return tx.Reference
+	}
+	if ref, ok := tx.Meta["reference"].(string); ok {
+		return ref
+	}
+	return ""
+}
+
+/**
+ * This function returns every record of the report, matched records first
+ * @returns The records in a stable order
+ */
+func (r *Report) Records() []Record {
+	var all []Record
+	all = append(all, r.Matched...)
+	all = append(all, r.AmountMismatches...)
+	all = append(all, r.StatusMismatches...)
+	all = append(all, r.Missing...)
+	all = append(all, r.Unexpected...)
+	return all
+}
+
+/**
+ * This function writes the report as JSON
+ * @param {io.Writer} w The destination
+ */
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+/**
+ * This function writes one CSV row per record with a header row
+ * @param {io.Writer} w The destination
+ */
+func (r *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := []string{
+		"outcome", "reference", "chi_ref", "issue_id", "sub_account",
+		"ledger_amount_usd", "chimoney_amount_usd", "ledger_status", "chimoney_status", "detail",
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, rec := range r.Records() {
+		row := make([]string, len(header))
+		row[0] = string(rec.Outcome)
+		if l := rec.Ledger; l != nil {
+			row[1], row[2], row[3], row[4] = l.Reference, l.ChiRef, l.IssueID, l.SubAccount
+			row[5] = strconv.FormatFloat(l.AmountUSD, 'f', 2, 64)
+			row[7] = l.Status
+		}
+		if tx := rec.Transaction; tx != nil {
+			if row[1] == "" {
+				row[1] = reference(*tx)
+			}
+			if row[2] == "" {
+				row[2] = tx.ChiRef
+			}
+			if row[3] == "" {
+				row[3] = tx.IssueID
+			}
+			if row[4] == "" {
+				row[4] = tx.SubAccount
+			}
+			row[6] = strconv.FormatFloat(tx.ValueInUSD, 'f', 2, 64)
+			row[8] = tx.Status
+		}
+		row[9] = rec.Detail
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
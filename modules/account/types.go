@@ -0,0 +1,48 @@
+package account
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type Transaction struct {
+	ID          string                 `json:"id"`
+	ChiRef      string                 `json:"chiRef"`
+	IssueID     string                 `json:"issueID"`
+	Reference   string                 `json:"reference,omitempty"`
+	ValueInUSD  float64                `json:"valueInUSD"`
+	Status      string                 `json:"status"`
+	Type        string                 `json:"type,omitempty"`
+	Email       string                 `json:"email,omitempty"`
+	SubAccount  string                 `json:"subAccount,omitempty"`
+	PaymentDate string                 `json:"paymentDate,omitempty"`
+	Meta        map[string]interface{} `json:"meta,omitempty"`
+}
+
+/**
+ * This function decodes the data of a transactions response
+ * @returns The transactions, whether the data is a bare array, a single object or wrapped in a "transactions" field
+ */
+func (r *AccountResponse) Transactions() ([]Transaction, error) {
+	if len(r.Data) == 0 || string(r.Data) == "null" {
+		return nil, nil
+	}
+
+	var txns []Transaction
+	if err := json.Unmarshal(r.Data, &txns); err == nil {
+		return txns, nil
+	}
+
+	var wrapped struct {
+		Transactions *[]Transaction `json:"transactions"`
+	}
+	if err := json.Unmarshal(r.Data, &wrapped); err == nil && wrapped.Transactions != nil {
+		return *wrapped.Transactions, nil
+	}
+
+	var single Transaction
+	if err := json.Unmarshal(r.Data, &single); err != nil {
+		return nil, fmt.Errorf("account: unexpected transactions data: %v", err)
+	}
+	return []Transaction{single}, nil
+}
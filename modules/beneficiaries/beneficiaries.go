@@ -0,0 +1,456 @@
+package beneficiaries
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/chimoney/chimoney-go/modules/info"
+	"github.com/chimoney/chimoney-go/modules/payouts"
+)
+
+var (
+	ErrNotFound           = errors.New("beneficiary not found")
+	ErrVersionConflict    = errors.New("beneficiary was changed concurrently")
+	ErrInvalidBeneficiary = errors.New("invalid beneficiary")
+	ErrNoMethod           = errors.New("beneficiary has no matching payout method")
+)
+
+type MethodType string
+
+const (
+	MethodBank        MethodType = "bank"
+	MethodMobileMoney MethodType = "mobile_money"
+	MethodAirtime     MethodType = "airtime"
+	MethodChimoney    MethodType = "chimoney"
+)
+
+// Method is one way of paying a beneficiary. Only the fields of its type are used.
+type Method struct {
+	Type            MethodType `json:"type"`
+	Country         string     `json:"country,omitempty"`
+	BankCode        string     `json:"bankCode,omitempty"`
+	AccountNumber   string     `json:"accountNumber,omitempty"`
+	PhoneNumber     string     `json:"phoneNumber,omitempty"`
+	MobileMoneyCode string     `json:"mobileMoneyCode,omitempty"`
+	Email           string     `json:"email,omitempty"`
+	Twitter         string     `json:"twitter,omitempty"`
+}
+
+// Beneficiary is a named recipient. Methods are in order of preference.
+// Version and UpdatedAt are set by the Book on every change. Deleted marks the
+// version recorded when the beneficiary was deleted.
+type Beneficiary struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Methods   []Method  `json:"methods"`
+	Version   int       `json:"version"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Deleted   bool      `json:"deleted,omitempty"`
+}
+
+// Change lists the fields that differ between a version and the one before it.
+type Change struct {
+	Version   int       `json:"version"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Fields    []string  `json:"fields"`
+}
+
+// Payload is the payout for one beneficiary. Exactly one of its payloads is set.
+type Payload struct {
+	Method      MethodType
+	Bank        *payouts.BankPayload
+	MobileMoney *payouts.MobileMoneyPayload
+	Airtime     *payouts.AirtimePayload
+	Chimoney    *payouts.ChimoneyPayload
+}
+
+type Book struct {
+	store Store
+	cache *info.Cache
+	ttl   time.Duration
+	now   func() time.Time
+}
+
+type Option func(*Book)
+
+func New(i *info.Info, store Store, options ...Option) *Book {
+	b := &Book{
+		store: store,
+		ttl:   time.Hour,
+		now:   time.Now,
+	}
+
+	for _, opt := range options {
+		opt(b)
+	}
+	if b.cache == nil {
+		b.cache = info.NewCache(i, info.WithTTL(b.ttl), info.WithClock(b.now))
+	}
+
+	return b
+}
+
+/**
+ * This function opens an address book kept in a directory
+ * @param {string} dir The directory of the beneficiary files, created if missing
+ * @returns The address book backed by a FileStore
+ */
+func Open(dir string, i *info.Info, options ...Option) (*Book, error) {
+	store, err := NewFileStore(dir)
+	if err != nil {
+		return nil, err
+	}
+	return New(i, store, options...), nil
+}
+
+// WithInfoCache shares the cache of banks and mobile money codes with other
+// modules. WithCacheTTL and WithClock then only apply to UpdatedAt.
+func WithInfoCache(c *info.Cache) Option {
+	return func(b *Book) {
+		b.cache = c
+	}
+}
+
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(b *Book) {
+		b.ttl = ttl
+	}
+}
+
+func WithClock(now func() time.Time) Option {
+	return func(b *Book) {
+		b.now = now
+	}
+}
+
+/**
+ * This function validates and saves a beneficiary as a new version
+ * @param {Beneficiary} beneficiary The beneficiary; saving it unchanged does not add a version
+ * @param {number} beneficiary.Version The version the changes were made to, 0 for a new or deleted beneficiary
+ * @returns The saved version, or ErrVersionConflict if the beneficiary changed since
+ */
+func (b *Book) Save(ctx context.Context, beneficiary Beneficiary) (*Beneficiary, error) {
+	if err := b.Validate(ctx, beneficiary); err != nil {
+		return nil, err
+	}
+
+	versions, err := b.store.Versions(beneficiary.ID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	var latest Beneficiary
+	if len(versions) > 0 {
+		latest = versions[len(versions)-1]
+	}
+	if latest.Deleted && beneficiary.Version == 0 {
+		beneficiary.Version = latest.Version
+	}
+	if beneficiary.Version != latest.Version {
+		return nil, fmt.Errorf("%w: %s is at version %d, got %d", ErrVersionConflict, beneficiary.ID, latest.Version, beneficiary.Version)
+	}
+	next := beneficiary.clone()
+	next.Deleted = false
+	if latest.Version > 0 && len(diff(latest, next)) == 0 {
+		return &latest, nil
+	}
+	next.Version = latest.Version + 1
+	next.UpdatedAt = b.now()
+
+	if err := b.store.Append(next); err != nil {
+		return nil, err
+	}
+	return &next, nil
+}
+
+/**
+ * This function checks a beneficiary and its methods against the supported banks and providers
+ * @param {Beneficiary} beneficiary The beneficiary to check
+ * @returns ErrInvalidBeneficiary describing the first problem found
+ */
+func (b *Book) Validate(ctx context.Context, beneficiary Beneficiary) error {
+	if err := validID(beneficiary.ID); err != nil {
+		return err
+	}
+	if strings.TrimSpace(beneficiary.Name) == "" {
+		return fmt.Errorf("%w: %s has no name", ErrInvalidBeneficiary, beneficiary.ID)
+	}
+	if len(beneficiary.Methods) == 0 {
+		return fmt.Errorf("%w: %s has no payout method", ErrInvalidBeneficiary, beneficiary.ID)
+	}
+
+	for i, m := range beneficiary.Methods {
+		reason, err := b.check(ctx, m)
+		if err != nil {
+			return err
+		}
+		if reason != "" {
+			return fmt.Errorf("%w: %s method %d: %s", ErrInvalidBeneficiary, beneficiary.ID, i, reason)
+		}
+	}
+	return nil
+}
+
+/**
+ * This function gets the latest version of a beneficiary
+ * @param {string} id The ID of the beneficiary
+ * @returns The beneficiary, or ErrNotFound
+ */
+func (b *Book) Get(id string) (*Beneficiary, error) {
+	versions, err := b.store.Versions(id)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 || versions[len(versions)-1].Deleted {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	latest := versions[len(versions)-1]
+	return &latest, nil
+}
+
+/**
+ * This function lists the latest version of every beneficiary that is not deleted
+ * @returns The beneficiaries, sorted by ID
+ */
+func (b *Book) List() ([]Beneficiary, error) {
+	ids, err := b.store.IDs()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]Beneficiary, 0, len(ids))
+	for _, id := range ids {
+		versions, err := b.store.Versions(id)
+		if err != nil {
+			return nil, err
+		}
+		if len(versions) == 0 {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+		if latest := versions[len(versions)-1]; !latest.Deleted {
+			list = append(list, latest)
+		}
+	}
+	return list, nil
+}
+
+/**
+ * This function gets every version of a beneficiary, including deleted ones
+ * @param {string} id The ID of the beneficiary
+ * @returns The versions, oldest first
+ */
+func (b *Book) History(id string) ([]Beneficiary, error) {
+	return b.store.Versions(id)
+}
+
+/**
+ * This function describes what changed in each version of a beneficiary
+ * @param {string} id The ID of the beneficiary
+ * @returns One change per version after the first, oldest first
+ */
+func (b *Book) Changes(id string) ([]Change, error) {
+	versions, err := b.store.Versions(id)
+	if err != nil {
+		return nil, err
+	}
+	var changes []Change
+	for i := 1; i < len(versions); i++ {
+		changes = append(changes, Change{
+			Version:   versions[i].Version,
+			UpdatedAt: versions[i].UpdatedAt,
+			Fields:    diff(versions[i-1], versions[i]),
+		})
+	}
+	return changes, nil
+}
+
+/**
+ * This function deletes a beneficiary by saving a version marked as deleted, so its history is kept
+ * @param {string} id The ID of the beneficiary
+ * @returns ErrNotFound if the beneficiary does not exist or is already deleted
+ */
+func (b *Book) Delete(id string) error {
+	if err := validID(id); err != nil {
+		return err
+	}
+	latest, err := b.Get(id)
+	if err != nil {
+		return err
+	}
+	tombstone := latest.clone()
+	tombstone.Version++
+	tombstone.UpdatedAt = b.now()
+	tombstone.Deleted = true
+	return b.store.Append(tombstone)
+}
+
+/**
+ * This function builds the payout for a beneficiary
+ * @param {number} amountUSD The amount to pay in USD
+ * @param {string?} reference The reference of the payout, for the rails that accept one
+ * @param {MethodType[]?} prefer The method types to use, in order; the beneficiary's own order if empty
+ * @returns The payload of the first matching method
+ */
+func (ben Beneficiary) Payload(amountUSD float64, reference string, prefer ...MethodType) (*Payload, error) {
+	if amountUSD <= 0 {
+		return nil, payouts.ErrInvalidPayoutAmount
+	}
+
+	method, ok := ben.method(prefer)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoMethod, ben.ID)
+	}
+
+	p := &Payload{Method: method.Type}
+	switch method.Type {
+	case MethodBank:
+		p.Bank = &payouts.BankPayload{
+			CountryToSend: method.Country,
+			AccountBank:   method.BankCode,
+			AccountNumber: method.AccountNumber,
+			ValueInUSD:    amountUSD,
+			Reference:     reference,
+		}
+	case MethodMobileMoney:
+		p.MobileMoney = &payouts.MobileMoneyPayload{
+			CountryToSend: method.Country,
+			PhoneNumber:   method.PhoneNumber,
+			MomoCode:      method.MobileMoneyCode,
+			ValueInUSD:    amountUSD,
+			Reference:     reference,
+		}
+	case MethodAirtime:
+		p.Airtime = &payouts.AirtimePayload{
+			CountryToSend: method.Country,
+			PhoneNumber:   method.PhoneNumber,
+			ValueInUSD:    amountUSD,
+		}
+	case MethodChimoney:
+		p.Chimoney = &payouts.ChimoneyPayload{
+			ValueInUSD: amountUSD,
+			Email:      method.Email,
+			Twitter:    method.Twitter,
+		}
+	}
+	return p, nil
+}
+
+func (ben Beneficiary) method(prefer []MethodType) (Method, bool) {
+	if len(prefer) == 0 {
+		if len(ben.Methods) == 0 {
+			return Method{}, false
+		}
+		return ben.Methods[0], true
+	}
+	for _, t := range prefer {
+		for _, m := range ben.Methods {
+			if m.Type == t {
+				return m, true
+			}
+		}
+	}
+	return Method{}, false
+}
+
+func (b *Book) check(ctx context.Context, m Method) (string, error) {
+	switch m.Type {
+	case MethodBank:
+		if m.Country == "" || m.BankCode == "" || m.AccountNumber == "" {
+			return "bank method needs a country, bank code and account number", nil
+		}
+		banks, err := b.cache.Banks(ctx, m.Country)
+		if err != nil {
+			return "", err
+		}
+		for _, bank := range banks {
+			if strings.EqualFold(bank.Code, m.BankCode) {
+				return "", nil
+			}
+		}
+		return fmt.Sprintf("bank %s is not supported in %s", m.BankCode, m.Country), nil
+
+	case MethodMobileMoney:
+		if m.Country == "" || m.PhoneNumber == "" || m.MobileMoneyCode == "" {
+			return "mobile money method needs a country, phone number and provider code", nil
+		}
+		codes, err := b.cache.MobileMoneyCodes(ctx)
+		if err != nil {
+			return "", err
+		}
+		for _, c := range codes {
+			if c.Country != "" && !strings.EqualFold(c.Country, m.Country) {
+				continue
+			}
+			if strings.EqualFold(c.Code, m.MobileMoneyCode) {
+				return "", nil
+			}
+		}
+		return fmt.Sprintf("mobile money provider %s is not supported in %s", m.MobileMoneyCode, m.Country), nil
+
+	case MethodAirtime:
+		if m.Country == "" || m.PhoneNumber == "" {
+			return "airtime method needs a country and phone number", nil
+		}
+		return "", nil
+
+	case MethodChimoney:
+		if m.Email == "" && m.Twitter == "" {
+			return "chimoney method needs an email or twitter handle", nil
+		}
+		return "", nil
+	}
+
+	return fmt.Sprintf("unknown method type %q", m.Type), nil
+}
+
+// diff names the fields that differ between two versions, such as
+// "methods[0].accountNumber". Version and UpdatedAt are ignored.
+func diff(a, b Beneficiary) []string {
+	var fields []string
+	if a.Name != b.Name {
+		fields = append(fields, "name")
+	}
+	if a.Deleted != b.Deleted {
+		fields = append(fields, "deleted")
+	}
+
+	n := len(a.Methods)
+	if len(b.Methods) > n {
+		n = len(b.Methods)
+	}
+	for i := 0; i < n; i++ {
+		switch {
+		case i >= len(a.Methods):
+			fields = append(fields, fmt.Sprintf("methods[%d] added", i))
+			continue
+		case i >= len(b.Methods):
+			fields = append(fields, fmt.Sprintf("methods[%d] removed", i))
+			continue
+		}
+		va, vb := reflect.ValueOf(a.Methods[i]), reflect.ValueOf(b.Methods[i])
+		for f := 0; f < va.NumField(); f++ {
+			if va.Field(f).Interface() != vb.Field(f).Interface() {
+				tag := strings.Split(va.Type().Field(f).Tag.Get("json"), ",")[0]
+				fields = append(fields, fmt.Sprintf("methods[%d].%s", i, tag))
+			}
+		}
+	}
+	return fields
+}
+
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,127}$`)
+
+func validID(id string) error {
+	if !idPattern.MatchString(id) {
+		return fmt.Errorf("%w: invalid ID %q", ErrInvalidBeneficiary, id)
+	}
+	return nil
+}
+
+func (ben Beneficiary) clone() Beneficiary {
+	ben.Methods = append([]Method(nil), ben.Methods...)
+	return ben
+}
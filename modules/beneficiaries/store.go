@@ -0,0 +1,183 @@
+package beneficiaries
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Store keeps every version of every beneficiary.
+type Store interface {
+	// Append adds a version of a beneficiary. It fails with ErrVersionConflict
+	// unless the version directly follows the latest one stored.
+	Append(b Beneficiary) error
+	// Versions returns every version of a beneficiary, oldest first, or
+	// ErrNotFound.
+	Versions(id string) ([]Beneficiary, error)
+	// IDs returns the IDs of all stored beneficiaries, sorted, including
+	// deleted ones.
+	IDs() ([]string, error)
+}
+
+type MemoryStore struct {
+	mu       sync.Mutex
+	versions map[string][]Beneficiary
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{versions: make(map[string][]Beneficiary)}
+}
+
+func (s *MemoryStore) Append(b Beneficiary) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := checkVersion(s.versions[b.ID], b); err != nil {
+		return err
+	}
+	s.versions[b.ID] = append(s.versions[b.ID], b.clone())
+	return nil
+}
+
+func (s *MemoryStore) Versions(id string) ([]Beneficiary, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions, ok := s.versions[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	out := make([]Beneficiary, len(versions))
+	for i, v := range versions {
+		out[i] = v.clone()
+	}
+	return out, nil
+}
+
+func (s *MemoryStore) IDs() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]string, 0, len(s.versions))
+	for id := range s.versions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// FileStore keeps one JSON file per beneficiary in a directory, holding all
+// of its versions. Files are replaced atomically on every change.
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) Append(b Beneficiary) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions, err := s.read(b.ID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	if err := checkVersion(versions, b); err != nil {
+		return err
+	}
+	return s.write(b.ID, append(versions, b))
+}
+
+func (s *FileStore) Versions(id string) ([]Beneficiary, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.read(id)
+}
+
+func (s *FileStore) IDs() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+			ids = append(ids, strings.TrimSuffix(e.Name(), ".json"))
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (s *FileStore) read(id string) ([]Beneficiary, error) {
+	name, err := s.path(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var versions []Beneficiary
+	if err := json.Unmarshal(data, &versions); err != nil {
+		return nil, fmt.Errorf("beneficiaries: corrupt file for %s: %v", id, err)
+	}
+	return versions, nil
+}
+
+func (s *FileStore) write(id string, versions []Beneficiary) error {
+	name, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(versions, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, "."+id+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+func (s *FileStore) path(id string) (string, error) {
+	if err := validID(id); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.dir, id+".json"), nil
+}
+
+func checkVersion(versions []Beneficiary, b Beneficiary) error {
+	latest := 0
+	if len(versions) > 0 {
+		latest = versions[len(versions)-1].Version
+	}
+	if b.Version != latest+1 {
+		return fmt.Errorf("%w: %s is at version %d, got %d", ErrVersionConflict, b.ID, latest, b.Version)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package info
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache keeps the reference lists of Info, such as banks and mobile money
+// codes, for a TTL. The modules validating payouts against those lists share
+// one so the API is asked once per TTL. It is safe for concurrent use.
+type Cache struct {
+	info *Info
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu      sync.Mutex
+	banks   map[string]cachedBanks
+	momo    *cachedCodes
+	airtime *cachedCountries
+}
+
+type cachedBanks struct {
+	banks   []Bank
+	fetched time.Time
+}
+
+type cachedCodes struct {
+	codes   []MobileMoneyCode
+	fetched time.Time
+}
+
+type cachedCountries struct {
+	countries []string
+	fetched   time.Time
+}
+
+type CacheOption func(*Cache)
+
+// WithTTL sets how long a list is kept before it is fetched again, an hour by default.
+func WithTTL(ttl time.Duration) CacheOption {
+	return func(c *Cache) {
+		c.ttl = ttl
+	}
+}
+
+func WithClock(now func() time.Time) CacheOption {
+	return func(c *Cache) {
+		c.now = now
+	}
+}
+
+func NewCache(i *Info, options ...CacheOption) *Cache {
+	c := &Cache{
+		info:  i,
+		ttl:   time.Hour,
+		now:   time.Now,
+		banks: make(map[string]cachedBanks),
+	}
+
+	for _, opt := range options {
+		opt(c)
+	}
+
+	return c
+}
+
+/**
+ * This function gets the banks of a country, from the cache while it is fresh
+ * @param {string} country The country code
+ * @returns The banks of the country
+ */
+func (c *Cache) Banks(ctx context.Context, country string) ([]Bank, error) {
+	country = strings.ToUpper(country)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if b, ok := c.banks[country]; ok && c.now().Sub(b.fetched) < c.ttl {
+		return b.banks, nil
+	}
+
+	resp, err := c.info.GetBanks(ctx, country)
+	if err != nil {
+		return nil, err
+	}
+	banks, err := resp.Banks()
+	if err != nil {
+		return nil, err
+	}
+	c.banks[country] = cachedBanks{banks: banks, fetched: c.now()}
+	return banks, nil
+}
+
+/**
+ * This function gets the mobile money codes, from the cache while they are fresh
+ * @returns The mobile money providers the API accepts
+ */
+func (c *Cache) MobileMoneyCodes(ctx context.Context) ([]MobileMoneyCode, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.momo != nil && c.now().Sub(c.momo.fetched) < c.ttl {
+		return c.momo.codes, nil
+	}
+
+	resp, err := c.info.GetMobileMoneyCodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	codes, err := resp.MobileMoneyCodes()
+	if err != nil {
+		return nil, err
+	}
+	c.momo = &cachedCodes{codes: codes, fetched: c.now()}
+	return codes, nil
+}
+
+/**
+ * This function gets the airtime countries, from the cache while they are fresh
+ * @returns The country codes airtime can be sent to
+ */
+func (c *Cache) AirtimeCountries(ctx context.Context) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.airtime != nil && c.now().Sub(c.airtime.fetched) < c.ttl {
+		return c.airtime.countries, nil
+	}
+
+	resp, err := c.info.GetAirtimeCountries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	countries, err := resp.Countries()
+	if err != nil {
+		return nil, err
+	}
+	c.airtime = &cachedCountries{countries: countries, fetched: c.now()}
+	return countries, nil
+}
+
+// Invalidate drops every cached list, so the next lookup asks the API.
+func (c *Cache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.banks = make(map[string]cachedBanks)
+	c.momo = nil
+	c.airtime = nil
+}
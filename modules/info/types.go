@@ -0,0 +1,179 @@
+package info
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type Bank struct {
+	Code    string `json:"code"`
+	Name    string `json:"name"`
+	Country string `json:"country,omitempty"`
+}
+
+type MobileMoneyCode struct {
+	Code    string `json:"code"`
+	Name    string `json:"name"`
+	Country string `json:"country,omitempty"`
+}
+
+/**
+ * This function decodes the data of a GetBanks response
+ * @returns The list of banks, whether the data is a bare array or wrapped in a "banks" field
+ */
+func (r *InfoResponse) Banks() ([]Bank, error) {
+	var banks []Bank
+	if err := decodeList(r.Data, "banks", &banks); err != nil {
+		return nil, err
+	}
+	return banks, nil
+}
+
+/**
+ * This function decodes the data of a GetMobileMoneyCodes response
+ * @returns The list of mobile money codes, whether the data is a bare array or wrapped in a "codes" field
+ */
+func (r *InfoResponse) MobileMoneyCodes() ([]MobileMoneyCode, error) {
+	var codes []MobileMoneyCode
+	if err := decodeList(r.Data, "codes", &codes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+/**
+ * This function decodes the data of a GetAirtimeCountries response
+ * @returns The list of countries, whether the data is a bare array or wrapped in a "countries" field
+ */
+func (r *InfoResponse) Countries() ([]string, error) {
+	var countries []string
+	if err := decodeList(r.Data, "countries", &countries); err != nil {
+		return nil, err
+	}
+	return countries, nil
+}
+
+/**
+ * This function decodes the data of a GetUSDInLocalAmount response
+ * @returns The amount in local currency, from "amountInDestinationCurrency" or else "localAmount"
+ */
+func (r *InfoResponse) LocalAmount() (float64, error) {
+	var data struct {
+		AmountInDestinationCurrency *float64 `json:"amountInDestinationCurrency"`
+		LocalAmount                 *float64 `json:"localAmount"`
+	}
+	if err := json.Unmarshal(r.Data, &data); err != nil {
+		return 0, fmt.Errorf("info: unexpected conversion response: %v", err)
+	}
+
+	var amount float64
+	switch {
+	case data.AmountInDestinationCurrency != nil:
+		amount = *data.AmountInDestinationCurrency
+	case data.LocalAmount != nil:
+		amount = *data.LocalAmount
+	}
+	if amount <= 0 {
+		return 0, fmt.Errorf("info: conversion response has no amount")
+	}
+	return amount, nil
+}
+
+func decodeList(data json.RawMessage, field string, v interface{}) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, v); err == nil {
+		return nil
+	}
+
+	var wrapped map[string]json.RawMessage
+	if err := json.Unmarshal(data, &wrapped); err != nil {
+		return fmt.Errorf("info: unexpected response data: %v", err)
+	}
+	raw, ok := wrapped[field]
+	if !ok {
+		return fmt.Errorf("info: response data missing %q", field)
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("info: unexpected %q data: %v", field, err)
+	}
+	return nil
+}
+
+// Asset is an entry of the benefits catalog, such as a gift card product.
+// Denominations are in the recipient currency.
+type Asset struct {
+	ProductID                string    `json:"productId"`
+	Name                     string    `json:"productName"`
+	Brand                    string    `json:"brand,omitempty"`
+	Type                     string    `json:"type,omitempty"`
+	CountryCode              string    `json:"countryCode"`
+	Currency                 string    `json:"recipientCurrencyCode"`
+	SenderCurrency           string    `json:"senderCurrencyCode,omitempty"`
+	FixedDenominations       []float64 `json:"fixedRecipientDenominations,omitempty"`
+	FixedSenderDenominations []float64 `json:"fixedSenderDenominations,omitempty"`
+	MinDenomination          float64   `json:"minRecipientDenomination,omitempty"`
+	MaxDenomination          float64   `json:"maxRecipientDenomination,omitempty"`
+	ExchangeRate             float64   `json:"recipientCurrencyToSenderCurrencyExchangeRate,omitempty"`
+}
+
+// UnmarshalJSON accepts numeric product IDs, a name under "name", a brand
+// object with a "brandName" and a country object with an "isoName".
+func (a *Asset) UnmarshalJSON(data []byte) error {
+	type asset Asset
+	var raw struct {
+		asset
+		ProductID json.RawMessage `json:"productId"`
+		AltName   string          `json:"name"`
+		Brand     json.RawMessage `json:"brand"`
+		Country   struct {
+			ISOName string `json:"isoName"`
+		} `json:"country"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*a = Asset(raw.asset)
+	a.ProductID = flexibleString(raw.ProductID)
+	if a.Name == "" {
+		a.Name = raw.AltName
+	}
+	if a.Brand = flexibleString(raw.Brand); a.Brand == "" {
+		var brand struct {
+			BrandName string `json:"brandName"`
+		}
+		json.Unmarshal(raw.Brand, &brand)
+		a.Brand = brand.BrandName
+	}
+	if a.CountryCode == "" {
+		a.CountryCode = raw.Country.ISOName
+	}
+	return nil
+}
+
+/**
+ * This function decodes the data of a GetSupportedAssets response
+ * @returns The benefits catalog, whether the data is a bare array or wrapped in a "benefitsList" field
+ */
+func (r *InfoResponse) Assets() ([]Asset, error) {
+	var assets []Asset
+	if err := decodeList(r.Data, "benefitsList", &assets); err != nil {
+		return nil, err
+	}
+	return assets, nil
+}
+
+// flexibleString reads a JSON string or number as a string.
+func flexibleString(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err == nil {
+		return n.String()
+	}
+	return ""
+}
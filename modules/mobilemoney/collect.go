@@ -0,0 +1,253 @@
+package mobilemoney
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrCollectionTimeout = errors.New("mobile money collection did not complete in time")
+
+// minPollInterval keeps Wait from polling the API in a tight loop.
+const minPollInterval = 10 * time.Millisecond
+
+type CollectionStatus string
+
+const (
+	CollectionPending    CollectionStatus = "pending"
+	CollectionSuccessful CollectionStatus = "successful"
+	CollectionFailed     CollectionStatus = "failed"
+	CollectionExpired    CollectionStatus = "expired"
+)
+
+// Final reports whether the status can no longer change.
+func (s CollectionStatus) Final() bool {
+	return s == CollectionSuccessful || s == CollectionFailed || s == CollectionExpired
+}
+
+// Transition is passed to the status callback every time the status of a
+// collection changes. Data is the payment as last returned by the API.
+type Transition struct {
+	From CollectionStatus
+	To   CollectionStatus
+	At   time.Time
+	Data json.RawMessage
+}
+
+type collectConfig struct {
+	timeout     time.Duration
+	interval    time.Duration
+	maxInterval time.Duration
+	onStatus    func(Transition)
+}
+
+type CollectOption func(*collectConfig)
+
+// WithTimeout bounds how long Wait polls, on top of the context deadline.
+func WithTimeout(timeout time.Duration) CollectOption {
+	return func(c *collectConfig) {
+		c.timeout = timeout
+	}
+}
+
+// WithBackoff sets the first polling interval, doubled after every poll up to
+// max. Intervals shorter than 10ms are raised to it.
+func WithBackoff(initial, max time.Duration) CollectOption {
+	return func(c *collectConfig) {
+		if initial < minPollInterval {
+			initial = minPollInterval
+		}
+		if max < initial {
+			max = initial
+		}
+		c.interval = initial
+		c.maxInterval = max
+	}
+}
+
+func WithStatusCallback(fn func(Transition)) CollectOption {
+	return func(c *collectConfig) {
+		c.onStatus = fn
+	}
+}
+
+// Collection is a mobile money payment awaiting the customer's approval.
+type Collection struct {
+	ID         string
+	TxRef      string
+	SubAccount string
+	Response   *PaymentResponse
+
+	m      *MobileMoney
+	config collectConfig
+
+	mu      sync.Mutex
+	status  CollectionStatus
+	lastErr error
+}
+
+/**
+ * This function starts a mobile money collection
+ * @param {PaymentRequest} req The payment request details
+ * @returns A handle to wait for the customer to approve or decline the payment; if the
+ * payment response cannot be read, the handle tracks the tx_ref and Err reports why
+ */
+func (m *MobileMoney) Collect(ctx context.Context, req *PaymentRequest, options ...CollectOption) (*Collection, error) {
+	config := collectConfig{
+		timeout:     5 * time.Minute,
+		interval:    2 * time.Second,
+		maxInterval: 30 * time.Second,
+	}
+	for _, opt := range options {
+		opt(&config)
+	}
+
+	resp, err := m.MakePayment(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var data struct {
+		ID     string `json:"id"`
+		TxRef  string `json:"tx_ref"`
+		Status string `json:"status"`
+	}
+	var decodeErr error
+	if len(resp.Data) > 0 {
+		if err := json.Unmarshal(resp.Data, &data); err != nil {
+			// The customer has been prompted already, so the collection is
+			// still tracked, by the tx_ref that was sent.
+			data.ID, data.TxRef, data.Status = "", "", ""
+			decodeErr = fmt.Errorf("mobilemoney: unexpected payment response: %v", err)
+		}
+	}
+
+	c := &Collection{
+		ID:         data.ID,
+		TxRef:      resp.TxRef,
+		SubAccount: req.SubAccount,
+		Response:   resp,
+		m:          m,
+		config:     config,
+		status:     parseCollectionStatus(data.Status),
+		lastErr:    decodeErr,
+	}
+	if data.TxRef != "" {
+		c.TxRef = data.TxRef
+	}
+	if c.ID == "" {
+		c.ID = c.TxRef
+	}
+	if c.ID == "" {
+		return nil, errors.New("mobilemoney: payment response has no ID to verify")
+	}
+	return c, nil
+}
+
+// Err returns the problem with the last response about the payment, such as
+// a payment response that could not be read, or nil.
+func (c *Collection) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastErr
+}
+
+func (c *Collection) Status() CollectionStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.status
+}
+
+/**
+ * This function polls the payment until it is successful, failed or expired
+ * @returns The final status; ErrCollectionTimeout, wrapping the last verification error if any,
+ * when the timeout passes first
+ */
+func (c *Collection) Wait(ctx context.Context) (CollectionStatus, error) {
+	if status := c.Status(); status.Final() {
+		return status, nil
+	}
+
+	deadline := time.NewTimer(c.config.timeout)
+	defer deadline.Stop()
+
+	interval := c.config.interval
+	for {
+		wait := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			wait.Stop()
+			return c.Status(), ctx.Err()
+		case <-deadline.C:
+			wait.Stop()
+			return c.Status(), c.timeoutErr()
+		case <-wait.C:
+		}
+
+		if status := c.poll(ctx); status.Final() {
+			return status, nil
+		}
+
+		if interval *= 2; interval > c.config.maxInterval {
+			interval = c.config.maxInterval
+		}
+	}
+}
+
+func (c *Collection) poll(ctx context.Context) CollectionStatus {
+	resp, err := c.m.VerifyPayment(ctx, c.ID, c.SubAccount)
+	if err != nil {
+		// Verification errors are usually transient; keep polling until the timeout.
+		c.mu.Lock()
+		c.lastErr = err
+		c.mu.Unlock()
+		return c.Status()
+	}
+
+	var data struct {
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		c.mu.Lock()
+		c.lastErr = fmt.Errorf("mobilemoney: unexpected verification response: %v", err)
+		c.mu.Unlock()
+		return c.Status()
+	}
+	next := parseCollectionStatus(data.Status)
+
+	c.mu.Lock()
+	prev := c.status
+	c.status = next
+	c.lastErr = nil
+	c.mu.Unlock()
+
+	if next != prev && c.config.onStatus != nil {
+		c.config.onStatus(Transition{From: prev, To: next, At: time.Now(), Data: resp.Data})
+	}
+	return next
+}
+
+func (c *Collection) timeoutErr() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lastErr != nil {
+		return fmt.Errorf("%w: %v", ErrCollectionTimeout, c.lastErr)
+	}
+	return ErrCollectionTimeout
+}
+
+func parseCollectionStatus(status string) CollectionStatus {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "success", "successful", "completed", "paid":
+		return CollectionSuccessful
+	case "failed", "declined", "cancelled", "canceled", "error", "rejected":
+		return CollectionFailed
+	case "expired", "timeout", "timed_out":
+		return CollectionExpired
+	}
+	return CollectionPending
+}
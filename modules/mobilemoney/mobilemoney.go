@@ -3,6 +3,10 @@ package mobilemoney
 import (
 	"context"
 	"encoding/json"
+	"sync"
+
+	"github.com/chimoney/chimoney-go/internal/apierr"
+	"github.com/chimoney/chimoney-go/modules/info"
 )
 
 type Client interface {
@@ -11,6 +15,11 @@ type Client interface {
 
 type MobileMoney struct {
 	client Client
+
+	mu       sync.Mutex
+	txRefs   TxRefGenerator
+	registry TxRefRegistry
+	cache    *info.Cache
 }
 
 func New(client Client) *MobileMoney {
@@ -32,16 +41,34 @@ type PaymentResponse struct {
 	Status  string          `json:"status"`
 	Data    json.RawMessage `json:"data"`
 	Message string          `json:"message"`
+	TxRef   string          `json:"-"`
 }
 
 /**
  * This function initiates a mobile money payment
- * @param {PaymentRequest} req The payment request details
- * @returns The response from the Chimoney API
+ * @param {PaymentRequest} req The payment request details; a tx_ref is generated if TxRef is empty
+ * @returns The response from the Chimoney API, with the tx_ref used
  */
 func (m *MobileMoney) MakePayment(ctx context.Context, req *PaymentRequest) (*PaymentResponse, error) {
-	resp := new(PaymentResponse)
-	err := m.client.Do(ctx, "POST", "/collections/mobile-money/pay", req, resp, nil)
+	if req == nil {
+		return nil, ErrNilRequest
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	ref, release, err := m.txRef(req)
+	if err != nil {
+		return nil, err
+	}
+	body := *req
+	body.TxRef = ref
+
+	resp := &PaymentResponse{TxRef: ref}
+	err = m.client.Do(ctx, "POST", "/collections/mobile-money/pay", body, resp, nil)
+	if err != nil && apierr.NotSent(err) {
+		// The payment was never made, so a retry may use the reference.
+		release()
+	}
 	return resp, err
 }
 
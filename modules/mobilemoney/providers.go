@@ -0,0 +1,273 @@
+package mobilemoney
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/chimoney/chimoney-go/modules/info"
+)
+
+var (
+	ErrInvalidPhoneNumber = errors.New("invalid phone number")
+	ErrUnknownCountry     = errors.New("no mobile money prefix data for country")
+	ErrUnknownProvider    = errors.New("no mobile money provider found for phone number")
+)
+
+//go:embed data/providers.json
+var embeddedProviders []byte
+
+// ProviderPrefixes describes one provider of a country. Aliases are the other
+// names or codes the API may list it under in Info.GetMobileMoneyCodes.
+type ProviderPrefixes struct {
+	Name     string   `json:"name"`
+	Code     string   `json:"code"`
+	Aliases  []string `json:"aliases,omitempty"`
+	Prefixes []string `json:"prefixes"`
+}
+
+// CountryPrefixes holds the numbering plan of a country. Prefixes are matched
+// against the national number, without the calling code or trunk prefix.
+type CountryPrefixes struct {
+	CallingCode    string             `json:"callingCode"`
+	NationalLength int                `json:"nationalLength"`
+	Providers      []ProviderPrefixes `json:"providers"`
+}
+
+type Provider struct {
+	Name string `json:"name"`
+	Code string `json:"code"`
+	// Verified is set when the code was confirmed against Info.GetMobileMoneyCodes.
+	Verified bool `json:"verified"`
+
+	aliases []string
+}
+
+// Detection lists every provider whose prefix matches the number best. More
+// than one candidate means the prefix alone cannot tell them apart.
+type Detection struct {
+	E164       string     `json:"e164"`
+	Country    string     `json:"country"`
+	Candidates []Provider `json:"candidates"`
+}
+
+var (
+	prefixMu sync.RWMutex
+	prefixes map[string]CountryPrefixes
+)
+
+func init() {
+	if err := LoadProviderData(bytes.NewReader(embeddedProviders)); err != nil {
+		panic(err)
+	}
+}
+
+/**
+ * This function replaces the prefix data used to detect providers
+ * @param {io.Reader} r JSON keyed by ISO country code, in the format of data/providers.json
+ */
+func LoadProviderData(r io.Reader) error {
+	var data map[string]CountryPrefixes
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return fmt.Errorf("mobilemoney: invalid provider data: %v", err)
+	}
+
+	normalized := make(map[string]CountryPrefixes, len(data))
+	for country, c := range data {
+		if c.CallingCode == "" || c.NationalLength <= 0 {
+			return fmt.Errorf("mobilemoney: provider data for %s needs a calling code and national length", country)
+		}
+		normalized[strings.ToUpper(country)] = c
+	}
+
+	prefixMu.Lock()
+	prefixes = normalized
+	prefixMu.Unlock()
+	return nil
+}
+
+/**
+ * This function detects the mobile money provider of a phone number from its prefix
+ * @param {string} phone The phone number, in national or international format
+ * @param {string?} country The ISO country code; inferred from the calling code if empty
+ * @returns The number in E.164 format and the matching providers
+ */
+func DetectProvider(phone, country string) (*Detection, error) {
+	prefixMu.RLock()
+	defer prefixMu.RUnlock()
+
+	country = strings.ToUpper(strings.TrimSpace(country))
+	national, country, err := normalizePhone(phone, country)
+	if err != nil {
+		return nil, err
+	}
+	plan := prefixes[country]
+
+	d := &Detection{E164: "+" + plan.CallingCode + national, Country: country}
+	best := 0
+	for _, p := range plan.Providers {
+		n := 0
+		for _, prefix := range p.Prefixes {
+			if strings.HasPrefix(national, prefix) && len(prefix) > n {
+				n = len(prefix)
+			}
+		}
+		switch {
+		case n == 0 || n < best:
+			continue
+		case n > best:
+			best = n
+			d.Candidates = nil
+		}
+		d.Candidates = append(d.Candidates, Provider{Name: p.Name, Code: p.Code, aliases: p.Aliases})
+	}
+
+	if len(d.Candidates) == 0 {
+		return d, fmt.Errorf("%w: %s", ErrUnknownProvider, d.E164)
+	}
+	return d, nil
+}
+
+/**
+ * This function detects the provider of a phone number and maps it to the codes the API accepts
+ * @param {string} phone The phone number, in national or international format
+ * @param {string?} country The ISO country code; inferred from the calling code if empty
+ * @returns The detection, keeping only candidates supported by the API, with the API's codes
+ */
+func (m *MobileMoney) DetectProvider(ctx context.Context, phone, country string) (*Detection, error) {
+	d, err := DetectProvider(phone, country)
+	if err != nil {
+		return d, err
+	}
+
+	codes, err := m.InfoCache().MobileMoneyCodes(ctx)
+	if err != nil {
+		return d, err
+	}
+
+	var verified []Provider
+	for _, p := range d.Candidates {
+		for _, c := range codes {
+			if c.Country != "" && !strings.EqualFold(c.Country, d.Country) {
+				continue
+			}
+			if strings.EqualFold(c.Code, p.Code) || p.knownAs(c.Name) || p.knownAs(c.Code) {
+				verified = append(verified, Provider{Name: p.Name, Code: c.Code, Verified: true})
+				break
+			}
+		}
+	}
+	d.Candidates = verified
+
+	if len(verified) == 0 {
+		return d, fmt.Errorf("%w: %s has no provider supported by the API", ErrUnknownProvider, d.E164)
+	}
+	return d, nil
+}
+
+/**
+ * This function shares a cache of the Info lookups, such as mobile money codes, with other modules
+ * @param {info.Cache} cache The cache, one of its own by default
+ */
+func (m *MobileMoney) SetInfoCache(cache *info.Cache) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cache = cache
+}
+
+/**
+ * This function returns the cache of Info lookups providers are verified against
+ * @returns The cache
+ */
+func (m *MobileMoney) InfoCache() *info.Cache {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.cache == nil {
+		m.cache = info.NewCache(info.New(m.client))
+	}
+	return m.cache
+}
+
+// normalizePhone returns the national number of a phone number and its
+// country. Callers must hold prefixMu.
+func normalizePhone(phone, country string) (string, string, error) {
+	trimmed := strings.TrimSpace(phone)
+	international := strings.HasPrefix(trimmed, "+")
+	digits := strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsDigit(r):
+			return r
+		case unicode.IsSpace(r) || r == '-' || r == '(' || r == ')' || r == '.' || r == '+':
+			return -1
+		}
+		return 'x'
+	}, trimmed)
+	if digits == "" || strings.ContainsRune(digits, 'x') {
+		return "", "", fmt.Errorf("%w: %q", ErrInvalidPhoneNumber, phone)
+	}
+	if strings.HasPrefix(digits, "00") {
+		international, digits = true, digits[2:]
+	}
+
+	if country == "" {
+		if !international {
+			return "", "", fmt.Errorf("%w: %q needs a country or an international prefix", ErrInvalidPhoneNumber, phone)
+		}
+		for code, plan := range prefixes {
+			if strings.HasPrefix(digits, plan.CallingCode) && len(digits)-len(plan.CallingCode) == plan.NationalLength {
+				country = code
+				break
+			}
+		}
+	}
+	plan, ok := prefixes[country]
+	if !ok {
+		return "", "", fmt.Errorf("%w: %q", ErrUnknownCountry, country)
+	}
+
+	cc, n := plan.CallingCode, plan.NationalLength
+	switch {
+	case international && strings.HasPrefix(digits, cc) && len(digits)-len(cc) == n:
+		return digits[len(cc):], country, nil
+	case international:
+	case len(digits) == n:
+		return digits, country, nil
+	case strings.HasPrefix(digits, "0") && len(digits)-1 == n:
+		return digits[1:], country, nil
+	case strings.HasPrefix(digits, cc) && len(digits)-len(cc) == n:
+		return digits[len(cc):], country, nil
+	}
+	return "", "", fmt.Errorf("%w: %q is not a %s number", ErrInvalidPhoneNumber, phone, country)
+}
+
+// knownAs reports whether the API name or code is the provider's name or one
+// of its aliases, ignoring case, spaces and punctuation. Names are compared
+// whole, so "Airtel" does not match "AirtelTigo".
+func (p Provider) knownAs(name string) bool {
+	n := normalizeName(name)
+	if n == "" {
+		return false
+	}
+	for _, known := range append([]string{p.Name}, p.aliases...) {
+		if normalizeName(known) == n {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, s)
+}
@@ -0,0 +1,407 @@
+package mobilemoney
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/chimoney/chimoney-go/internal/stream"
+)
+
+type MobileMoneyTransaction struct {
+	ID          string          `json:"id"`
+	TxRef       string          `json:"tx_ref"`
+	Status      string          `json:"status"`
+	Amount      float64         `json:"amount"`
+	Currency    string          `json:"currency"`
+	Country     string          `json:"country"`
+	PhoneNumber string          `json:"phone_number"`
+	FullName    string          `json:"fullname"`
+	Email       string          `json:"email"`
+	SubAccount  string          `json:"subAccount,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	Raw         json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON accepts amounts sent as strings and creation times sent as
+// RFC 3339 strings, Unix milliseconds or {"_seconds": ...} timestamps.
+func (t *MobileMoneyTransaction) UnmarshalJSON(data []byte) error {
+	type transaction MobileMoneyTransaction
+	var raw struct {
+		transaction
+		Amount    json.RawMessage `json:"amount"`
+		CreatedAt json.RawMessage `json:"createdAt"`
+		Created   json.RawMessage `json:"created_at"`
+		Date      json.RawMessage `json:"date"`
+		Phone     string          `json:"phoneNumber"`
+		TxRefAlt  string          `json:"txRef"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*t = MobileMoneyTransaction(raw.transaction)
+	t.Raw = append(json.RawMessage(nil), data...)
+	if t.PhoneNumber == "" {
+		t.PhoneNumber = raw.Phone
+	}
+	if t.TxRef == "" {
+		t.TxRef = raw.TxRefAlt
+	}
+
+	if len(raw.Amount) > 0 {
+		s := strings.Trim(string(raw.Amount), `"`)
+		if s != "" && s != "null" {
+			amount, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return fmt.Errorf("mobilemoney: invalid amount %s", raw.Amount)
+			}
+			t.Amount = amount
+		}
+	}
+
+	for _, ts := range []json.RawMessage{raw.CreatedAt, raw.Created, raw.Date} {
+		if at, ok := parseTimestamp(ts); ok {
+			t.CreatedAt = at
+			break
+		}
+	}
+	return nil
+}
+
+type SortField string
+
+const (
+	SortByDate   SortField = "date"
+	SortByAmount SortField = "amount"
+)
+
+// TransactionQuery filters mobile money transactions. Empty fields match
+// everything; From is inclusive and To exclusive. SubAccount is sent in the
+// request body and the other filters as query parameters. The API may not
+// apply them all, so they are also checked on the client as the transactions
+// are read.
+type TransactionQuery struct {
+	SubAccount  string
+	Status      string
+	Currency    string
+	Country     string
+	PhoneNumber string
+	TxRef       string
+	From        time.Time
+	To          time.Time
+	SortBy      SortField
+	Descending  bool
+}
+
+// TransactionIterator reads transactions one at a time. With a client that
+// can stream responses, unsorted queries decode each transaction from the
+// response body only when Next is called; sorted queries, and clients without
+// streaming, hold the response in memory.
+type TransactionIterator struct {
+	query  TransactionQuery
+	body   io.Closer
+	dec    *json.Decoder
+	sorted []MobileMoneyTransaction
+	cur    MobileMoneyTransaction
+	err    error
+}
+
+/**
+ * This function lists mobile money transactions matching a query
+ * @param {TransactionQuery} q The filters and sort order
+ * @returns An iterator over the matching transactions, to be closed if not read to the end
+ */
+func (m *MobileMoney) Transactions(ctx context.Context, q TransactionQuery) (*TransactionIterator, error) {
+	it := &TransactionIterator{query: q}
+	if err := it.open(ctx, m.client, q); err != nil {
+		it.Close()
+		return nil, err
+	}
+
+	if q.SortBy != "" {
+		var matches []MobileMoneyTransaction
+		for it.next() {
+			matches = append(matches, it.cur)
+		}
+		if it.err != nil {
+			return nil, it.err
+		}
+		sortTransactions(matches, q.SortBy, q.Descending)
+		it.sorted = matches
+	}
+	return it, nil
+}
+
+// open positions the iterator at the start of the transaction list, streaming
+// it from the response body when the client allows.
+func (it *TransactionIterator) open(ctx context.Context, client Client, q TransactionQuery) error {
+	req := make(map[string]string)
+	if q.SubAccount != "" {
+		req["subAccount"] = q.SubAccount
+	}
+	params := q.params()
+
+	var (
+		dec *json.Decoder
+		ok  bool
+		err error
+	)
+	if s, streams := client.(stream.Streamer); streams {
+		body, err := s.Stream(ctx, "POST", "/collections/mobile-money/all", req, params)
+		if err != nil {
+			return err
+		}
+		it.body = body
+		dec = json.NewDecoder(body)
+		ok, err = seekData(dec)
+		if err != nil {
+			return err
+		}
+	} else {
+		resp := new(PaymentResponse)
+		if err := client.Do(ctx, "POST", "/collections/mobile-money/all", req, resp, params); err != nil {
+			return err
+		}
+		data := bytes.TrimSpace(resp.Data)
+		dec, ok = json.NewDecoder(bytes.NewReader(data)), len(data) > 0
+	}
+	if !ok {
+		return nil
+	}
+
+	if ok, err = openList(dec); err != nil || !ok {
+		return err
+	}
+	it.dec = dec
+	return nil
+}
+
+/**
+ * This function releases the response behind the iterator
+ * @returns An error if the response could not be closed
+ */
+func (it *TransactionIterator) Close() error {
+	it.dec = nil
+	if it.body == nil {
+		return nil
+	}
+	err := it.body.Close()
+	it.body = nil
+	return err
+}
+
+// Next advances to the next matching transaction. It returns false when
+// there are none left or an error occurred.
+func (it *TransactionIterator) Next() bool {
+	if it.dec == nil {
+		if len(it.sorted) == 0 {
+			return false
+		}
+		it.cur, it.sorted = it.sorted[0], it.sorted[1:]
+		return true
+	}
+	return it.next()
+}
+
+func (it *TransactionIterator) Transaction() MobileMoneyTransaction {
+	return it.cur
+}
+
+func (it *TransactionIterator) Err() error {
+	return it.err
+}
+
+func (it *TransactionIterator) next() bool {
+	if it.dec == nil || it.err != nil {
+		return false
+	}
+	for it.dec.More() {
+		var t MobileMoneyTransaction
+		if err := it.dec.Decode(&t); err != nil {
+			it.err = fmt.Errorf("mobilemoney: unexpected transaction: %v", err)
+			it.Close()
+			return false
+		}
+		if it.query.matches(t) {
+			it.cur = t
+			return true
+		}
+	}
+	it.Close()
+	return false
+}
+
+// params returns the filters of the query as query parameters.
+func (q TransactionQuery) params() map[string]string {
+	params := make(map[string]string)
+	for k, v := range map[string]string{
+		"status":      q.Status,
+		"currency":    q.Currency,
+		"country":     q.Country,
+		"phoneNumber": q.PhoneNumber,
+		"txRef":       q.TxRef,
+	} {
+		if v != "" {
+			params[k] = v
+		}
+	}
+	if !q.From.IsZero() {
+		params["startDate"] = q.From.UTC().Format(time.RFC3339)
+	}
+	if !q.To.IsZero() {
+		params["endDate"] = q.To.UTC().Format(time.RFC3339)
+	}
+	if len(params) == 0 {
+		return nil
+	}
+	return params
+}
+
+func (q TransactionQuery) matches(t MobileMoneyTransaction) bool {
+	switch {
+	case q.Status != "" && !strings.EqualFold(q.Status, t.Status):
+		return false
+	case q.Currency != "" && !strings.EqualFold(q.Currency, t.Currency):
+		return false
+	case q.Country != "" && !strings.EqualFold(q.Country, t.Country):
+		return false
+	case q.TxRef != "" && q.TxRef != t.TxRef:
+		return false
+	case q.PhoneNumber != "" && !samePhone(q.PhoneNumber, t.PhoneNumber):
+		return false
+	case !q.From.IsZero() && t.CreatedAt.Before(q.From):
+		return false
+	case !q.To.IsZero() && !t.CreatedAt.Before(q.To):
+		return false
+	}
+	return true
+}
+
+func sortTransactions(ts []MobileMoneyTransaction, by SortField, desc bool) {
+	sort.SliceStable(ts, func(i, j int) bool {
+		a, b := ts[i], ts[j]
+		if desc {
+			a, b = b, a
+		}
+		if by == SortByAmount {
+			return a.Amount < b.Amount
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+}
+
+// seekData advances a decoder at the start of a response to its data. It
+// reports false when the response has none.
+func seekData(dec *json.Decoder) (bool, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return false, fmt.Errorf("mobilemoney: unexpected response: %v", err)
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return false, fmt.Errorf("mobilemoney: unexpected response")
+	}
+	return seekField(dec, "data")
+}
+
+// openList reads the opening bracket of the list of transactions, which is
+// either the data itself or one of its fields. It reports false when the
+// data is null or holds no list.
+func openList(dec *json.Decoder) (bool, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return false, fmt.Errorf("mobilemoney: unexpected response data: %v", err)
+	}
+	if delim, ok := tok.(json.Delim); ok && delim == '{' {
+		found, err := seekField(dec, "transactions", "data", "items")
+		if err != nil || !found {
+			return false, err
+		}
+		if tok, err = dec.Token(); err != nil {
+			return false, fmt.Errorf("mobilemoney: unexpected response data: %v", err)
+		}
+	}
+
+	switch tok {
+	case nil:
+		return false, nil
+	case json.Delim('['):
+		return true, nil
+	}
+	return false, fmt.Errorf("mobilemoney: response data is not a list of transactions")
+}
+
+// seekField advances a decoder inside an object, past its opening brace, to
+// the value of the first of keys it holds. It reports false when it holds none.
+func seekField(dec *json.Decoder, keys ...string) (bool, error) {
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return false, fmt.Errorf("mobilemoney: unexpected response data: %v", err)
+		}
+		for _, k := range keys {
+			if tok == k {
+				return true, nil
+			}
+		}
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return false, fmt.Errorf("mobilemoney: unexpected response data: %v", err)
+		}
+	}
+	return false, nil
+}
+
+func parseTimestamp(raw json.RawMessage) (time.Time, bool) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return time.Time{}, false
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02"} {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t, true
+			}
+		}
+		return time.Time{}, false
+	}
+
+	var ms int64
+	if err := json.Unmarshal(raw, &ms); err == nil {
+		return time.UnixMilli(ms).UTC(), true
+	}
+
+	var fs struct {
+		Seconds     *int64 `json:"_seconds"`
+		Nanoseconds int64  `json:"_nanoseconds"`
+	}
+	if err := json.Unmarshal(raw, &fs); err == nil && fs.Seconds != nil {
+		return time.Unix(*fs.Seconds, fs.Nanoseconds).UTC(), true
+	}
+	return time.Time{}, false
+}
+
+func samePhone(a, b string) bool {
+	digits := func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if unicode.IsDigit(r) {
+				return r
+			}
+			return -1
+		}, s)
+	}
+	// Match numbers written with and without the country code or trunk prefix.
+	da, db := strings.TrimLeft(digits(a), "0"), strings.TrimLeft(digits(b), "0")
+	if len(da) < 7 || len(db) < 7 {
+		return da != "" && da == db
+	}
+	return strings.HasSuffix(da, db) || strings.HasSuffix(db, da)
+}
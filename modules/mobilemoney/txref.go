@@ -0,0 +1,197 @@
+package mobilemoney
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrDuplicateTxRef = errors.New("tx_ref has already been used")
+	ErrNilRequest     = errors.New("payment request is required")
+)
+
+// TxRefGenerator invents a tx_ref for payment requests that have none.
+type TxRefGenerator interface {
+	NewTxRef() (string, error)
+}
+
+// TxRefRegistry remembers the references used so far. Reserve fails with
+// ErrDuplicateTxRef for a reference that was reserved before; Release forgets
+// a reference whose payment never reached the API.
+type TxRefRegistry interface {
+	Reserve(txRef string) error
+	Release(txRef string)
+}
+
+type TxRefFunc func() (string, error)
+
+func (f TxRefFunc) NewTxRef() (string, error) {
+	return f()
+}
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULIDGenerator produces 26 character ULIDs, which sort by creation time.
+// IDs made in the same millisecond by one generator are still increasing.
+type ULIDGenerator struct {
+	now func() time.Time
+
+	mu      sync.Mutex
+	lastMS  uint64
+	lastRnd [10]byte
+}
+
+func NewULIDGenerator() *ULIDGenerator {
+	return &ULIDGenerator{now: time.Now}
+}
+
+func (g *ULIDGenerator) NewTxRef() (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := uint64(g.now().UnixMilli())
+	if ms <= g.lastMS {
+		// Same millisecond, or the clock went back: increment the randomness.
+		ms = g.lastMS
+		i := len(g.lastRnd) - 1
+		for ; i >= 0; i-- {
+			g.lastRnd[i]++
+			if g.lastRnd[i] != 0 {
+				break
+			}
+		}
+		if i < 0 {
+			return "", errors.New("mobilemoney: ULID randomness overflow")
+		}
+	} else {
+		if _, err := rand.Read(g.lastRnd[:]); err != nil {
+			return "", err
+		}
+		g.lastMS = ms
+	}
+
+	var b [16]byte
+	for i := 0; i < 6; i++ {
+		b[i] = byte(ms >> (40 - 8*i))
+	}
+	copy(b[6:], g.lastRnd[:])
+	return encodeULID(b), nil
+}
+
+// encodeULID writes 128 bits as 26 Crockford base32 characters, most
+// significant first; the first character only carries 3 bits.
+func encodeULID(b [16]byte) string {
+	var out [26]byte
+	var acc uint32
+	bits := 2 // 26*5 = 130 bits, so pad two leading zero bits.
+	j := 0
+	for _, v := range b {
+		acc = acc<<8 | uint32(v)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			out[j] = crockford[(acc>>bits)&31]
+			j++
+		}
+	}
+	return string(out[:])
+}
+
+// PrefixedGenerator prefixes the references of another generator, e.g. with
+// a service name or a "namespace/service" path, joined by a dash.
+type PrefixedGenerator struct {
+	Prefix string
+	Next   TxRefGenerator
+}
+
+func (g PrefixedGenerator) NewTxRef() (string, error) {
+	next := g.Next
+	if next == nil {
+		next = defaultGenerator
+	}
+	ref, err := next.NewTxRef()
+	if err != nil {
+		return "", err
+	}
+	if g.Prefix == "" {
+		return ref, nil
+	}
+	return strings.TrimRight(g.Prefix, "-") + "-" + ref, nil
+}
+
+var defaultGenerator = NewULIDGenerator()
+
+type MemoryTxRefRegistry struct {
+	mu   sync.Mutex
+	used map[string]bool
+}
+
+func NewMemoryTxRefRegistry() *MemoryTxRefRegistry {
+	return &MemoryTxRefRegistry{used: make(map[string]bool)}
+}
+
+func (r *MemoryTxRefRegistry) Reserve(txRef string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.used[txRef] {
+		return fmt.Errorf("%w: %s", ErrDuplicateTxRef, txRef)
+	}
+	r.used[txRef] = true
+	return nil
+}
+
+func (r *MemoryTxRefRegistry) Release(txRef string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.used, txRef)
+}
+
+/**
+ * This function sets how tx_refs are generated for payment requests without one
+ * @param {TxRefGenerator} gen The generator, ULIDs by default
+ */
+func (m *MobileMoney) SetTxRefGenerator(gen TxRefGenerator) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.txRefs = gen
+}
+
+/**
+ * This function sets the registry that rejects reused tx_refs before a payment is made
+ * @param {TxRefRegistry} registry The registry, or nil to allow any reference
+ */
+func (m *MobileMoney) SetTxRefRegistry(registry TxRefRegistry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.registry = registry
+}
+
+// txRef picks the reference of a payment request and reserves it. The release
+// function gives the reference back to the registry.
+func (m *MobileMoney) txRef(req *PaymentRequest) (string, func(), error) {
+	m.mu.Lock()
+	gen, registry := m.txRefs, m.registry
+	m.mu.Unlock()
+
+	ref := req.TxRef
+	if ref == "" {
+		if gen == nil {
+			gen = defaultGenerator
+		}
+		var err error
+		if ref, err = gen.NewTxRef(); err != nil {
+			return "", nil, fmt.Errorf("mobilemoney: could not generate tx_ref: %v", err)
+		}
+	}
+	if registry == nil {
+		return ref, func() {}, nil
+	}
+	if err := registry.Reserve(ref); err != nil {
+		return "", nil, err
+	}
+	return ref, func() { registry.Release(ref) }, nil
+}
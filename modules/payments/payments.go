@@ -0,0 +1,205 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrInvalidAmount    = errors.New("invalid amount")
+	ErrInvalidCurrency  = errors.New("invalid currency")
+	ErrInvalidEmail     = errors.New("invalid payer email")
+	ErrInvalidPaymentID = errors.New("invalid payment ID")
+)
+
+type Client interface {
+	Do(ctx context.Context, method, path string, body interface{}, v interface{}, params map[string]string) error
+}
+
+type Payments struct {
+	client     Client
+	subAccount string
+}
+
+func New(client Client) *Payments {
+	return &Payments{client: client}
+}
+
+/**
+ * This function scopes payment requests to a sub-account
+ * @param {string} subAccount The ID of the sub-account
+ * @returns A Payments sending every request on behalf of the sub-account
+ */
+func (p *Payments) ForSubAccount(subAccount string) *Payments {
+	return &Payments{client: p.client, subAccount: subAccount}
+}
+
+// PaymentRequest asks a payer for money. Set Amount and Currency to request a
+// local amount, or ValueInUSD alone to request a USD amount.
+type PaymentRequest struct {
+	Amount      float64 `json:"amount,omitempty"`
+	Currency    string  `json:"currency,omitempty"`
+	ValueInUSD  float64 `json:"valueInUSD,omitempty"`
+	PayerEmail  string  `json:"payerEmail"`
+	RedirectURL string  `json:"redirect_url,omitempty"`
+	Narration   string  `json:"narration,omitempty"`
+	SubAccount  string  `json:"subAccount,omitempty"`
+}
+
+type PaymentStatus string
+
+const (
+	StatusPending PaymentStatus = "pending"
+	StatusPaid    PaymentStatus = "paid"
+	StatusFailed  PaymentStatus = "failed"
+	StatusExpired PaymentStatus = "expired"
+)
+
+type Payment struct {
+	ID          string        `json:"id"`
+	IssueID     string        `json:"issueID,omitempty"`
+	Status      PaymentStatus `json:"status"`
+	Amount      float64       `json:"amount,omitempty"`
+	Currency    string        `json:"currency,omitempty"`
+	ValueInUSD  float64       `json:"valueInUSD,omitempty"`
+	PayerEmail  string        `json:"payerEmail,omitempty"`
+	PaymentLink string        `json:"paymentLink,omitempty"`
+	PaidAt      string        `json:"paidAt,omitempty"`
+}
+
+type PaymentResponse struct {
+	Status  string          `json:"status"`
+	Data    json.RawMessage `json:"data"`
+	Message string          `json:"message"`
+}
+
+type CheckoutResponse struct {
+	PaymentResponse
+	ID           string `json:"id"`
+	IssueID      string `json:"issueID,omitempty"`
+	CheckoutLink string `json:"checkoutLink"`
+}
+
+type VerifyResponse struct {
+	PaymentResponse
+	Payment Payment `json:"payment"`
+}
+
+/**
+ * This function creates a payment request
+ * @param {PaymentRequest} req The amount, payer and redirect URL of the request
+ * @returns The response from the Chimoney API with the checkout link to send to the payer
+ */
+func (p *Payments) CreateRequest(ctx context.Context, req *PaymentRequest) (*CheckoutResponse, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+
+	body := *req
+	if body.SubAccount == "" {
+		body.SubAccount = p.subAccount
+	}
+
+	resp := new(CheckoutResponse)
+	if err := p.client.Do(ctx, "POST", "/payment/initiate", body, &resp.PaymentResponse, nil); err != nil {
+		return resp, err
+	}
+
+	payment, err := decodePayment(resp.Data)
+	if err != nil {
+		return resp, err
+	}
+	resp.ID = payment.ID
+	resp.IssueID = payment.IssueID
+	resp.CheckoutLink = payment.PaymentLink
+	return resp, nil
+}
+
+/**
+ * This function verifies a payment request
+ * @param {string} id The ID of the payment request
+ * @returns The response from the Chimoney API with the payment and its status
+ */
+func (p *Payments) Verify(ctx context.Context, id string) (*VerifyResponse, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, ErrInvalidPaymentID
+	}
+
+	req := map[string]string{
+		"id": id,
+	}
+	if p.subAccount != "" {
+		req["subAccount"] = p.subAccount
+	}
+
+	resp := new(VerifyResponse)
+	if err := p.client.Do(ctx, "POST", "/payment/verify", req, &resp.PaymentResponse, nil); err != nil {
+		return resp, err
+	}
+
+	payment, err := decodePayment(resp.Data)
+	if err != nil {
+		return resp, err
+	}
+	if payment.ID == "" {
+		payment.ID = id
+	}
+	resp.Payment = *payment
+	return resp, nil
+}
+
+func (r *PaymentRequest) validate() error {
+	if r == nil {
+		return ErrInvalidAmount
+	}
+	email := strings.TrimSpace(r.PayerEmail)
+	if at := strings.Index(email, "@"); at <= 0 || at == len(email)-1 {
+		return ErrInvalidEmail
+	}
+	switch {
+	case r.Amount > 0 && r.Currency == "":
+		return ErrInvalidCurrency
+	case r.Amount <= 0 && r.ValueInUSD <= 0:
+		return ErrInvalidAmount
+	case r.Amount < 0 || r.ValueInUSD < 0:
+		return ErrInvalidAmount
+	}
+	return nil
+}
+
+// decodePayment reads a payment from the response data, which may use chiRef
+// or issueID as the ID and "redirect"/"checkoutLink" for the link.
+func decodePayment(data json.RawMessage) (*Payment, error) {
+	payment := new(Payment)
+	if len(data) == 0 || string(data) == "null" {
+		return payment, nil
+	}
+
+	var raw struct {
+		Payment
+		ChiRef       string `json:"chiRef"`
+		CheckoutLink string `json:"checkoutLink"`
+		Redirect     string `json:"redirect"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("payments: unexpected response data: %v", err)
+	}
+
+	*payment = raw.Payment
+	if payment.ID == "" {
+		payment.ID = raw.ChiRef
+	}
+	if payment.ID == "" {
+		payment.ID = payment.IssueID
+	}
+	for _, link := range []string{raw.CheckoutLink, raw.Redirect} {
+		if payment.PaymentLink == "" {
+			payment.PaymentLink = link
+		}
+	}
+	payment.Status = PaymentStatus(strings.ToLower(string(payment.Status)))
+	return payment, nil
+}
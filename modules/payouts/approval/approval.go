@@ -0,0 +1,509 @@
+package approval
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chimoney/chimoney-go/internal/apierr"
+	"github.com/chimoney/chimoney-go/modules/payouts"
+	"github.com/chimoney/chimoney-go/modules/payouts/dedupe"
+	"github.com/chimoney/chimoney-go/modules/policy"
+)
+
+var (
+	ErrEmptyBatch            = errors.New("batch has no payouts")
+	ErrInvalidActor          = errors.New("invalid actor")
+	ErrNotFound              = errors.New("pending payout not found")
+	ErrSelfApproval          = errors.New("preparer cannot approve their own payout")
+	ErrDuplicateApproval     = errors.New("approver has already approved this payout")
+	ErrModified              = errors.New("payout was modified after it was prepared")
+	ErrInsufficientApprovals = errors.New("not enough valid approvals")
+	ErrAlreadySubmitted      = errors.New("payout has already been submitted")
+)
+
+type Batch struct {
+	Airtime    []payouts.AirtimePayload  `json:"airtime,omitempty"`
+	Bank       []payouts.BankPayload     `json:"bank,omitempty"`
+	Chimoney   []payouts.ChimoneyPayload `json:"chimoney,omitempty"`
+	GiftCard   []payouts.GiftCardPayload `json:"giftCard,omitempty"`
+	SubAccount string                    `json:"subAccount,omitempty"`
+}
+
+/**
+ * This function computes the content hash of a batch
+ * @returns The hex-encoded SHA-256 of the batch's JSON encoding
+ */
+func (b *Batch) Hash() (string, error) {
+	data, err := json.Marshal(b)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (b *Batch) empty() bool {
+	return len(b.Airtime) == 0 && len(b.Bank) == 0 && len(b.Chimoney) == 0 && len(b.GiftCard) == 0
+}
+
+type Approval struct {
+	Approver   string    `json:"approver"`
+	Hash       string    `json:"hash"`
+	ApprovedAt time.Time `json:"approvedAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+type PendingPayout struct {
+	ID          string     `json:"id"`
+	Batch       *Batch     `json:"batch"`
+	Hash        string     `json:"hash"`
+	PreparedBy  string     `json:"preparedBy"`
+	PreparedAt  time.Time  `json:"preparedAt"`
+	Approvals   []Approval `json:"approvals"`
+	SubmittedAt *time.Time `json:"submittedAt,omitempty"`
+}
+
+type EventType string
+
+const (
+	EventPrepared    EventType = "prepared"
+	EventApproved    EventType = "approved"
+	EventInvalidated EventType = "invalidated"
+	EventSubmitted   EventType = "submitted"
+	EventFailed      EventType = "submit_failed"
+)
+
+type Event struct {
+	PayoutID string    `json:"payoutId"`
+	Type     EventType `json:"type"`
+	Actor    string    `json:"actor"`
+	Hash     string    `json:"hash"`
+	At       time.Time `json:"at"`
+	Detail   string    `json:"detail,omitempty"`
+}
+
+type SubmitResult struct {
+	Airtime  *payouts.PayoutResponse `json:"airtime,omitempty"`
+	Bank     *payouts.PayoutResponse `json:"bank,omitempty"`
+	Chimoney *payouts.PayoutResponse `json:"chimoney,omitempty"`
+	GiftCard *payouts.PayoutResponse `json:"giftCard,omitempty"`
+}
+
+// Workflow holds prepared payouts until enough distinct approvers have signed
+// off on their exact content, and only then releases them through Payouts.
+type Workflow struct {
+	payouts  *payouts.Payouts
+	required int
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	pending map[string]*PendingPayout
+	history []Event
+}
+
+type Option func(*Workflow)
+
+func New(p *payouts.Payouts, required int, options ...Option) *Workflow {
+	if required < 1 {
+		required = 1
+	}
+
+	w := &Workflow{
+		payouts:  p,
+		required: required,
+		ttl:      24 * time.Hour,
+		now:      time.Now,
+		pending:  make(map[string]*PendingPayout),
+	}
+
+	for _, opt := range options {
+		opt(w)
+	}
+
+	return w
+}
+
+func WithApprovalTTL(ttl time.Duration) Option {
+	return func(w *Workflow) {
+		w.ttl = ttl
+	}
+}
+
+func WithClock(now func() time.Time) Option {
+	return func(w *Workflow) {
+		w.now = now
+	}
+}
+
+/**
+ * This function registers a batch for approval
+ * @param {string} preparedBy The person preparing the batch
+ * @param {Batch} batch The payouts to release once approved
+ * @returns A copy of the pending payout holding a snapshot of the batch and its hash
+ */
+func (w *Workflow) Prepare(preparedBy string, batch *Batch) (*PendingPayout, error) {
+	if strings.TrimSpace(preparedBy) == "" {
+		return nil, ErrInvalidActor
+	}
+	if batch == nil || batch.empty() {
+		return nil, ErrEmptyBatch
+	}
+
+	snapshot, err := copyBatch(batch)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := snapshot.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	p := &PendingPayout{
+		ID:         newID(),
+		Batch:      snapshot,
+		Hash:       hash,
+		PreparedBy: preparedBy,
+		PreparedAt: w.now(),
+	}
+	w.pending[p.ID] = p
+	w.record(p.ID, EventPrepared, preparedBy, hash, "")
+	return p.clone(), nil
+}
+
+/**
+ * This function replaces the batch of a pending payout, dropping every existing approval
+ * @param {string} id The pending payout ID
+ * @param {string} actor The person editing the batch, who becomes its preparer
+ * @param {Batch} batch The new batch
+ * @returns A copy of the updated pending payout
+ */
+func (w *Workflow) Update(id, actor string, batch *Batch) (*PendingPayout, error) {
+	if strings.TrimSpace(actor) == "" {
+		return nil, ErrInvalidActor
+	}
+	if batch == nil || batch.empty() {
+		return nil, ErrEmptyBatch
+	}
+
+	snapshot, err := copyBatch(batch)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := snapshot.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	p, err := w.get(id)
+	if err != nil {
+		return nil, err
+	}
+	p.Batch = snapshot
+	p.Hash = hash
+	p.PreparedBy = actor
+	p.PreparedAt = w.now()
+	p.Approvals = nil
+	w.record(id, EventInvalidated, actor, hash, "batch replaced")
+	return p.clone(), nil
+}
+
+/**
+ * This function records an approval of the pending payout's current content
+ * @param {string} id The pending payout ID
+ * @param {string} approver The person approving, who must differ from the preparer
+ * @returns ErrModified if the batch changed since it was prepared
+ */
+func (w *Workflow) Approve(id, approver string) error {
+	if strings.TrimSpace(approver) == "" {
+		return ErrInvalidActor
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	p, err := w.get(id)
+	if err != nil {
+		return err
+	}
+	if err := w.verify(p); err != nil {
+		return err
+	}
+	if sameActor(approver, p.PreparedBy) {
+		return ErrSelfApproval
+	}
+
+	now := w.now()
+	for _, a := range p.Approvals {
+		if sameActor(a.Approver, approver) && now.Before(a.ExpiresAt) {
+			return ErrDuplicateApproval
+		}
+	}
+
+	p.Approvals = append(p.Approvals, Approval{
+		Approver:   approver,
+		Hash:       p.Hash,
+		ApprovedAt: now,
+		ExpiresAt:  now.Add(w.ttl),
+	})
+	w.record(id, EventApproved, approver, p.Hash, "")
+	return nil
+}
+
+/**
+ * This function releases an approved payout through the Payouts module
+ * @param {string} id The pending payout ID
+ * @param {string} submitter The person releasing the payout
+ * @returns The response for each rail in the batch; a payout refused before anything
+ * was paid can be submitted again
+ */
+func (w *Workflow) Submit(ctx context.Context, id, submitter string) (*SubmitResult, error) {
+	if strings.TrimSpace(submitter) == "" {
+		return nil, ErrInvalidActor
+	}
+
+	w.mu.Lock()
+	p, err := w.get(id)
+	if err == nil {
+		err = w.verify(p)
+	}
+	if err == nil && p.SubmittedAt != nil {
+		err = ErrAlreadySubmitted
+	}
+	if err == nil {
+		if n := w.validApprovals(p); n < w.required {
+			err = fmt.Errorf("%w: have %d, need %d", ErrInsufficientApprovals, n, w.required)
+		}
+	}
+	if err != nil {
+		w.mu.Unlock()
+		return nil, err
+	}
+
+	// Mark as submitted before calling out so a concurrent Submit cannot pay twice.
+	now := w.now()
+	p.SubmittedAt = &now
+	batch := p.Batch
+	hash := p.Hash
+	w.mu.Unlock()
+
+	result, sent, err := w.dispatch(ctx, batch)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err != nil {
+		if !sent {
+			// Nothing was paid, so the payout can be submitted again.
+			p.SubmittedAt = nil
+		}
+		w.record(id, EventFailed, submitter, hash, err.Error())
+		return result, err
+	}
+	w.record(id, EventSubmitted, submitter, hash, "")
+	return result, nil
+}
+
+/**
+ * This function gets a pending payout
+ * @param {string} id The pending payout ID
+ * @returns A copy of the pending payout
+ */
+func (w *Workflow) Get(id string) (*PendingPayout, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	p, err := w.get(id)
+	if err != nil {
+		return nil, err
+	}
+	return p.clone(), nil
+}
+
+/**
+ * This function returns the audit history of every payout, oldest first
+ * @returns A copy of the recorded events
+ */
+func (w *Workflow) History() []Event {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]Event(nil), w.history...)
+}
+
+/**
+ * This function writes the audit history as a JSON array
+ * @param {io.Writer} out The destination
+ */
+func (w *Workflow) ExportJSON(out io.Writer) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(w.History())
+}
+
+/**
+ * This function writes the audit history as CSV with a header row
+ * @param {io.Writer} out The destination
+ */
+func (w *Workflow) ExportCSV(out io.Writer) error {
+	cw := csv.NewWriter(out)
+	if err := cw.Write([]string{"payout_id", "type", "actor", "hash", "at", "detail"}); err != nil {
+		return err
+	}
+	for _, e := range w.History() {
+		row := []string{e.PayoutID, string(e.Type), e.Actor, e.Hash, e.At.UTC().Format(time.RFC3339), e.Detail}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (w *Workflow) get(id string) (*PendingPayout, error) {
+	p, ok := w.pending[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return p, nil
+}
+
+// verify recomputes the batch hash; a mismatch drops all approvals.
+func (w *Workflow) verify(p *PendingPayout) error {
+	hash, err := p.Batch.Hash()
+	if err != nil {
+		return err
+	}
+	if hash == p.Hash {
+		return nil
+	}
+
+	p.Approvals = nil
+	w.record(p.ID, EventInvalidated, "", hash, "batch changed from "+p.Hash)
+	return ErrModified
+}
+
+func (w *Workflow) validApprovals(p *PendingPayout) int {
+	now := w.now()
+	seen := make(map[string]bool)
+	for _, a := range p.Approvals {
+		if a.Hash != p.Hash || !now.Before(a.ExpiresAt) || sameActor(a.Approver, p.PreparedBy) {
+			continue
+		}
+		seen[actorKey(a.Approver)] = true
+	}
+	return len(seen)
+}
+
+// dispatch sends the rails of a batch in turn. On error, sent reports whether
+// any payout may have been made.
+func (w *Workflow) dispatch(ctx context.Context, b *Batch) (result *SubmitResult, sent bool, err error) {
+	result = new(SubmitResult)
+	fail := func(rail string, err error) (*SubmitResult, bool, error) {
+		return result, sent || !unsent(err), fmt.Errorf("%s: %w", rail, err)
+	}
+
+	if len(b.Bank) > 0 {
+		if result.Bank, err = w.payouts.Bank(ctx, b.Bank, b.SubAccount); err != nil {
+			return fail("bank", err)
+		}
+		sent = true
+	}
+	if len(b.Airtime) > 0 {
+		if result.Airtime, err = w.payouts.Airtime(ctx, b.Airtime, b.SubAccount); err != nil {
+			return fail("airtime", err)
+		}
+		sent = true
+	}
+	if len(b.Chimoney) > 0 {
+		if result.Chimoney, err = w.payouts.Chimoney(ctx, b.Chimoney, b.SubAccount); err != nil {
+			return fail("chimoney", err)
+		}
+		sent = true
+	}
+	if len(b.GiftCard) > 0 {
+		if result.GiftCard, err = w.payouts.GiftCard(ctx, b.GiftCard, b.SubAccount); err != nil {
+			return fail("gift card", err)
+		}
+	}
+
+	return result, true, nil
+}
+
+// unsent reports whether a failed payout request was certainly not carried
+// out: the API refused it, it never left the process, or a policy or
+// duplicate guard wrapping the client blocked it.
+func unsent(err error) bool {
+	return apierr.Rejected(err) || apierr.NotSent(err) ||
+		errors.Is(err, policy.ErrViolation) || errors.Is(err, dedupe.ErrDuplicate)
+}
+
+func (w *Workflow) record(id string, t EventType, actor, hash, detail string) {
+	w.history = append(w.history, Event{
+		PayoutID: id,
+		Type:     t,
+		Actor:    actor,
+		Hash:     hash,
+		At:       w.now(),
+		Detail:   detail,
+	})
+}
+
+// sameActor compares identities ignoring case and surrounding space, so
+// "Alice" cannot approve a payout prepared by "alice".
+func sameActor(a, b string) bool {
+	return actorKey(a) == actorKey(b)
+}
+
+func actorKey(actor string) string {
+	return strings.ToLower(strings.TrimSpace(actor))
+}
+
+// clone copies a pending payout so callers cannot change it without the lock.
+func (p *PendingPayout) clone() *PendingPayout {
+	c := *p
+	c.Approvals = append([]Approval(nil), p.Approvals...)
+	if p.SubmittedAt != nil {
+		at := *p.SubmittedAt
+		c.SubmittedAt = &at
+	}
+	// The batch was encoded when it was stored, so copying it again succeeds.
+	if batch, err := copyBatch(p.Batch); err == nil {
+		c.Batch = batch
+	}
+	return &c
+}
+
+func copyBatch(b *Batch) (*Batch, error) {
+	data, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	c := new(Batch)
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return "pp_" + hex.EncodeToString(b)
+}
@@ -0,0 +1,263 @@
+package payouts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chimoney/chimoney-go/internal/apierr"
+	"github.com/chimoney/chimoney-go/modules/account"
+)
+
+var (
+	ErrInvalidChiRef = errors.New("invalid chiRef")
+	ErrMissingReason = errors.New("a cancellation reason is required")
+)
+
+type CancelOutcome string
+
+const (
+	OutcomeCancelled        CancelOutcome = "cancelled"
+	OutcomeAlreadyPaid      CancelOutcome = "already_paid"
+	OutcomeAlreadyCancelled CancelOutcome = "already_cancelled"
+	OutcomeNotFound         CancelOutcome = "not_found"
+	OutcomeNotCancellable   CancelOutcome = "not_cancellable"
+	OutcomeFailed           CancelOutcome = "failed"
+)
+
+var (
+	paidStatuses        = []string{"paid", "redeemed", "completed", "success", "successful"}
+	cancelledStatuses   = []string{"cancelled", "canceled", "deleted", "expired", "refunded"}
+	cancellableStatuses = []string{"pending", "unpaid", "issued", "initiated"}
+)
+
+// Cancellation is the outcome of cancelling one payout. PreviousStatus is the
+// status before the attempt and Status the one confirmed after it.
+type Cancellation struct {
+	ChiRef         string        `json:"chiRef"`
+	Outcome        CancelOutcome `json:"outcome"`
+	PreviousStatus string        `json:"previousStatus,omitempty"`
+	Status         string        `json:"status,omitempty"`
+	Err            error         `json:"-"`
+}
+
+// AuditEntry records one cancellation attempt, with the statuses of the
+// payout before and after it as in Cancellation.
+type AuditEntry struct {
+	ChiRef         string        `json:"chiRef"`
+	SubAccount     string        `json:"subAccount,omitempty"`
+	Reason         string        `json:"reason"`
+	Outcome        CancelOutcome `json:"outcome"`
+	PreviousStatus string        `json:"previousStatus,omitempty"`
+	Status         string        `json:"status,omitempty"`
+	Error          string        `json:"error,omitempty"`
+	At             time.Time     `json:"at"`
+}
+
+// AuditTrail records every cancellation attempt, whatever its outcome.
+type AuditTrail interface {
+	Record(entry AuditEntry) error
+}
+
+type MemoryAuditTrail struct {
+	mu      sync.Mutex
+	entries []AuditEntry
+}
+
+func NewMemoryAuditTrail() *MemoryAuditTrail {
+	return &MemoryAuditTrail{}
+}
+
+func (m *MemoryAuditTrail) Record(entry AuditEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func (m *MemoryAuditTrail) Entries() []AuditEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]AuditEntry(nil), m.entries...)
+}
+
+/**
+ * This function replaces the audit trail cancellations are recorded in
+ * @param {AuditTrail} trail The audit trail, in memory by default
+ */
+func (p *Payouts) SetAuditTrail(trail AuditTrail) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.audit = trail
+}
+
+/**
+ * This function returns the audit trail cancellations are recorded in
+ * @returns The audit trail
+ */
+func (p *Payouts) AuditTrail() AuditTrail {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.audit == nil {
+		p.audit = NewMemoryAuditTrail()
+	}
+	return p.audit
+}
+
+/**
+ * This function cancels a payout that has not been claimed yet
+ * @param {string} chiRef The ID of the payout
+ * @param {string} reason Why the payout is cancelled, for the audit trail
+ * @param {string?} subAccount The subAccount of the payout
+ * @returns The confirmed outcome; an error is only returned if the audit trail could not record it
+ */
+func (p *Payouts) Cancel(ctx context.Context, chiRef, reason, subAccount string) (*Cancellation, error) {
+	if strings.TrimSpace(chiRef) == "" {
+		return nil, ErrInvalidChiRef
+	}
+	if strings.TrimSpace(reason) == "" {
+		return nil, ErrMissingReason
+	}
+
+	c := p.cancel(ctx, chiRef, subAccount)
+
+	entry := AuditEntry{
+		ChiRef:         chiRef,
+		SubAccount:     subAccount,
+		Reason:         reason,
+		Outcome:        c.Outcome,
+		PreviousStatus: c.PreviousStatus,
+		Status:         c.Status,
+		At:             p.clock()(),
+	}
+	if c.Err != nil {
+		entry.Error = c.Err.Error()
+	}
+	if err := p.AuditTrail().Record(entry); err != nil {
+		return c, fmt.Errorf("payouts: cancellation of %s not recorded: %v", chiRef, err)
+	}
+	return c, nil
+}
+
+/**
+ * This function cancels several payouts, one after the other
+ * @param {string[]} chiRefs The IDs of the payouts
+ * @param {string} reason Why the payouts are cancelled, for the audit trail
+ * @param {string?} subAccount The subAccount of the payouts
+ * @returns One outcome per payout, in order
+ */
+func (p *Payouts) CancelBulk(ctx context.Context, chiRefs []string, reason, subAccount string) ([]Cancellation, error) {
+	if len(chiRefs) == 0 {
+		return nil, ErrEmptyPayout
+	}
+	if strings.TrimSpace(reason) == "" {
+		return nil, ErrMissingReason
+	}
+
+	results := make([]Cancellation, 0, len(chiRefs))
+	for _, chiRef := range chiRefs {
+		c, err := p.Cancel(ctx, chiRef, reason, subAccount)
+		if c == nil {
+			results = append(results, Cancellation{ChiRef: chiRef, Outcome: OutcomeFailed, Err: err})
+			continue
+		}
+		results = append(results, *c)
+		if err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+func (p *Payouts) cancel(ctx context.Context, chiRef, subAccount string) *Cancellation {
+	c := &Cancellation{ChiRef: chiRef}
+
+	status, found, err := p.currentStatus(ctx, chiRef, subAccount)
+	switch {
+	case err != nil:
+		c.Outcome, c.Err = OutcomeFailed, err
+		return c
+	case !found:
+		c.Outcome = OutcomeNotFound
+		return c
+	}
+	c.PreviousStatus, c.Status = status, status
+
+	switch {
+	case hasStatus(paidStatuses, status):
+		c.Outcome = OutcomeAlreadyPaid
+		return c
+	case hasStatus(cancelledStatuses, status):
+		c.Outcome = OutcomeAlreadyCancelled
+		return c
+	case !hasStatus(cancellableStatuses, status):
+		c.Outcome = OutcomeNotCancellable
+		return c
+	}
+
+	if _, err := account.New(p.client).DeleteUnpaidTransaction(ctx, chiRef, subAccount); err != nil {
+		c.Outcome, c.Err = OutcomeFailed, err
+		return c
+	}
+
+	// Confirm, since the payout may have been claimed while it was being deleted.
+	status, found, err = p.currentStatus(ctx, chiRef, subAccount)
+	switch {
+	case err != nil:
+		c.Outcome, c.Err = OutcomeFailed, fmt.Errorf("payouts: could not confirm cancellation of %s: %v", chiRef, err)
+	case !found:
+		c.Outcome, c.Status = OutcomeCancelled, ""
+	case hasStatus(cancelledStatuses, status):
+		c.Outcome, c.Status = OutcomeCancelled, status
+	case hasStatus(paidStatuses, status):
+		c.Outcome, c.Status = OutcomeAlreadyPaid, status
+	default:
+		c.Outcome, c.Status = OutcomeFailed, status
+		c.Err = fmt.Errorf("payouts: %s is still %s after deletion", chiRef, status)
+	}
+	return c
+}
+
+func (p *Payouts) currentStatus(ctx context.Context, chiRef, subAccount string) (string, bool, error) {
+	resp, err := p.Status(ctx, chiRef, subAccount)
+	if err != nil {
+		if apierr.Status(err) == http.StatusNotFound {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+
+	// The data may be a list of payouts, a payout result or a single payout.
+	var items []PayoutItemResult
+	if err := json.Unmarshal(resp.Data, &items); err != nil {
+		result, err := resp.Result()
+		if err != nil {
+			return "", false, err
+		}
+		items = result.Chimoneys
+		var item PayoutItemResult
+		if len(items) == 0 && json.Unmarshal(resp.Data, &item) == nil {
+			items = append(items, item)
+		}
+	}
+	for _, item := range items {
+		if item.Status != "" && (item.ChiRef == "" || item.ChiRef == chiRef || item.ID == chiRef) {
+			return strings.ToLower(item.Status), true, nil
+		}
+	}
+	return "", false, nil
+}
+
+func hasStatus(statuses []string, status string) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
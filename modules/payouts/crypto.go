@@ -0,0 +1,310 @@
+package payouts
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrInvalidCryptoAddress = errors.New("invalid crypto address")
+	ErrInvalidCryptoIssuer  = errors.New("invalid crypto issuer")
+	ErrInvalidCryptoAsset   = errors.New("invalid crypto asset")
+	ErrInvalidCryptoMemo    = errors.New("invalid crypto memo")
+	ErrUnsupportedNetwork   = errors.New("unsupported crypto network")
+)
+
+type CryptoNetwork string
+
+const (
+	NetworkXRPL     CryptoNetwork = "xrpl"
+	NetworkStellar  CryptoNetwork = "stellar"
+	NetworkEthereum CryptoNetwork = "ethereum"
+	NetworkPolygon  CryptoNetwork = "polygon"
+	NetworkCelo     CryptoNetwork = "celo"
+	NetworkBase     CryptoNetwork = "base"
+	NetworkSolana   CryptoNetwork = "solana"
+)
+
+// evmTokens lists the stablecoins accepted on each EVM chain.
+var evmTokens = map[CryptoNetwork][]string{
+	NetworkEthereum: {"USDC", "USDT"},
+	NetworkPolygon:  {"USDC", "USDT"},
+	NetworkCelo:     {"CUSD", "USDC"},
+	NetworkBase:     {"USDC"},
+}
+
+var solanaTokens = []string{"USDC", "USDT"}
+
+// CryptoDestination is one of XRPLDestination, StellarDestination,
+// EVMDestination or SolanaDestination.
+type CryptoDestination interface {
+	Network() CryptoNetwork
+	Validate() error
+	cryptoDestination()
+}
+
+type XRPLDestination struct {
+	Address        string  `json:"address"`
+	Issuer         string  `json:"issuer,omitempty"`
+	Currency       string  `json:"currency,omitempty"`
+	DestinationTag *uint32 `json:"destinationTag,omitempty"`
+}
+
+type StellarDestination struct {
+	Address   string `json:"address"`
+	AssetCode string `json:"assetCode,omitempty"`
+	Issuer    string `json:"issuer,omitempty"`
+	Memo      string `json:"memo,omitempty"`
+}
+
+type EVMDestination struct {
+	Chain   CryptoNetwork `json:"-"`
+	Address string        `json:"address"`
+	Token   string        `json:"token"`
+}
+
+type SolanaDestination struct {
+	Address string `json:"address"`
+	Token   string `json:"token"`
+}
+
+func (XRPLDestination) Network() CryptoNetwork    { return NetworkXRPL }
+func (StellarDestination) Network() CryptoNetwork { return NetworkStellar }
+func (d EVMDestination) Network() CryptoNetwork   { return d.Chain }
+func (SolanaDestination) Network() CryptoNetwork  { return NetworkSolana }
+
+func (XRPLDestination) cryptoDestination()    {}
+func (StellarDestination) cryptoDestination() {}
+func (EVMDestination) cryptoDestination()     {}
+func (SolanaDestination) cryptoDestination()  {}
+
+var xrplCurrencyPattern = regexp.MustCompile(`^([A-Za-z0-9?!@#$%^&*<>(){}\[\]|]{3}|[0-9A-Fa-f]{40})$`)
+
+/**
+ * This function checks the XRPL classic address, issuer and currency offline
+ * @returns An error wrapping ErrInvalidCryptoAddress, ErrInvalidCryptoIssuer or ErrInvalidCryptoAsset
+ */
+func (d XRPLDestination) Validate() error {
+	if !validXRPLAddress(d.Address) {
+		return fmt.Errorf("%w: %q is not an XRPL classic address", ErrInvalidCryptoAddress, d.Address)
+	}
+	if d.Issuer != "" && !validXRPLAddress(d.Issuer) {
+		return fmt.Errorf("%w: %q is not an XRPL classic address", ErrInvalidCryptoIssuer, d.Issuer)
+	}
+	if d.Currency != "" {
+		if !xrplCurrencyPattern.MatchString(d.Currency) || strings.EqualFold(d.Currency, "XRP") && d.Issuer != "" {
+			return fmt.Errorf("%w: %q is not an XRPL currency code", ErrInvalidCryptoAsset, d.Currency)
+		}
+		if !strings.EqualFold(d.Currency, "XRP") && d.Issuer == "" {
+			return fmt.Errorf("%w: issued currency %s needs an issuer", ErrInvalidCryptoIssuer, d.Currency)
+		}
+	}
+	return nil
+}
+
+var stellarAssetPattern = regexp.MustCompile(`^[A-Za-z0-9]{1,12}$`)
+
+/**
+ * This function checks the Stellar account, asset and memo offline
+ * @returns An error wrapping ErrInvalidCryptoAddress, ErrInvalidCryptoIssuer, ErrInvalidCryptoAsset or ErrInvalidCryptoMemo
+ */
+func (d StellarDestination) Validate() error {
+	if !validStellarAddress(d.Address) {
+		return fmt.Errorf("%w: %q is not a Stellar account ID", ErrInvalidCryptoAddress, d.Address)
+	}
+	if d.AssetCode != "" && !stellarAssetPattern.MatchString(d.AssetCode) {
+		return fmt.Errorf("%w: %q is not a Stellar asset code", ErrInvalidCryptoAsset, d.AssetCode)
+	}
+	if d.AssetCode != "" && !strings.EqualFold(d.AssetCode, "XLM") && !validStellarAddress(d.Issuer) {
+		return fmt.Errorf("%w: %q is not a Stellar account ID", ErrInvalidCryptoIssuer, d.Issuer)
+	}
+	if len(d.Memo) > 28 {
+		return fmt.Errorf("%w: text memos are limited to 28 bytes", ErrInvalidCryptoMemo)
+	}
+	return nil
+}
+
+var evmAddressPattern = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+
+/**
+ * This function checks the EVM address, including its EIP-55 checksum when mixed case
+ * @returns An error wrapping ErrUnsupportedNetwork, ErrInvalidCryptoAddress or ErrInvalidCryptoAsset
+ */
+func (d EVMDestination) Validate() error {
+	tokens, ok := evmTokens[d.Chain]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnsupportedNetwork, d.Chain)
+	}
+	if !containsFold(tokens, d.Token) {
+		return fmt.Errorf("%w: %s is not supported on %s", ErrInvalidCryptoAsset, d.Token, d.Chain)
+	}
+	if !evmAddressPattern.MatchString(d.Address) {
+		return fmt.Errorf("%w: %q is not an EVM address", ErrInvalidCryptoAddress, d.Address)
+	}
+	hexPart := d.Address[2:]
+	if hexPart != strings.ToLower(hexPart) && hexPart != strings.ToUpper(hexPart) && d.Address != ChecksumEVMAddress(d.Address) {
+		return fmt.Errorf("%w: %q fails its EIP-55 checksum", ErrInvalidCryptoAddress, d.Address)
+	}
+	return nil
+}
+
+/**
+ * This function checks the Solana address and token offline
+ * @returns An error wrapping ErrInvalidCryptoAddress or ErrInvalidCryptoAsset
+ */
+func (d SolanaDestination) Validate() error {
+	if !containsFold(solanaTokens, d.Token) {
+		return fmt.Errorf("%w: %s is not supported on solana", ErrInvalidCryptoAsset, d.Token)
+	}
+	key, ok := base58Decode(d.Address, bitcoinAlphabet)
+	if !ok || len(key) != 32 {
+		return fmt.Errorf("%w: %q is not a Solana address", ErrInvalidCryptoAddress, d.Address)
+	}
+	return nil
+}
+
+/**
+ * This function returns the EIP-55 mixed-case form of an EVM address
+ * @param {string} address A 0x-prefixed hex address in any case
+ * @returns The checksummed address
+ */
+func ChecksumEVMAddress(address string) string {
+	lower := strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(address, "0x"), "0X"))
+	hash := hex.EncodeToString(keccak256([]byte(lower)))
+
+	out := []byte(lower)
+	for i, ch := range out {
+		if ch >= 'a' && ch <= 'f' && hash[i] >= '8' {
+			out[i] = ch - 'a' + 'A'
+		}
+	}
+	return "0x" + string(out)
+}
+
+type CryptoPayment struct {
+	// Deprecated: set Destination to an XRPLDestination instead.
+	XRPL struct {
+		Address  string `json:"address"`
+		Issuer   string `json:"issuer"`
+		Currency string `json:"currency"`
+	} `json:"xrpl,omitempty"`
+
+	Destination CryptoDestination `json:"-"`
+}
+
+/**
+ * This function validates the payment destination without any network calls
+ * @returns An error if the address, issuer, asset or memo is invalid
+ */
+func (c CryptoPayment) Validate() error {
+	if c.Destination != nil {
+		return c.Destination.Validate()
+	}
+	legacy := XRPLDestination{
+		Address:  c.XRPL.Address,
+		Issuer:   c.XRPL.Issuer,
+		Currency: c.XRPL.Currency,
+	}
+	return legacy.Validate()
+}
+
+func (c CryptoPayment) MarshalJSON() ([]byte, error) {
+	if c.Destination == nil {
+		return json.Marshal(map[string]interface{}{"xrpl": c.XRPL})
+	}
+	return json.Marshal(map[string]interface{}{
+		string(c.Destination.Network()): c.Destination,
+	})
+}
+
+func validateCryptoPayments(payments []CryptoPayment) error {
+	for i, p := range payments {
+		if err := p.Validate(); err != nil {
+			return fmt.Errorf("payouts: crypto payment %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func validXRPLAddress(address string) bool {
+	if !strings.HasPrefix(address, "r") || len(address) < 25 || len(address) > 35 {
+		return false
+	}
+	raw, ok := base58Decode(address, rippleAlphabet)
+	if !ok || len(raw) != 25 || raw[0] != 0x00 {
+		return false
+	}
+	first := sha256.Sum256(raw[:21])
+	second := sha256.Sum256(first[:])
+	return string(second[:4]) == string(raw[21:])
+}
+
+func validStellarAddress(address string) bool {
+	if len(address) != 56 || address[0] != 'G' {
+		return false
+	}
+	raw, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(address)
+	if err != nil || len(raw) != 35 || raw[0] != 6<<3 {
+		return false
+	}
+	sum := crc16XModem(raw[:33])
+	return raw[33] == byte(sum) && raw[34] == byte(sum>>8)
+}
+
+func crc16XModem(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+const (
+	bitcoinAlphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	rippleAlphabet  = "rpshnaf39wBUDNEGHJKLM4PQRST7VWXYZ2bcdeCg65jkm8oFqi1tuvAxyz"
+)
+
+func base58Decode(s, alphabet string) ([]byte, bool) {
+	if s == "" {
+		return nil, false
+	}
+	n := new(big.Int)
+	radix := big.NewInt(58)
+	for _, ch := range s {
+		idx := strings.IndexRune(alphabet, ch)
+		if idx < 0 {
+			return nil, false
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(idx)))
+	}
+
+	leading := 0
+	for leading < len(s) && s[leading] == alphabet[0] {
+		leading++
+	}
+	return append(make([]byte, leading), n.Bytes()...), true
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
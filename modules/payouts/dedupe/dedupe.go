@@ -0,0 +1,293 @@
+package dedupe
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/chimoney/chimoney-go/internal/apierr"
+	"github.com/chimoney/chimoney-go/internal/stream"
+	"github.com/chimoney/chimoney-go/modules/policy"
+)
+
+var ErrDuplicate = errors.New("duplicate payout")
+
+type Client interface {
+	Do(ctx context.Context, method, path string, body interface{}, v interface{}, params map[string]string) error
+}
+
+// DuplicateError describes a payout item already sent within the window. It
+// matches ErrDuplicate with errors.Is.
+type DuplicateError struct {
+	Rail        string
+	Index       int
+	Fingerprint string
+	FirstSeen   time.Time
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("dedupe: %s payout item %d duplicates one sent at %s", e.Rail, e.Index, e.FirstSeen.Format(time.RFC3339))
+}
+
+func (e *DuplicateError) Unwrap() error {
+	return ErrDuplicate
+}
+
+type Mode int
+
+const (
+	// Block rejects a request containing a duplicate before it is sent.
+	Block Mode = iota
+	// Flag reports duplicates to the flag handler and sends the request anyway.
+	Flag
+)
+
+type overrideKey struct{}
+
+// WithOverride marks a call as an intentional repeat that must not be blocked.
+func WithOverride(ctx context.Context) context.Context {
+	return context.WithValue(ctx, overrideKey{}, true)
+}
+
+type Guard struct {
+	next   Client
+	store  Store
+	window time.Duration
+	mode   Mode
+	now    func() time.Time
+	onFlag func(*DuplicateError)
+
+	mu sync.Mutex
+}
+
+type Option func(*Guard)
+
+func Wrap(next Client, options ...Option) *Guard {
+	g := &Guard{
+		next:   next,
+		store:  NewMemoryStore(),
+		window: 24 * time.Hour,
+		mode:   Block,
+		now:    time.Now,
+	}
+
+	for _, opt := range options {
+		opt(g)
+	}
+
+	return g
+}
+
+func WithStore(store Store) Option {
+	return func(g *Guard) {
+		g.store = store
+	}
+}
+
+func WithWindow(window time.Duration) Option {
+	return func(g *Guard) {
+		g.window = window
+	}
+}
+
+func WithMode(mode Mode) Option {
+	return func(g *Guard) {
+		g.mode = mode
+	}
+}
+
+func WithFlagHandler(fn func(*DuplicateError)) Option {
+	return func(g *Guard) {
+		g.onFlag = fn
+	}
+}
+
+func WithClock(now func() time.Time) Option {
+	return func(g *Guard) {
+		g.now = now
+	}
+}
+
+func (g *Guard) Do(ctx context.Context, method, path string, body interface{}, v interface{}, params map[string]string) error {
+	rail, ok := rails[path]
+	if !ok {
+		return g.next.Do(ctx, method, path, body, v, params)
+	}
+
+	fingerprints, err := Fingerprints(path, body)
+	if err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	now := g.now()
+	override, _ := ctx.Value(overrideKey{}).(bool)
+	var dups []*DuplicateError
+	batch := make(map[string]bool)
+	for i, fp := range fingerprints {
+		seen, found, err := g.store.Get(fp)
+		if err != nil {
+			g.mu.Unlock()
+			return err
+		}
+		switch {
+		case found && now.Sub(seen) < g.window:
+			dups = append(dups, &DuplicateError{Rail: rail.name, Index: i, Fingerprint: fp, FirstSeen: seen})
+		case batch[fp]:
+			dups = append(dups, &DuplicateError{Rail: rail.name, Index: i, Fingerprint: fp, FirstSeen: now})
+		}
+		batch[fp] = true
+	}
+	if len(dups) > 0 && !override && g.mode == Block {
+		g.mu.Unlock()
+		return dups[0]
+	}
+
+	// Record before sending so a concurrent identical request is caught too.
+	previous := make(map[string]time.Time)
+	for fp := range batch {
+		if seen, found, _ := g.store.Get(fp); found {
+			previous[fp] = seen
+		}
+		if err := g.store.Put(fp, now, g.window); err != nil {
+			g.mu.Unlock()
+			return err
+		}
+	}
+	g.mu.Unlock()
+
+	if !override && g.onFlag != nil {
+		for _, d := range dups {
+			g.onFlag(d)
+		}
+	}
+
+	if err := g.next.Do(ctx, method, path, body, v, params); err != nil {
+		// A timeout or transport error may come after the payout was made,
+		// so only a request refused or never sent lets it be sent again.
+		if !unsent(err) {
+			return err
+		}
+		g.mu.Lock()
+		for fp := range batch {
+			if seen, ok := previous[fp]; ok {
+				g.store.Put(fp, seen, g.window)
+			} else {
+				g.store.Delete(fp)
+			}
+		}
+		g.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Stream passes requests other than payouts to the wrapped client, unread if
+// it can stream. Payouts are checked through Do first.
+func (g *Guard) Stream(ctx context.Context, method, path string, body interface{}, params map[string]string) (io.ReadCloser, error) {
+	s, ok := g.next.(stream.Streamer)
+	if _, guarded := rails[path]; ok && !guarded {
+		return s.Stream(ctx, method, path, body, params)
+	}
+	return stream.Buffered(ctx, g.Do, method, path, body, params)
+}
+
+// unsent reports whether no payout was made: the API refused the request, a
+// policy wrapped by the guard blocked it, or it never left the process.
+func unsent(err error) bool {
+	return apierr.Rejected(err) || apierr.NotSent(err) || errors.Is(err, policy.ErrViolation)
+}
+
+type railSpec struct {
+	name        string
+	list        string
+	destination []string
+}
+
+var rails = map[string]railSpec{
+	"/payouts/airtime":   {name: "airtime", list: "airtime", destination: []string{"countryToSend", "phoneNumber"}},
+	"/payouts/bank":      {name: "bank", list: "banks", destination: []string{"countryToSend", "account_bank", "account_number"}},
+	"/payouts/chimoney":  {name: "chimoney", list: "chimoneys", destination: []string{"email", "twitter"}},
+	"/payouts/gift-card": {name: "gift_card", list: "giftCards", destination: []string{"email", "redeemData.productId", "redeemData.countryCode"}},
+	"/payouts/interledger-wallet-address": {
+		name: "interledger", list: "interledgerWallets", destination: []string{"interledgerWalletAddress", "currency", "amountToDeliver"},
+	},
+	"/payouts/mobile-money": {
+		name: "mobile_money", list: "momos", destination: []string{"countryToSend", "phoneNumber", "momoCode"},
+	},
+}
+
+/**
+ * This function fingerprints every item of a payout request
+ * @param {string} path The API path of the payout rail
+ * @param {object} body The request body
+ * @returns One fingerprint per item, from the rail, normalized destination, amount and reference
+ */
+func Fingerprints(path string, body interface{}) ([]string, error) {
+	rail, ok := rails[path]
+	if !ok {
+		return nil, fmt.Errorf("dedupe: %s is not a payout rail", path)
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("dedupe: unexpected request body for %s: %v", path, err)
+	}
+
+	items, _ := doc[rail.list].([]interface{})
+	fps := make([]string, 0, len(items))
+	for _, item := range items {
+		fields, _ := item.(map[string]interface{})
+		parts := []string{rail.name}
+		for _, f := range rail.destination {
+			parts = append(parts, normalize(lookup(fields, f)))
+		}
+		amount, _ := fields["valueInUSD"].(float64)
+		parts = append(parts, fmt.Sprintf("%.2f", amount))
+		parts = append(parts, strings.TrimSpace(fmt.Sprint(lookup(fields, "reference"))))
+
+		sum := sha256.Sum256([]byte(strings.Join(parts, "|")))
+		fps = append(fps, hex.EncodeToString(sum[:]))
+	}
+	return fps, nil
+}
+
+func lookup(fields map[string]interface{}, path string) interface{} {
+	var cur interface{} = fields
+	for _, key := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		cur = m[key]
+	}
+	if cur == nil {
+		return ""
+	}
+	return cur
+}
+
+// normalize lowercases a destination and strips formatting such as spaces,
+// dashes, a leading "+" or "@", so the same destination always matches.
+func normalize(v interface{}) string {
+	s := strings.ToLower(strings.TrimSpace(fmt.Sprint(v)))
+	s = strings.TrimLeft(s, "+@")
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '-' || r == '(' || r == ')' {
+			return -1
+		}
+		return r
+	}, s)
+}
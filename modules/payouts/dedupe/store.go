@@ -0,0 +1,56 @@
+package dedupe
+
+import (
+	"sync"
+	"time"
+)
+
+// Store keeps the time each fingerprint was last sent. Implementations backed
+// by a shared database let several processes guard against the same duplicates.
+type Store interface {
+	Get(fingerprint string) (time.Time, bool, error)
+	// Put records a fingerprint; ttl is how long it must be kept at least.
+	Put(fingerprint string, at time.Time, ttl time.Duration) error
+	Delete(fingerprint string) error
+}
+
+type memoryEntry struct {
+	at      time.Time
+	expires time.Time
+}
+
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]memoryEntry)}
+}
+
+func (s *MemoryStore) Get(fingerprint string) (time.Time, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[fingerprint]
+	return e.at, ok, nil
+}
+
+func (s *MemoryStore) Put(fingerprint string, at time.Time, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for fp, e := range s.entries {
+		if at.After(e.expires) {
+			delete(s.entries, fp)
+		}
+	}
+	s.entries[fingerprint] = memoryEntry{at: at, expires: at.Add(ttl)}
+	return nil
+}
+
+func (s *MemoryStore) Delete(fingerprint string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, fingerprint)
+	return nil
+}
@@ -0,0 +1,338 @@
+package giftcards
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chimoney/chimoney-go/modules/info"
+	"github.com/chimoney/chimoney-go/modules/payouts"
+)
+
+var (
+	ErrProductNotFound     = errors.New("gift card product not found")
+	ErrInvalidDenomination = errors.New("amount is not an allowed denomination")
+	ErrInvalidRecipient    = errors.New("gift card recipient has no email")
+)
+
+// Snap decides what happens to an amount that is not an allowed denomination.
+type Snap int
+
+const (
+	// Exact rejects amounts that are not allowed.
+	Exact Snap = iota
+	// Down uses the closest allowed amount at or below the requested one.
+	Down
+	// Up uses the closest allowed amount at or above the requested one.
+	Up
+	// Nearest uses the closest allowed amount in either direction.
+	Nearest
+)
+
+type Query struct {
+	Brand        string
+	Country      string
+	Denomination float64
+}
+
+// Card describes a gift card to send. LocalValue is in the product's currency.
+type Card struct {
+	Email      string
+	ProductID  string
+	LocalValue float64
+	Snap       Snap
+}
+
+type Catalog struct {
+	info      *info.Info
+	ttl       time.Duration
+	now       func() time.Time
+	assetType string
+
+	mu       sync.Mutex
+	products []info.Asset
+	fetched  time.Time
+}
+
+type Option func(*Catalog)
+
+func New(i *info.Info, options ...Option) *Catalog {
+	c := &Catalog{
+		info:      i,
+		ttl:       time.Hour,
+		now:       time.Now,
+		assetType: "gift",
+	}
+
+	for _, opt := range options {
+		opt(c)
+	}
+
+	return c
+}
+
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(c *Catalog) {
+		c.ttl = ttl
+	}
+}
+
+// WithAssetType lists the supported assets of another type, e.g. airtime,
+// instead of gift cards.
+func WithAssetType(t string) Option {
+	return func(c *Catalog) {
+		c.assetType = strings.ToLower(t)
+	}
+}
+
+func WithClock(now func() time.Time) Option {
+	return func(c *Catalog) {
+		c.now = now
+	}
+}
+
+/**
+ * This function lists the gift card products of the catalog
+ * @returns Every gift card product, from cache when it is fresh enough
+ */
+func (c *Catalog) Products(ctx context.Context) ([]info.Asset, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.products != nil && c.now().Sub(c.fetched) < c.ttl {
+		return c.products, nil
+	}
+
+	resp, err := c.info.GetSupportedAssets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	assets, err := resp.Assets()
+	if err != nil {
+		return nil, err
+	}
+
+	products := make([]info.Asset, 0, len(assets))
+	for _, a := range assets {
+		// Untyped assets are gift cards.
+		if (a.Type == "" && c.assetType == "gift") || strings.Contains(strings.ToLower(a.Type), c.assetType) {
+			products = append(products, a)
+		}
+	}
+	c.products = products
+	c.fetched = c.now()
+	return products, nil
+}
+
+/**
+ * This function searches the catalog
+ * @param {Query} q Brand (matched against the brand and product name), country and local denomination, each optional
+ * @returns The matching products
+ */
+func (c *Catalog) Search(ctx context.Context, q Query) ([]info.Asset, error) {
+	products, err := c.Products(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	brand := strings.ToLower(strings.TrimSpace(q.Brand))
+	var matches []info.Asset
+	for _, p := range products {
+		if q.Country != "" && !strings.EqualFold(p.CountryCode, q.Country) {
+			continue
+		}
+		if brand != "" && !strings.Contains(strings.ToLower(p.Brand), brand) && !strings.Contains(strings.ToLower(p.Name), brand) {
+			continue
+		}
+		if q.Denomination > 0 && !allows(p, q.Denomination) {
+			continue
+		}
+		matches = append(matches, p)
+	}
+	return matches, nil
+}
+
+/**
+ * This function looks up a product by ID
+ * @param {string} productID The ID of the product
+ * @returns The product, or ErrProductNotFound
+ */
+func (c *Catalog) Product(ctx context.Context, productID string) (*info.Asset, error) {
+	products, err := c.Products(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range products {
+		if products[i].ProductID == productID {
+			p := products[i]
+			return &p, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrProductNotFound, productID)
+}
+
+/**
+ * This function builds a gift card payout the API accepts
+ * @param {Card} card The recipient, product and local value
+ * @returns The payload with an allowed denomination and its USD value
+ */
+func (c *Catalog) Build(ctx context.Context, card Card) (*payouts.GiftCardPayload, error) {
+	if strings.TrimSpace(card.Email) == "" {
+		return nil, ErrInvalidRecipient
+	}
+	product, err := c.Product(ctx, card.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	value, err := SnapAmount(*product, card.LocalValue, card.Snap)
+	if err != nil {
+		return nil, err
+	}
+	usd, err := c.valueInUSD(ctx, *product, value)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := &payouts.GiftCardPayload{
+		Email:      card.Email,
+		ValueInUSD: usd,
+	}
+	payload.RedeemData.ProductID = product.ProductID
+	payload.RedeemData.CountryCode = product.CountryCode
+	payload.RedeemData.ValueInLocalCurrency = value
+	return payload, nil
+}
+
+/**
+ * This function fits an amount to the denominations of a product
+ * @param {Asset} product The gift card product
+ * @param {number} value The requested amount in the product's currency
+ * @param {Snap} snap How to treat an amount that is not allowed
+ * @returns The allowed amount, or ErrInvalidDenomination
+ */
+func SnapAmount(product info.Asset, value float64, snap Snap) (float64, error) {
+	if value <= 0 {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidDenomination, value)
+	}
+	if allows(product, value) {
+		return round2(value), nil
+	}
+
+	var snapped float64
+	var ok bool
+	if len(product.FixedDenominations) > 0 {
+		snapped, ok = snapFixed(product.FixedDenominations, value, snap)
+	} else {
+		snapped, ok = snapRange(product.MinDenomination, product.MaxDenomination, value, snap)
+	}
+	if !ok {
+		return 0, fmt.Errorf("%w: %v %s for product %s", ErrInvalidDenomination, value, product.Currency, product.ProductID)
+	}
+	return round2(snapped), nil
+}
+
+func allows(p info.Asset, value float64) bool {
+	if len(p.FixedDenominations) > 0 {
+		for _, f := range p.FixedDenominations {
+			if sameAmount(f, value) {
+				return true
+			}
+		}
+		return false
+	}
+	if p.MinDenomination > 0 && value < p.MinDenomination {
+		return false
+	}
+	if p.MaxDenomination > 0 && value > p.MaxDenomination {
+		return false
+	}
+	return true
+}
+
+func snapFixed(fixed []float64, value float64, snap Snap) (float64, bool) {
+	sorted := append([]float64(nil), fixed...)
+	sort.Float64s(sorted)
+
+	switch snap {
+	case Down:
+		for i := len(sorted) - 1; i >= 0; i-- {
+			if sorted[i] <= value {
+				return sorted[i], true
+			}
+		}
+	case Up:
+		for _, f := range sorted {
+			if f >= value {
+				return f, true
+			}
+		}
+	case Nearest:
+		best := sorted[0]
+		for _, f := range sorted[1:] {
+			if math.Abs(f-value) < math.Abs(best-value) {
+				best = f
+			}
+		}
+		return best, true
+	}
+	return 0, false
+}
+
+func snapRange(min, max, value float64, snap Snap) (float64, bool) {
+	switch {
+	case min > 0 && value < min && (snap == Up || snap == Nearest):
+		return min, true
+	case max > 0 && value > max && (snap == Down || snap == Nearest):
+		return max, true
+	}
+	return 0, false
+}
+
+// valueInUSD prefers the catalog's own sender prices and rate, and only asks
+// the API to convert when the catalog has neither. The result is rounded up
+// to the cent so it always covers the local value.
+func (c *Catalog) valueInUSD(ctx context.Context, p info.Asset, local float64) (float64, error) {
+	if strings.EqualFold(p.SenderCurrency, "USD") {
+		for i, f := range p.FixedDenominations {
+			if sameAmount(f, local) && i < len(p.FixedSenderDenominations) {
+				return ceil2(p.FixedSenderDenominations[i]), nil
+			}
+		}
+		if p.ExchangeRate > 0 {
+			return ceil2(local * p.ExchangeRate), nil
+		}
+	}
+	if strings.EqualFold(p.Currency, "USD") {
+		return ceil2(local), nil
+	}
+
+	resp, err := c.info.GetLocalAmountInUSD(ctx, p.Currency, local)
+	if err != nil {
+		return 0, err
+	}
+	var data struct {
+		AmountInUSD *float64 `json:"amountInUSD"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil || data.AmountInUSD == nil {
+		return 0, fmt.Errorf("giftcards: unexpected conversion response for %v %s", local, p.Currency)
+	}
+	return ceil2(*data.AmountInUSD), nil
+}
+
+func sameAmount(a, b float64) bool {
+	return math.Abs(a-b) < 0.005
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func ceil2(v float64) float64 {
+	return math.Ceil(v*100-1e-9) / 100
+}
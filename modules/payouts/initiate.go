@@ -0,0 +1,70 @@
+package payouts
+
+import (
+	"context"
+)
+
+// InitiateOptions holds everything the initiate endpoint accepts. Extra is
+// merged into the request last, for fields not covered here.
+type InitiateOptions struct {
+	Chimoneys           []ChimoneyPayload
+	TurnOffNotification bool
+	RedirectURL         string
+	CryptoPayments      []CryptoPayment
+	SubAccount          string
+	Extra               map[string]interface{}
+}
+
+// InitiateResponse exposes the payment link, issue ID and chiRefs of an
+// initiated payout alongside the raw response.
+type InitiateResponse struct {
+	PayoutResponse
+	PaymentLink string             `json:"paymentLink,omitempty"`
+	IssueID     string             `json:"issueID,omitempty"`
+	ChiRefs     []string           `json:"chiRefs"`
+	Chimoneys   []PayoutItemResult `json:"chimoneys"`
+}
+
+/**
+ * This function initiates a Chimoney payout
+ * @param {InitiateOptions} opts The payouts and request options
+ * @returns The response from the Chimoney API with its payment link, issue ID and chiRefs, left empty if the data cannot be decoded
+ */
+func (p *Payouts) Initiate(ctx context.Context, opts InitiateOptions) (*InitiateResponse, error) {
+	if err := validateCryptoPayments(opts.CryptoPayments); err != nil {
+		return nil, err
+	}
+
+	req := map[string]interface{}{
+		"chimoneys":           opts.Chimoneys,
+		"turnOffNotification": opts.TurnOffNotification,
+	}
+	if opts.RedirectURL != "" {
+		req["redirect_url"] = opts.RedirectURL
+	}
+	if len(opts.CryptoPayments) > 0 {
+		req["crypto_payments"] = opts.CryptoPayments
+	}
+	if opts.SubAccount != "" {
+		req["subAccount"] = opts.SubAccount
+	}
+	for k, v := range opts.Extra {
+		req[k] = v
+	}
+
+	resp := new(InitiateResponse)
+	if err := p.client.Do(ctx, "POST", "/payouts/initiate", req, &resp.PayoutResponse, nil); err != nil {
+		return resp, err
+	}
+
+	result := resp.sentResult()
+	resp.PaymentLink = result.PaymentLink
+	resp.IssueID = result.IssueID
+	resp.Chimoneys = result.Chimoneys
+	for _, c := range result.Chimoneys {
+		if c.ChiRef != "" {
+			resp.ChiRefs = append(resp.ChiRefs, c.ChiRef)
+		}
+	}
+	return resp, nil
+}
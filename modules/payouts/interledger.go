@@ -0,0 +1,115 @@
+package payouts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrInvalidWalletAddress = errors.New("invalid interledger wallet address")
+	ErrInvalidPayoutAmount  = errors.New("invalid payout amount")
+	ErrEmptyPayout          = errors.New("no payouts provided")
+)
+
+type InterledgerPayload struct {
+	WalletAddress   string  `json:"interledgerWalletAddress"`
+	ValueInUSD      float64 `json:"valueInUSD,omitempty"`
+	Currency        string  `json:"currency,omitempty"`
+	AmountToDeliver float64 `json:"amountToDeliver,omitempty"`
+	Narration       string  `json:"narration,omitempty"`
+	Reference       string  `json:"reference,omitempty"`
+}
+
+type InterledgerResult struct {
+	WalletAddress string `json:"walletAddress"`
+	PayoutItemResult
+}
+
+type InterledgerResponse struct {
+	PayoutResponse
+	Results     []InterledgerResult `json:"results"`
+	PaymentLink string              `json:"paymentLink,omitempty"`
+	IssueID     string              `json:"issueID,omitempty"`
+}
+
+var hostPattern = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.)+[a-zA-Z]{2,63}(:[0-9]{1,5})?$`)
+
+/**
+ * This function resolves an interledger wallet address to its https URL
+ * @param {string} address A payment pointer such as $ilp.example.com/alice, or an https:// wallet address
+ * @returns The https URL of the wallet address
+ */
+func ResolveWalletAddress(address string) (string, error) {
+	address = strings.TrimSpace(address)
+
+	if strings.HasPrefix(address, "$") {
+		u, err := url.Parse("https://" + address[1:])
+		if err != nil || !hostPattern.MatchString(u.Host) || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+			return "", fmt.Errorf("%w: %q is not a payment pointer", ErrInvalidWalletAddress, address)
+		}
+		if u.Path == "" || u.Path == "/" {
+			u.Path = "/.well-known/pay"
+		}
+		return u.String(), nil
+	}
+
+	u, err := url.Parse(address)
+	if err != nil || u.Scheme != "https" || !hostPattern.MatchString(u.Host) || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return "", fmt.Errorf("%w: %q must be a $pointer or an https:// URL", ErrInvalidWalletAddress, address)
+	}
+	return u.String(), nil
+}
+
+/**
+ * This function sends payouts to interledger wallet addresses
+ * @param {InterledgerPayload[]} wallets Array of interledger payouts
+ * @param {string?} subAccount The subAccount for the transaction
+ * @returns The response from the Chimoney API with a result per wallet
+ */
+func (p *Payouts) InterledgerWalletAddress(ctx context.Context, wallets []InterledgerPayload, subAccount string) (*InterledgerResponse, error) {
+	if len(wallets) == 0 {
+		return nil, ErrEmptyPayout
+	}
+	for i, w := range wallets {
+		if _, err := ResolveWalletAddress(w.WalletAddress); err != nil {
+			return nil, fmt.Errorf("payouts: wallet %d: %w", i, err)
+		}
+		if w.ValueInUSD <= 0 && w.AmountToDeliver <= 0 {
+			return nil, fmt.Errorf("payouts: wallet %d: %w", i, ErrInvalidPayoutAmount)
+		}
+		if w.AmountToDeliver > 0 && w.Currency == "" {
+			return nil, fmt.Errorf("payouts: wallet %d: amountToDeliver needs a currency: %w", i, ErrInvalidPayoutAmount)
+		}
+	}
+
+	req := map[string]interface{}{
+		"interledgerWallets": wallets,
+	}
+	if subAccount != "" {
+		req["subAccount"] = subAccount
+	}
+
+	resp := new(InterledgerResponse)
+	if err := p.client.Do(ctx, "POST", "/payouts/interledger-wallet-address", req, &resp.PayoutResponse, nil); err != nil {
+		return resp, err
+	}
+
+	result := resp.sentResult()
+	resp.PaymentLink = result.PaymentLink
+	resp.IssueID = result.IssueID
+	for i, item := range result.Chimoneys {
+		r := InterledgerResult{PayoutItemResult: item}
+		if i < len(wallets) {
+			r.WalletAddress = wallets[i].WalletAddress
+		}
+		if addr, ok := item.Meta["interledgerWalletAddress"].(string); ok {
+			r.WalletAddress = addr
+		}
+		resp.Results = append(resp.Results, r)
+	}
+	return resp, nil
+}
@@ -0,0 +1,135 @@
+package payouts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chimoney/chimoney-go/modules/info"
+)
+
+var (
+	ErrInvalidPhoneNumber  = errors.New("invalid phone number")
+	ErrUnsupportedProvider = errors.New("unsupported mobile money provider")
+)
+
+// MobileMoneyCodesTTL is how long the provider list used to validate mobile
+// money payouts is cached, unless SetInfoCache shares another cache.
+const MobileMoneyCodesTTL = time.Hour
+
+type MobileMoneyPayload struct {
+	CountryToSend string  `json:"countryToSend"`
+	PhoneNumber   string  `json:"phoneNumber"`
+	MomoCode      string  `json:"momoCode"`
+	ValueInUSD    float64 `json:"valueInUSD"`
+	Reference     string  `json:"reference,omitempty"`
+}
+
+type MobileMoneyResult struct {
+	PhoneNumber string `json:"phoneNumber"`
+	PayoutItemResult
+}
+
+type MobileMoneyResponse struct {
+	PayoutResponse
+	Results     []MobileMoneyResult `json:"results"`
+	PaymentLink string              `json:"paymentLink,omitempty"`
+	IssueID     string              `json:"issueID,omitempty"`
+}
+
+/**
+ * This function sends mobile money payouts
+ * @param {MobileMoneyPayload[]} momos Array of mobile money payouts
+ * @param {string?} subAccount The subAccount for the transaction
+ * @returns The response from the Chimoney API with a result per recipient
+ */
+func (p *Payouts) MobileMoney(ctx context.Context, momos []MobileMoneyPayload, subAccount string) (*MobileMoneyResponse, error) {
+	if len(momos) == 0 {
+		return nil, ErrEmptyPayout
+	}
+	for i, m := range momos {
+		if strings.TrimSpace(m.PhoneNumber) == "" {
+			return nil, fmt.Errorf("payouts: mobile money %d: %w", i, ErrInvalidPhoneNumber)
+		}
+		if m.ValueInUSD <= 0 {
+			return nil, fmt.Errorf("payouts: mobile money %d: %w", i, ErrInvalidPayoutAmount)
+		}
+	}
+
+	codes, err := p.InfoCache().MobileMoneyCodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i, m := range momos {
+		if !supportsProvider(codes, m.CountryToSend, m.MomoCode) {
+			return nil, fmt.Errorf("payouts: mobile money %d: %w: %q in %s", i, ErrUnsupportedProvider, m.MomoCode, m.CountryToSend)
+		}
+	}
+
+	req := map[string]interface{}{
+		"momos": momos,
+	}
+	if subAccount != "" {
+		req["subAccount"] = subAccount
+	}
+
+	resp := new(MobileMoneyResponse)
+	if err := p.client.Do(ctx, "POST", "/payouts/mobile-money", req, &resp.PayoutResponse, nil); err != nil {
+		return resp, err
+	}
+
+	result := resp.sentResult()
+	resp.PaymentLink = result.PaymentLink
+	resp.IssueID = result.IssueID
+	for i, item := range result.Chimoneys {
+		r := MobileMoneyResult{PayoutItemResult: item}
+		if i < len(momos) {
+			r.PhoneNumber = momos[i].PhoneNumber
+		}
+		if phone, ok := item.Meta["phoneNumber"].(string); ok {
+			r.PhoneNumber = phone
+		}
+		resp.Results = append(resp.Results, r)
+	}
+	return resp, nil
+}
+
+/**
+ * This function shares a cache of the Info lookups, such as mobile money codes, with other modules
+ * @param {info.Cache} cache The cache, one of its own with MobileMoneyCodesTTL by default
+ */
+func (p *Payouts) SetInfoCache(cache *info.Cache) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cache = cache
+}
+
+/**
+ * This function returns the cache of Info lookups used to validate payouts
+ * @returns The cache
+ */
+func (p *Payouts) InfoCache() *info.Cache {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cache == nil {
+		p.cache = info.NewCache(info.New(p.client), info.WithTTL(MobileMoneyCodesTTL))
+	}
+	return p.cache
+}
+
+func supportsProvider(codes []info.MobileMoneyCode, country, code string) bool {
+	if code == "" {
+		return false
+	}
+	for _, c := range codes {
+		if c.Country != "" && !strings.EqualFold(c.Country, country) {
+			continue
+		}
+		if strings.EqualFold(c.Code, code) {
+			return true
+		}
+	}
+	return false
+}
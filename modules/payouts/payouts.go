@@ -3,6 +3,10 @@ package payouts
 import (
 	"context"
 	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/chimoney/chimoney-go/modules/info"
 )
 
 type Client interface {
@@ -11,6 +15,12 @@ type Client interface {
 
 type Payouts struct {
 	client Client
+
+	mu    sync.Mutex
+	rates map[string]cachedRate
+	cache *info.Cache
+	audit AuditTrail
+	now   func() time.Time
 }
 
 func New(client Client) *Payouts {
@@ -148,35 +158,23 @@ func (p *Payouts) Status(ctx context.Context, chiRef, subAccount string) (*Payou
 	return resp, err
 }
 
-type CryptoPayment struct {
-	XRPL struct {
-		Address  string `json:"address"`
-		Issuer   string `json:"issuer"`
-		Currency string `json:"currency"`
-	} `json:"xrpl,omitempty"`
-}
-
 /**
  * This function initiates a Chimoney payout with optional crypto payments
  * @param {ChimoneyPayload[]} chimoneys Array of Chimoney payouts
  * @param {boolean} turnOffNotification Whether to turn off notifications
- * @param {CryptoPayment[]} cryptoPayments Optional array of crypto payment details
+ * @param {CryptoPayment[]} cryptoPayments Optional array of crypto payment details, validated offline before sending
  * @param {string?} subAccount The subAccount for the transaction
  * @returns The response from the Chimoney API
  */
 func (p *Payouts) InitiateChimoney(ctx context.Context, chimoneys []ChimoneyPayload, turnOffNotification bool, cryptoPayments []CryptoPayment, subAccount string) (*PayoutResponse, error) {
-	req := map[string]interface{}{
-		"chimoneys":          chimoneys,
-		"turnOffNotification": turnOffNotification,
-	}
-	if len(cryptoPayments) > 0 {
-		req["crypto_payments"] = cryptoPayments
+	resp, err := p.Initiate(ctx, InitiateOptions{
+		Chimoneys:           chimoneys,
+		TurnOffNotification: turnOffNotification,
+		CryptoPayments:      cryptoPayments,
+		SubAccount:          subAccount,
+	})
+	if resp == nil {
+		return new(PayoutResponse), err
 	}
-	if subAccount != "" {
-		req["subAccount"] = subAccount
-	}
-
-	resp := new(PayoutResponse)
-	err := p.client.Do(ctx, "POST", "/payouts/initiate", req, resp, nil)
-	return resp, err
+	return &resp.PayoutResponse, err
 }
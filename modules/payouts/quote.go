@@ -0,0 +1,206 @@
+package payouts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/chimoney/chimoney-go/modules/info"
+)
+
+var (
+	ErrEmptyQuote   = errors.New("no items to quote")
+	ErrQuoteExpired = errors.New("quote has expired")
+	ErrRateDrift    = errors.New("exchange rate drifted since quote")
+)
+
+// QuoteTTL is how long a quote, and the rates cached to build it, stay valid.
+const QuoteTTL = 5 * time.Minute
+
+type Denominations struct {
+	Fixed []float64 `json:"fixed,omitempty"`
+	Min   float64   `json:"min,omitempty"`
+	Max   float64   `json:"max,omitempty"`
+}
+
+type QuoteItem struct {
+	Reference     string         `json:"reference,omitempty"`
+	Currency      string         `json:"currency"`
+	ValueInUSD    float64        `json:"valueInUSD"`
+	Denominations *Denominations `json:"denominations,omitempty"`
+}
+
+type QuoteLine struct {
+	QuoteItem
+	LocalValue   float64 `json:"localValue"`
+	Rate         float64 `json:"rate"`
+	OutOfRange   bool    `json:"outOfRange"`
+	RangeMessage string  `json:"rangeMessage,omitempty"`
+}
+
+type Quote struct {
+	Lines       []QuoteLine        `json:"lines"`
+	TotalUSD    float64            `json:"totalUSD"`
+	LocalTotals map[string]float64 `json:"localTotals"`
+	Rates       map[string]float64 `json:"rates"`
+	OutOfRange  int                `json:"outOfRange"`
+	CreatedAt   time.Time          `json:"createdAt"`
+	ExpiresAt   time.Time          `json:"expiresAt"`
+}
+
+type cachedRate struct {
+	rate    float64
+	fetched time.Time
+}
+
+/**
+ * This function previews what each recipient receives in local currency
+ * @param {QuoteItem[]} items The payouts to quote
+ * @returns The per-item and aggregate preview, valid until ExpiresAt
+ */
+func (p *Payouts) Quote(ctx context.Context, items []QuoteItem) (*Quote, error) {
+	if len(items) == 0 {
+		return nil, ErrEmptyQuote
+	}
+
+	now := p.clock()()
+	q := &Quote{
+		LocalTotals: make(map[string]float64),
+		Rates:       make(map[string]float64),
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(QuoteTTL),
+	}
+
+	for _, item := range items {
+		currency := strings.ToUpper(item.Currency)
+		if currency == "" || item.ValueInUSD <= 0 {
+			return nil, fmt.Errorf("payouts: invalid quote item %q", item.Reference)
+		}
+
+		rate, ok := q.Rates[currency]
+		if !ok {
+			var err error
+			if rate, err = p.rate(ctx, currency, false); err != nil {
+				return nil, err
+			}
+			q.Rates[currency] = rate
+		}
+
+		line := QuoteLine{
+			QuoteItem:  item,
+			Rate:       rate,
+			LocalValue: round2(item.ValueInUSD * rate),
+		}
+		line.Currency = currency
+		if item.Denominations != nil {
+			line.RangeMessage = item.Denominations.check(line.LocalValue)
+			line.OutOfRange = line.RangeMessage != ""
+		}
+		if line.OutOfRange {
+			q.OutOfRange++
+		}
+
+		q.Lines = append(q.Lines, line)
+		q.TotalUSD += item.ValueInUSD
+		q.LocalTotals[currency] = round2(q.LocalTotals[currency] + line.LocalValue)
+	}
+	q.TotalUSD = round2(q.TotalUSD)
+
+	return q, nil
+}
+
+/**
+ * This function checks a quote before submitting the payouts it describes
+ * @param {Quote} q The quote to check
+ * @param {number} tolerance The accepted relative rate change, e.g. 0.01 for 1%
+ * @returns ErrQuoteExpired, or ErrRateDrift if a fresh rate moved beyond the tolerance
+ */
+func (p *Payouts) CheckQuote(ctx context.Context, q *Quote, tolerance float64) error {
+	if p.clock()().After(q.ExpiresAt) {
+		return ErrQuoteExpired
+	}
+
+	for currency, quoted := range q.Rates {
+		current, err := p.rate(ctx, currency, true)
+		if err != nil {
+			return err
+		}
+		if quoted > 0 && math.Abs(current-quoted)/quoted > tolerance {
+			return fmt.Errorf("%w: %s moved from %v to %v", ErrRateDrift, currency, quoted, current)
+		}
+	}
+	return nil
+}
+
+/**
+ * This function sets the clock quotes and their rates expire by, also used to time cancellations
+ * @param {func} now The clock, time.Now by default
+ */
+func (p *Payouts) SetClock(now func() time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.now = now
+}
+
+func (p *Payouts) clock() func() time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.now == nil {
+		return time.Now
+	}
+	return p.now
+}
+
+// rate returns the local amount of one USD. The lock is not held while the
+// rate is fetched, so a slow lookup does not stall other calls.
+func (p *Payouts) rate(ctx context.Context, currency string, fresh bool) (float64, error) {
+	now := p.clock()
+	p.mu.Lock()
+	c, ok := p.rates[currency]
+	p.mu.Unlock()
+	if ok && !fresh && now().Sub(c.fetched) < QuoteTTL {
+		return c.rate, nil
+	}
+
+	resp, err := info.New(p.client).GetUSDInLocalAmount(ctx, currency, 1)
+	if err != nil {
+		return 0, err
+	}
+	rate, err := resp.LocalAmount()
+	if err != nil {
+		return 0, fmt.Errorf("payouts: rate for %s: %w", currency, err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.rates == nil {
+		p.rates = make(map[string]cachedRate)
+	}
+	p.rates[currency] = cachedRate{rate: rate, fetched: now()}
+	return rate, nil
+}
+
+func (d *Denominations) check(value float64) string {
+	if len(d.Fixed) > 0 {
+		for _, f := range d.Fixed {
+			if math.Abs(f-value) < 0.005 {
+				return ""
+			}
+		}
+		return fmt.Sprintf("%.2f is not one of the fixed denominations %v", value, d.Fixed)
+	}
+	if d.Min > 0 && value < d.Min {
+		return fmt.Sprintf("%.2f is below the minimum of %.2f", value, d.Min)
+	}
+	if d.Max > 0 && value > d.Max {
+		return fmt.Sprintf("%.2f is above the maximum of %.2f", value, d.Max)
+	}
+	return ""
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,82 @@
+package payouts
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type PayoutItemResult struct {
+	ID         string                 `json:"id,omitempty"`
+	ChiRef     string                 `json:"chiRef,omitempty"`
+	IssueID    string                 `json:"issueID,omitempty"`
+	ValueInUSD float64                `json:"valueInUSD,omitempty"`
+	Status     string                 `json:"status,omitempty"`
+	RedeemLink string                 `json:"redeemLink,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+	Meta       map[string]interface{} `json:"meta,omitempty"`
+	Raw        json.RawMessage        `json:"-"`
+}
+
+type PayoutResult struct {
+	Chimoneys   []PayoutItemResult `json:"chimoneys"`
+	PaymentLink string             `json:"paymentLink,omitempty"`
+	IssueID     string             `json:"issueID,omitempty"`
+	Error       string             `json:"error,omitempty"`
+}
+
+/**
+ * This function decodes the data of a payout response
+ * @returns The per-item results along with the payment link and issue ID, if any
+ */
+func (r *PayoutResponse) Result() (*PayoutResult, error) {
+	result := new(PayoutResult)
+	if len(r.Data) == 0 || string(r.Data) == "null" {
+		return result, nil
+	}
+
+	var data struct {
+		Chimoneys   []json.RawMessage `json:"chimoneys"`
+		Data        []json.RawMessage `json:"data"`
+		PaymentLink string            `json:"paymentLink"`
+		IssueID     string            `json:"issueID"`
+		Error       interface{}       `json:"error"`
+	}
+	if err := json.Unmarshal(r.Data, &data); err != nil {
+		return nil, fmt.Errorf("payouts: unexpected response data: %v", err)
+	}
+
+	result.PaymentLink = data.PaymentLink
+	result.IssueID = data.IssueID
+	if s, ok := data.Error.(string); ok && s != "None" {
+		result.Error = s
+	}
+
+	items := data.Chimoneys
+	if items == nil {
+		items = data.Data
+	}
+	for _, raw := range items {
+		var item PayoutItemResult
+		if err := json.Unmarshal(raw, &item); err != nil {
+			return nil, fmt.Errorf("payouts: unexpected payout item: %v", err)
+		}
+		item.Raw = raw
+		if item.IssueID == "" {
+			item.IssueID = result.IssueID
+		}
+		result.Chimoneys = append(result.Chimoneys, item)
+	}
+
+	return result, nil
+}
+
+// sentResult decodes the result of a payout that was already sent. Data in an
+// unexpected shape gives an empty result rather than an error, which would
+// fail a call the caller may then retry.
+func (r *PayoutResponse) sentResult() *PayoutResult {
+	result, err := r.Result()
+	if err != nil {
+		return new(PayoutResult)
+	}
+	return result
+}
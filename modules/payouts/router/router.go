@@ -0,0 +1,298 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chimoney/chimoney-go/modules/info"
+	"github.com/chimoney/chimoney-go/modules/payouts"
+)
+
+var (
+	ErrNoRoute       = errors.New("no supported payout rail for recipient")
+	ErrInvalidAmount = errors.New("invalid amount")
+)
+
+type Rail string
+
+const (
+	RailBank        Rail = "bank"
+	RailMobileMoney Rail = "mobile_money"
+	RailAirtime     Rail = "airtime"
+	RailChimoney    Rail = "chimoney"
+)
+
+// Recipient describes a payee independently of the rail used to pay them.
+// Only the fields needed by a rail have to be set for that rail to be eligible.
+type Recipient struct {
+	Email           string
+	Twitter         string
+	PhoneNumber     string
+	Country         string
+	BankCode        string
+	AccountNumber   string
+	MobileMoneyCode string
+	Reference       string
+}
+
+// Preferences orders rails per country. Countries without an entry use Default.
+type Preferences struct {
+	Default   []Rail
+	Countries map[string][]Rail
+}
+
+var DefaultPreferences = Preferences{
+	Default: []Rail{RailBank, RailMobileMoney, RailAirtime, RailChimoney},
+	Countries: map[string][]Rail{
+		"GH": {RailMobileMoney, RailBank, RailAirtime, RailChimoney},
+		"KE": {RailMobileMoney, RailBank, RailAirtime, RailChimoney},
+		"UG": {RailMobileMoney, RailBank, RailAirtime, RailChimoney},
+		"NG": {RailBank, RailAirtime, RailChimoney},
+	},
+}
+
+type Attempt struct {
+	Rail     Rail   `json:"rail"`
+	Eligible bool   `json:"eligible"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+type Decision struct {
+	Rail     Rail      `json:"rail"`
+	Attempts []Attempt `json:"attempts"`
+}
+
+type Result struct {
+	Decision
+	Response *payouts.PayoutResponse `json:"response"`
+}
+
+type dispatchFunc func(ctx context.Context, p *payouts.Payouts, r Recipient, amountUSD float64, subAccount string) (*payouts.PayoutResponse, error)
+
+type Router struct {
+	cache    *info.Cache
+	payouts  *payouts.Payouts
+	prefs    Preferences
+	ttl      time.Duration
+	now      func() time.Time
+	dispatch map[Rail]dispatchFunc
+}
+
+type Option func(*Router)
+
+func New(i *info.Info, p *payouts.Payouts, options ...Option) *Router {
+	r := &Router{
+		payouts: p,
+		prefs:   DefaultPreferences,
+		ttl:     time.Hour,
+		now:     time.Now,
+		dispatch: map[Rail]dispatchFunc{
+			RailBank:        sendBank,
+			RailMobileMoney: sendMobileMoney,
+			RailAirtime:     sendAirtime,
+			RailChimoney:    sendChimoney,
+		},
+	}
+
+	for _, opt := range options {
+		opt(r)
+	}
+	if r.cache == nil {
+		r.cache = info.NewCache(i, info.WithTTL(r.ttl), info.WithClock(r.now))
+	}
+
+	return r
+}
+
+func WithPreferences(prefs Preferences) Option {
+	return func(r *Router) {
+		r.prefs = prefs
+	}
+}
+
+// WithInfoCache shares the cache of banks, mobile money codes and airtime
+// countries with other modules. WithCacheTTL and WithClock then do not apply.
+func WithInfoCache(c *info.Cache) Option {
+	return func(r *Router) {
+		r.cache = c
+	}
+}
+
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(r *Router) {
+		r.ttl = ttl
+	}
+}
+
+func WithClock(now func() time.Time) Option {
+	return func(r *Router) {
+		r.now = now
+	}
+}
+
+/**
+ * This function picks the preferred rail the recipient can be paid through.
+ * A rail whose capability data cannot be fetched is skipped, with the error as its reason.
+ * @param {Recipient} recipient The rail-agnostic recipient
+ * @param {number} amountUSD The amount to pay in USD
+ * @returns The chosen rail and every rail that was considered, in order
+ */
+func (r *Router) Route(ctx context.Context, recipient Recipient, amountUSD float64) (*Decision, error) {
+	if amountUSD <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	decision := &Decision{}
+	for _, rail := range r.railsFor(recipient.Country) {
+		reason := r.check(ctx, rail, recipient)
+		attempt := Attempt{Rail: rail, Eligible: reason == "", Reason: reason}
+		decision.Attempts = append(decision.Attempts, attempt)
+		if attempt.Eligible {
+			decision.Rail = rail
+			return decision, nil
+		}
+	}
+
+	return decision, ErrNoRoute
+}
+
+/**
+ * This function routes a payout and dispatches it through the matching Payouts method.
+ * Rails are only skipped for capability reasons; a failed API call is returned as is
+ * rather than retried on another rail, so a recipient is never paid twice.
+ * @param {Recipient} recipient The rail-agnostic recipient
+ * @param {number} amountUSD The amount to pay in USD
+ * @param {string?} subAccount The subAccount for the transaction
+ * @returns The routing decision and the response from the Chimoney API
+ */
+func (r *Router) Send(ctx context.Context, recipient Recipient, amountUSD float64, subAccount string) (*Result, error) {
+	decision, err := r.Route(ctx, recipient, amountUSD)
+	result := &Result{}
+	if decision != nil {
+		result.Decision = *decision
+	}
+	if err != nil {
+		return result, err
+	}
+
+	resp, err := r.dispatch[decision.Rail](ctx, r.payouts, recipient, amountUSD, subAccount)
+	result.Response = resp
+	return result, err
+}
+
+func (r *Router) railsFor(country string) []Rail {
+	if rails, ok := r.prefs.Countries[strings.ToUpper(country)]; ok {
+		return rails
+	}
+	return r.prefs.Default
+}
+
+func (r *Router) check(ctx context.Context, rail Rail, recipient Recipient) string {
+	if _, ok := r.dispatch[rail]; !ok {
+		return "rail is not supported by the payouts module"
+	}
+
+	switch rail {
+	case RailBank:
+		if recipient.BankCode == "" || recipient.AccountNumber == "" {
+			return "recipient has no bank account"
+		}
+		banks, err := r.cache.Banks(ctx, recipient.Country)
+		if err != nil {
+			return fmt.Sprintf("could not list banks in %s: %v", recipient.Country, err)
+		}
+		for _, b := range banks {
+			if strings.EqualFold(b.Code, recipient.BankCode) {
+				return ""
+			}
+		}
+		return fmt.Sprintf("bank %s is not supported in %s", recipient.BankCode, recipient.Country)
+
+	case RailMobileMoney:
+		if recipient.PhoneNumber == "" {
+			return "recipient has no phone number"
+		}
+		if recipient.MobileMoneyCode == "" {
+			return "recipient has no mobile money provider"
+		}
+		codes, err := r.cache.MobileMoneyCodes(ctx)
+		if err != nil {
+			return fmt.Sprintf("could not list mobile money providers: %v", err)
+		}
+		for _, c := range codes {
+			if c.Country != "" && !strings.EqualFold(c.Country, recipient.Country) {
+				continue
+			}
+			if strings.EqualFold(c.Code, recipient.MobileMoneyCode) {
+				return ""
+			}
+		}
+		return fmt.Sprintf("no matching mobile money provider in %s", recipient.Country)
+
+	case RailAirtime:
+		if recipient.PhoneNumber == "" {
+			return "recipient has no phone number"
+		}
+		countries, err := r.cache.AirtimeCountries(ctx)
+		if err != nil {
+			return fmt.Sprintf("could not list airtime countries: %v", err)
+		}
+		for _, c := range countries {
+			if strings.EqualFold(c, recipient.Country) {
+				return ""
+			}
+		}
+		return fmt.Sprintf("airtime is not supported in %s", recipient.Country)
+
+	case RailChimoney:
+		if recipient.Email == "" && recipient.Twitter == "" {
+			return "recipient has no email or twitter handle"
+		}
+		return ""
+	}
+
+	return "unknown rail"
+}
+
+func sendBank(ctx context.Context, p *payouts.Payouts, r Recipient, amountUSD float64, subAccount string) (*payouts.PayoutResponse, error) {
+	return p.Bank(ctx, []payouts.BankPayload{{
+		CountryToSend: r.Country,
+		AccountBank:   r.BankCode,
+		AccountNumber: r.AccountNumber,
+		ValueInUSD:    amountUSD,
+		Reference:     r.Reference,
+	}}, subAccount)
+}
+
+func sendMobileMoney(ctx context.Context, p *payouts.Payouts, r Recipient, amountUSD float64, subAccount string) (*payouts.PayoutResponse, error) {
+	resp, err := p.MobileMoney(ctx, []payouts.MobileMoneyPayload{{
+		CountryToSend: r.Country,
+		PhoneNumber:   r.PhoneNumber,
+		MomoCode:      r.MobileMoneyCode,
+		ValueInUSD:    amountUSD,
+		Reference:     r.Reference,
+	}}, subAccount)
+	if resp == nil {
+		return nil, err
+	}
+	return &resp.PayoutResponse, err
+}
+
+func sendAirtime(ctx context.Context, p *payouts.Payouts, r Recipient, amountUSD float64, subAccount string) (*payouts.PayoutResponse, error) {
+	return p.Airtime(ctx, []payouts.AirtimePayload{{
+		CountryToSend: r.Country,
+		PhoneNumber:   r.PhoneNumber,
+		ValueInUSD:    amountUSD,
+	}}, subAccount)
+}
+
+func sendChimoney(ctx context.Context, p *payouts.Payouts, r Recipient, amountUSD float64, subAccount string) (*payouts.PayoutResponse, error) {
+	return p.Chimoney(ctx, []payouts.ChimoneyPayload{{
+		ValueInUSD: amountUSD,
+		Email:      r.Email,
+		Twitter:    r.Twitter,
+	}}, subAccount)
+}
@@ -0,0 +1,400 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/chimoney/chimoney-go/internal/apierr"
+	"github.com/chimoney/chimoney-go/modules/info"
+	"github.com/chimoney/chimoney-go/modules/payouts"
+)
+
+var (
+	ErrInvalidPlan   = errors.New("invalid payout plan")
+	ErrDuplicatePlan = errors.New("payout plan already exists")
+)
+
+// maxCatchUp bounds how many missed runs of one plan are considered per tick.
+// CatchUpAll works through the oldest first; the other policies keep the most
+// recent, so the latest missed run is always among them.
+const maxCatchUp = 1000
+
+type MissedRunPolicy int
+
+const (
+	// CatchUpAll executes every missed run, oldest first.
+	CatchUpAll MissedRunPolicy = iota
+	// CatchUpLatest executes only the most recent missed run and skips the rest.
+	CatchUpLatest
+	// SkipMissed only executes runs that are due within the grace period.
+	SkipMissed
+)
+
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// Entry is one recipient of a plan. Exactly one payload must be set. When
+// LocalAmount is set, the payload's ValueInUSD is computed from it on every run.
+type Entry struct {
+	Bank        *payouts.BankPayload
+	Airtime     *payouts.AirtimePayload
+	Chimoney    *payouts.ChimoneyPayload
+	LocalAmount float64
+	Currency    string
+}
+
+type Plan struct {
+	ID         string
+	Schedule   string
+	TimeZone   string
+	Entries    []Entry
+	SubAccount string
+	MissedRuns MissedRunPolicy
+	// StartAt is the earliest time a run may be scheduled. Without it, a plan
+	// resumes after its last run in the store, or starts when it is added.
+	StartAt time.Time
+}
+
+type Run struct {
+	PlanID      string
+	ScheduledAt time.Time
+	Skipped     bool
+	// Retry is set when the run failed before anything was paid. It is
+	// attempted again on a later tick.
+	Retry    bool
+	Airtime  *payouts.PayoutResponse
+	Bank     *payouts.PayoutResponse
+	Chimoney *payouts.PayoutResponse
+	Err      error
+}
+
+type plan struct {
+	Plan
+	spec  *Spec
+	loc   *time.Location
+	added time.Time
+}
+
+type Scheduler struct {
+	payouts *payouts.Payouts
+	info    *info.Info
+	store   Store
+	clock   Clock
+	grace   time.Duration
+
+	mu    sync.Mutex
+	plans map[string]*plan
+}
+
+type Option func(*Scheduler)
+
+func New(p *payouts.Payouts, i *info.Info, options ...Option) *Scheduler {
+	s := &Scheduler{
+		payouts: p,
+		info:    i,
+		store:   NewMemoryStore(),
+		clock:   systemClock{},
+		grace:   5 * time.Minute,
+		plans:   make(map[string]*plan),
+	}
+
+	for _, opt := range options {
+		opt(s)
+	}
+
+	return s
+}
+
+func WithStore(store Store) Option {
+	return func(s *Scheduler) {
+		s.store = store
+	}
+}
+
+func WithClock(clock Clock) Option {
+	return func(s *Scheduler) {
+		s.clock = clock
+	}
+}
+
+func WithGracePeriod(grace time.Duration) Option {
+	return func(s *Scheduler) {
+		s.grace = grace
+	}
+}
+
+/**
+ * This function registers a recurring payout plan
+ * @param {Plan} p The plan to run
+ * @returns ErrInvalidPlan if the schedule, time zone or entries are invalid
+ */
+func (s *Scheduler) Add(p Plan) error {
+	if p.ID == "" || len(p.Entries) == 0 {
+		return fmt.Errorf("%w: a plan needs an ID and at least one entry", ErrInvalidPlan)
+	}
+	for i, e := range p.Entries {
+		if n := e.payloads(); n != 1 {
+			return fmt.Errorf("%w: entry %d must set exactly one payload, has %d", ErrInvalidPlan, i, n)
+		}
+		if e.LocalAmount > 0 && e.Currency == "" {
+			return fmt.Errorf("%w: entry %d has a local amount without a currency", ErrInvalidPlan, i)
+		}
+	}
+
+	spec, err := Parse(p.Schedule)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPlan, err)
+	}
+	loc, err := time.LoadLocation(p.TimeZone)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPlan, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.plans[p.ID]; ok {
+		return ErrDuplicatePlan
+	}
+	s.plans[p.ID] = &plan{Plan: p, spec: spec, loc: loc, added: s.clock.Now()}
+	return nil
+}
+
+/**
+ * This function unregisters a payout plan
+ * @param {string} id The plan ID
+ */
+func (s *Scheduler) Remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.plans, id)
+}
+
+/**
+ * This function executes every plan run that is due at the clock's current time
+ * @returns The runs that were executed or skipped, ordered by scheduled time
+ */
+func (s *Scheduler) Tick(ctx context.Context) ([]Run, error) {
+	s.mu.Lock()
+	plans := make([]*plan, 0, len(s.plans))
+	for _, p := range s.plans {
+		plans = append(plans, p)
+	}
+	s.mu.Unlock()
+	sort.Slice(plans, func(i, j int) bool { return plans[i].ID < plans[j].ID })
+
+	now := s.clock.Now()
+	var runs []Run
+	for _, p := range plans {
+		due, err := s.due(p, now)
+		if err != nil {
+			return runs, err
+		}
+
+		for i, t := range due {
+			skip := false
+			switch p.MissedRuns {
+			case CatchUpLatest:
+				skip = i < len(due)-1
+			case SkipMissed:
+				skip = now.Sub(t) > s.grace
+			}
+
+			claimed, err := s.store.Claim(p.ID, t)
+			if err != nil {
+				return runs, err
+			}
+			if !claimed {
+				continue
+			}
+
+			run := Run{PlanID: p.ID, ScheduledAt: t, Skipped: skip}
+			if !skip {
+				run.Retry = s.execute(ctx, p, &run)
+			}
+			if run.Retry {
+				if err := s.store.Fail(p.ID, t); err != nil {
+					return runs, err
+				}
+			}
+			runs = append(runs, run)
+		}
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].ScheduledAt.Before(runs[j].ScheduledAt) })
+	return runs, nil
+}
+
+/**
+ * This function calls Tick on every interval until the context is cancelled
+ * @param {time.Duration} interval How often to check for due runs
+ * @param {function?} onRun Optional callback receiving every executed or skipped run
+ * @returns The context's error, or the first error returned by the store
+ */
+func (s *Scheduler) Run(ctx context.Context, interval time.Duration, onRun func(Run)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		runs, err := s.Tick(ctx)
+		if err != nil {
+			return err
+		}
+		if onRun != nil {
+			for _, r := range runs {
+				onRun(r)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// due resumes after the last run in the store, so runs missed while the
+// process was down are caught up. Only a plan without history starts from
+// StartAt, or from when it was added. Failed runs awaiting a retry come first.
+func (s *Scheduler) due(p *plan, now time.Time) ([]time.Time, error) {
+	last, ok, err := s.store.LastRun(p.ID)
+	if err != nil {
+		return nil, err
+	}
+	failed, err := s.store.Failed(p.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var from time.Time
+	switch {
+	case ok && (p.StartAt.IsZero() || !last.Before(p.StartAt)):
+		from = last
+	case !p.StartAt.IsZero():
+		from = p.StartAt.Add(-time.Nanosecond)
+	default:
+		from = p.added.Add(-time.Nanosecond)
+	}
+
+	var missed []time.Time
+	for t := p.spec.Next(from, p.loc); !t.IsZero() && !t.After(now); t = p.spec.Next(t, p.loc) {
+		if len(missed) == maxCatchUp {
+			if p.MissedRuns == CatchUpAll {
+				break
+			}
+			missed = missed[1:]
+		}
+		missed = append(missed, t)
+	}
+
+	due := append(failed, missed...)
+	sort.Slice(due, func(i, j int) bool { return due[i].Before(due[j]) })
+	return due, nil
+}
+
+// execute reports whether the run failed before anything was paid, in which
+// case it is retried.
+func (s *Scheduler) execute(ctx context.Context, p *plan, run *Run) bool {
+	var airtime []payouts.AirtimePayload
+	var banks []payouts.BankPayload
+	var chimoneys []payouts.ChimoneyPayload
+
+	for _, e := range p.Entries {
+		usd := 0.0
+		if e.LocalAmount > 0 {
+			var err error
+			if usd, err = s.toUSD(ctx, e.Currency, e.LocalAmount); err != nil {
+				run.Err = err
+				return true
+			}
+		}
+
+		switch {
+		case e.Airtime != nil:
+			item := *e.Airtime
+			if usd > 0 {
+				item.ValueInUSD = usd
+			}
+			airtime = append(airtime, item)
+		case e.Bank != nil:
+			item := *e.Bank
+			if usd > 0 {
+				item.ValueInUSD = usd
+			}
+			banks = append(banks, item)
+		case e.Chimoney != nil:
+			item := *e.Chimoney
+			if usd > 0 {
+				item.ValueInUSD = usd
+			}
+			chimoneys = append(chimoneys, item)
+		}
+	}
+
+	sent := false
+	if len(banks) > 0 {
+		if run.Bank, run.Err = s.payouts.Bank(ctx, banks, p.SubAccount); run.Err != nil {
+			return !sent && transient(run.Err)
+		}
+		sent = true
+	}
+	if len(airtime) > 0 {
+		if run.Airtime, run.Err = s.payouts.Airtime(ctx, airtime, p.SubAccount); run.Err != nil {
+			return !sent && transient(run.Err)
+		}
+		sent = true
+	}
+	if len(chimoneys) > 0 {
+		if run.Chimoney, run.Err = s.payouts.Chimoney(ctx, chimoneys, p.SubAccount); run.Err != nil {
+			return !sent && transient(run.Err)
+		}
+	}
+	return false
+}
+
+// transient reports whether a payout request certainly did not go through and
+// may succeed later. Server errors are not retried, as the payout may have been made.
+func transient(err error) bool {
+	return apierr.NotSent(err) || apierr.Status(err) == http.StatusTooManyRequests
+}
+
+func (s *Scheduler) toUSD(ctx context.Context, currency string, amount float64) (float64, error) {
+	resp, err := s.info.GetLocalAmountInUSD(ctx, currency, amount)
+	if err != nil {
+		return 0, err
+	}
+	var data struct {
+		AmountInUSD float64 `json:"amountInUSD"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		return 0, fmt.Errorf("schedule: unexpected conversion response: %v", err)
+	}
+	if data.AmountInUSD <= 0 {
+		return 0, fmt.Errorf("schedule: conversion of %v %s returned no USD amount", amount, currency)
+	}
+	return data.AmountInUSD, nil
+}
+
+func (e Entry) payloads() int {
+	n := 0
+	if e.Bank != nil {
+		n++
+	}
+	if e.Airtime != nil {
+		n++
+	}
+	if e.Chimoney != nil {
+		n++
+	}
+	return n
+}
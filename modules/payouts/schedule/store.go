@@ -0,0 +1,204 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Store remembers which scheduled runs have been claimed so a run is never
+// executed twice, including across restarts and between processes sharing the store.
+type Store interface {
+	// Claim records the run of a plan at a scheduled time. It returns false if
+	// the run was already claimed.
+	Claim(planID string, scheduledAt time.Time) (bool, error)
+	// LastRun returns the latest claimed scheduled time of a plan.
+	LastRun(planID string) (time.Time, bool, error)
+	// Fail releases a claimed run that failed before anything was paid, so it
+	// can be claimed again.
+	Fail(planID string, scheduledAt time.Time) error
+	// Failed returns the failed runs of a plan that have not been claimed again.
+	Failed(planID string) ([]time.Time, error)
+}
+
+// MemoryStore keeps runs in memory: true for a claimed run, false for a failed one.
+type MemoryStore struct {
+	mu   sync.Mutex
+	runs map[string]map[int64]bool
+	last map[string]time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		runs: make(map[string]map[int64]bool),
+		last: make(map[string]time.Time),
+	}
+}
+
+func (s *MemoryStore) Claim(planID string, scheduledAt time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.runs[planID] == nil {
+		s.runs[planID] = make(map[int64]bool)
+	}
+	key := scheduledAt.Unix()
+	if claimed := s.runs[planID][key]; claimed {
+		return false, nil
+	}
+	s.runs[planID][key] = true
+	if scheduledAt.After(s.last[planID]) {
+		s.last[planID] = scheduledAt
+	}
+	return true, nil
+}
+
+func (s *MemoryStore) LastRun(planID string) (time.Time, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.last[planID]
+	return t, ok, nil
+}
+
+func (s *MemoryStore) Fail(planID string, scheduledAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.runs[planID][scheduledAt.Unix()]; ok {
+		s.runs[planID][scheduledAt.Unix()] = false
+	}
+	return nil
+}
+
+func (s *MemoryStore) Failed(planID string) ([]time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var failed []time.Time
+	for key, claimed := range s.runs[planID] {
+		if !claimed {
+			failed = append(failed, time.Unix(key, 0))
+		}
+	}
+	return failed, nil
+}
+
+// FileStore claims runs by exclusively creating one marker file per run in a
+// directory, which is atomic across processes and survives restarts. A failed
+// run's marker is renamed from .run to .failed until it is claimed again.
+type FileStore struct {
+	dir string
+}
+
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) Claim(planID string, scheduledAt time.Time) (bool, error) {
+	planDir, err := s.planDir(planID)
+	if err != nil {
+		return false, err
+	}
+	if err := os.MkdirAll(planDir, 0o700); err != nil {
+		return false, err
+	}
+
+	name := filepath.Join(planDir, strconv.FormatInt(scheduledAt.Unix(), 10))
+	f, err := os.OpenFile(name+".run", os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if errors.Is(err, os.ErrExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	_, err = f.WriteString(time.Now().UTC().Format(time.RFC3339))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if rerr := os.Remove(name + ".failed"); err == nil && !errors.Is(rerr, os.ErrNotExist) {
+		err = rerr
+	}
+	return true, err
+}
+
+func (s *FileStore) Fail(planID string, scheduledAt time.Time) error {
+	planDir, err := s.planDir(planID)
+	if err != nil {
+		return err
+	}
+	name := filepath.Join(planDir, strconv.FormatInt(scheduledAt.Unix(), 10))
+	return os.Rename(name+".run", name+".failed")
+}
+
+func (s *FileStore) Failed(planID string) ([]time.Time, error) {
+	entries, err := s.entries(planID)
+	if err != nil {
+		return nil, err
+	}
+	var failed []time.Time
+	for _, e := range entries {
+		if n, ok := runTime(e.Name(), ".failed"); ok {
+			failed = append(failed, time.Unix(n, 0))
+		}
+	}
+	return failed, nil
+}
+
+func (s *FileStore) LastRun(planID string) (time.Time, bool, error) {
+	entries, err := s.entries(planID)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	var last int64
+	found := false
+	for _, e := range entries {
+		n, ok := runTime(e.Name(), ".run")
+		if !ok {
+			n, ok = runTime(e.Name(), ".failed")
+		}
+		if !ok {
+			continue
+		}
+		if !found || n > last {
+			last, found = n, true
+		}
+	}
+	if !found {
+		return time.Time{}, false, nil
+	}
+	return time.Unix(last, 0), true, nil
+}
+
+func (s *FileStore) entries(planID string) ([]os.DirEntry, error) {
+	planDir, err := s.planDir(planID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(planDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return entries, err
+}
+
+func runTime(name, suffix string) (int64, bool) {
+	if !strings.HasSuffix(name, suffix) {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(strings.TrimSuffix(name, suffix), 10, 64)
+	return n, err == nil
+}
+
+func (s *FileStore) planDir(planID string) (string, error) {
+	if planID == "" || strings.ContainsAny(planID, `/\`) || planID == "." || planID == ".." {
+		return "", fmt.Errorf("schedule: invalid plan ID %q", planID)
+	}
+	return filepath.Join(s.dir, planID), nil
+}
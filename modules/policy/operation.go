@@ -0,0 +1,131 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type Operation struct {
+	Path       string
+	SubAccount string
+	Items      []Item
+}
+
+type Item struct {
+	AmountUSD float64
+	Recipient string
+	Country   string
+	// Unpriced marks an item whose amount is not given in USD, so the amount
+	// limits cannot be checked against it.
+	Unpriced bool
+}
+
+// operationSpec describes where the items of a money-moving request live and
+// which of their fields identify the amount, recipient and country.
+type operationSpec struct {
+	list      string
+	amount    string
+	local     string
+	recipient []string
+	country   []string
+}
+
+// operations lists every request that moves money. Requests on other paths pass through unchecked.
+var operations = map[string]operationSpec{
+	"/payouts/airtime":   {list: "airtime", amount: "valueInUSD", recipient: []string{"phoneNumber"}, country: []string{"countryToSend"}},
+	"/payouts/bank":      {list: "banks", amount: "valueInUSD", recipient: []string{"account_bank", "account_number"}, country: []string{"countryToSend"}},
+	"/payouts/chimoney":  {list: "chimoneys", amount: "valueInUSD", recipient: []string{"email", "twitter"}},
+	"/payouts/gift-card": {list: "giftCards", amount: "valueInUSD", recipient: []string{"email"}, country: []string{"redeemData.countryCode"}},
+	"/payouts/initiate":  {list: "chimoneys", amount: "valueInUSD", recipient: []string{"email", "twitter"}},
+	"/payouts/interledger-wallet-address": {
+		list: "interledgerWallets", amount: "valueInUSD", local: "amountToDeliver", recipient: []string{"interledgerWalletAddress"},
+	},
+	"/payouts/mobile-money": {
+		list: "momos", amount: "valueInUSD", recipient: []string{"phoneNumber"}, country: []string{"countryToSend"},
+	},
+	"/wallets/transfer":  {recipient: []string{"receiver"}},
+	"/accounts/transfer": {recipient: []string{"chiRef"}},
+	"/redeem/airtime":    {recipient: []string{"phoneNumber"}, country: []string{"countryToSend"}},
+	"/redeem/any":        {list: "redeemData", country: []string{"countryCode"}},
+	"/redeem/chimoney":   {list: "chimoneys", amount: "valueInUSD", recipient: []string{"email", "phoneNumber"}, country: []string{"countryToSend"}},
+	"/redeem/gift-card":  {recipient: []string{"chiRef"}, country: []string{"redeemOptions.countryCode"}},
+	"/redeem/mobile-money": {
+		recipient: []string{"redeemOptions.phoneNumber"},
+		country:   []string{"redeemOptions.countryToSend", "redeemOptions.countryCode"},
+	},
+}
+
+/**
+ * This function describes a request as a money-moving operation
+ * @param {string} path The API path of the request
+ * @param {object} body The request body
+ * @returns The operation, or nil if the request does not move money
+ */
+func Extract(path string, body interface{}) (*Operation, error) {
+	spec, ok := operations[path]
+	if !ok {
+		return nil, nil
+	}
+
+	var doc map[string]interface{}
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("policy: unexpected request body for %s: %v", path, err)
+		}
+	}
+
+	op := &Operation{Path: path}
+	op.SubAccount, _ = doc["subAccount"].(string)
+
+	entries := []interface{}{doc}
+	if spec.list != "" {
+		entries, _ = doc[spec.list].([]interface{})
+	}
+	for _, entry := range entries {
+		fields, _ := entry.(map[string]interface{})
+		item := Item{Country: firstString(fields, spec.country)}
+		if spec.amount != "" {
+			item.AmountUSD, _ = fields[spec.amount].(float64)
+		}
+		if local, _ := fields[spec.local].(float64); local > 0 && item.AmountUSD <= 0 {
+			item.Unpriced = true
+		}
+		var parts []string
+		for _, f := range spec.recipient {
+			if s := lookupString(fields, f); s != "" {
+				parts = append(parts, strings.ToLower(strings.TrimSpace(s)))
+			}
+		}
+		item.Recipient = strings.Join(parts, ":")
+		op.Items = append(op.Items, item)
+	}
+
+	return op, nil
+}
+
+func firstString(fields map[string]interface{}, paths []string) string {
+	for _, p := range paths {
+		if s := lookupString(fields, p); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+func lookupString(fields map[string]interface{}, path string) string {
+	var cur interface{} = fields
+	for _, key := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		cur = m[key]
+	}
+	s, _ := cur.(string)
+	return s
+}
@@ -0,0 +1,397 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chimoney/chimoney-go/internal/apierr"
+	"github.com/chimoney/chimoney-go/internal/stream"
+)
+
+var ErrViolation = errors.New("policy violation")
+
+type Client interface {
+	Do(ctx context.Context, method, path string, body interface{}, v interface{}, params map[string]string) error
+}
+
+type Rule string
+
+const (
+	RuleMaxItem          Rule = "max_item_usd"
+	RuleMaxBatch         Rule = "max_batch_usd"
+	RuleDailySubAccount  Rule = "daily_sub_account_usd"
+	RuleDailyRecipient   Rule = "daily_recipient_usd"
+	RuleCountryAllowList Rule = "allowed_countries"
+	RuleCountryDenyList  Rule = "denied_countries"
+	RuleBusinessHours    Rule = "business_hours"
+	RuleUSDAmount        Rule = "usd_amount"
+)
+
+// Violation names the rule an operation broke. It matches ErrViolation with errors.Is.
+type Violation struct {
+	Rule   Rule
+	Path   string
+	Index  int
+	Limit  float64
+	Actual float64
+	Detail string
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("policy: %s violated on %s: %s", v.Rule, v.Path, v.Detail)
+}
+
+func (v *Violation) Unwrap() error {
+	return ErrViolation
+}
+
+// Policy holds the limits enforced before money moves. Zero values disable a rule.
+type Policy struct {
+	MaxItemUSD         float64        `json:"maxItemUSD,omitempty"`
+	MaxBatchUSD        float64        `json:"maxBatchUSD,omitempty"`
+	DailySubAccountUSD float64        `json:"dailySubAccountUSD,omitempty"`
+	DailyRecipientUSD  float64        `json:"dailyRecipientUSD,omitempty"`
+	AllowedCountries   []string       `json:"allowedCountries,omitempty"`
+	DeniedCountries    []string       `json:"deniedCountries,omitempty"`
+	BusinessHours      *BusinessHours `json:"businessHours,omitempty"`
+}
+
+// BusinessHours is a daily window such as 09:00 to 17:00. A window ending
+// before it starts, such as 22:00 to 06:00, runs overnight.
+type BusinessHours struct {
+	TimeZone string   `json:"timeZone"`
+	Days     []string `json:"days,omitempty"`
+	Start    string   `json:"start"`
+	End      string   `json:"end"`
+}
+
+/**
+ * This function loads a policy from a JSON file
+ * @param {string} path The path of the policy file
+ * @returns The decoded policy
+ */
+func LoadFile(path string) (Policy, error) {
+	var p Policy
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return p, err
+	}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return p, fmt.Errorf("policy: invalid policy file %s: %v", path, err)
+	}
+	return p, nil
+}
+
+type spend struct {
+	at         time.Time
+	subAccount string
+	recipient  string
+	amount     float64
+}
+
+type Engine struct {
+	policy Policy
+	now    func() time.Time
+
+	loc        *time.Location
+	days       map[time.Weekday]bool
+	start, end int
+	allowed    map[string]bool
+	denied     map[string]bool
+
+	mu     sync.Mutex
+	spends []*spend
+}
+
+type Option func(*Engine)
+
+func WithClock(now func() time.Time) Option {
+	return func(e *Engine) {
+		e.now = now
+	}
+}
+
+func NewEngine(p Policy, options ...Option) (*Engine, error) {
+	e := &Engine{
+		policy:  p,
+		now:     time.Now,
+		allowed: countrySet(p.AllowedCountries),
+		denied:  countrySet(p.DeniedCountries),
+	}
+
+	if bh := p.BusinessHours; bh != nil {
+		loc, err := time.LoadLocation(bh.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("policy: invalid business hours time zone: %v", err)
+		}
+		e.loc = loc
+		if e.start, err = parseClock(bh.Start); err != nil {
+			return nil, err
+		}
+		if e.end, err = parseClock(bh.End); err != nil {
+			return nil, err
+		}
+		if e.start == e.end {
+			return nil, fmt.Errorf("policy: business hours start and end at %s", bh.Start)
+		}
+		if e.days, err = parseDays(bh.Days); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, opt := range options {
+		opt(e)
+	}
+
+	return e, nil
+}
+
+/**
+ * This function checks an operation against every rule and reserves its amounts
+ * against the daily limits
+ * @param {Operation} op The money-moving operation
+ * @returns A function releasing the reservation if the operation is not carried out, or a *Violation
+ */
+func (e *Engine) Reserve(op *Operation) (func(), error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	if err := e.check(op, now); err != nil {
+		return nil, err
+	}
+
+	added := make([]*spend, 0, len(op.Items))
+	for _, item := range op.Items {
+		if item.AmountUSD <= 0 {
+			continue
+		}
+		s := &spend{at: now, subAccount: op.SubAccount, recipient: item.Recipient, amount: item.AmountUSD}
+		e.spends = append(e.spends, s)
+		added = append(added, s)
+	}
+
+	return func() { e.release(added) }, nil
+}
+
+/**
+ * This function checks an operation against every rule without reserving anything
+ * @param {Operation} op The money-moving operation
+ * @returns A *Violation naming the broken rule
+ */
+func (e *Engine) Check(op *Operation) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.check(op, e.now())
+}
+
+func (e *Engine) check(op *Operation, now time.Time) error {
+	p := e.policy
+
+	if e.loc != nil {
+		local := now.In(e.loc)
+		if !e.open(local) {
+			return &Violation{
+				Rule:   RuleBusinessHours,
+				Path:   op.Path,
+				Index:  -1,
+				Detail: fmt.Sprintf("%s is outside business hours", local.Format("Mon 15:04 MST")),
+			}
+		}
+	}
+
+	e.prune(now)
+	subAccountSpent := 0.0
+	recipientSpent := make(map[string]float64)
+	for _, s := range e.spends {
+		if s.subAccount == op.SubAccount {
+			subAccountSpent += s.amount
+		}
+		recipientSpent[s.recipient] += s.amount
+	}
+
+	total := 0.0
+	for i, item := range op.Items {
+		country := strings.ToUpper(item.Country)
+		if country != "" && len(e.allowed) > 0 && !e.allowed[country] {
+			return &Violation{Rule: RuleCountryAllowList, Path: op.Path, Index: i, Detail: fmt.Sprintf("country %s is not allowed", country)}
+		}
+		if country != "" && e.denied[country] {
+			return &Violation{Rule: RuleCountryDenyList, Path: op.Path, Index: i, Detail: fmt.Sprintf("country %s is denied", country)}
+		}
+		if item.Unpriced && e.limitsAmounts() {
+			return &Violation{Rule: RuleUSDAmount, Path: op.Path, Index: i, Detail: "amount is not in USD and cannot be checked against the limits"}
+		}
+		if p.MaxItemUSD > 0 && item.AmountUSD > p.MaxItemUSD {
+			return &Violation{
+				Rule: RuleMaxItem, Path: op.Path, Index: i, Limit: p.MaxItemUSD, Actual: item.AmountUSD,
+				Detail: fmt.Sprintf("item of $%.2f exceeds $%.2f", item.AmountUSD, p.MaxItemUSD),
+			}
+		}
+
+		total += item.AmountUSD
+		if item.Recipient != "" {
+			recipientSpent[item.Recipient] += item.AmountUSD
+			if spent := recipientSpent[item.Recipient]; p.DailyRecipientUSD > 0 && spent > p.DailyRecipientUSD {
+				return &Violation{
+					Rule: RuleDailyRecipient, Path: op.Path, Index: i, Limit: p.DailyRecipientUSD, Actual: spent,
+					Detail: fmt.Sprintf("recipient %s would receive $%.2f in 24h, limit $%.2f", item.Recipient, spent, p.DailyRecipientUSD),
+				}
+			}
+		}
+	}
+
+	if p.MaxBatchUSD > 0 && total > p.MaxBatchUSD {
+		return &Violation{
+			Rule: RuleMaxBatch, Path: op.Path, Index: -1, Limit: p.MaxBatchUSD, Actual: total,
+			Detail: fmt.Sprintf("batch of $%.2f exceeds $%.2f", total, p.MaxBatchUSD),
+		}
+	}
+	if spent := subAccountSpent + total; p.DailySubAccountUSD > 0 && spent > p.DailySubAccountUSD {
+		return &Violation{
+			Rule: RuleDailySubAccount, Path: op.Path, Index: -1, Limit: p.DailySubAccountUSD, Actual: spent,
+			Detail: fmt.Sprintf("sub-account %q would send $%.2f in 24h, limit $%.2f", op.SubAccount, spent, p.DailySubAccountUSD),
+		}
+	}
+
+	return nil
+}
+
+// open reports whether a local time is within business hours. Hours ending
+// before they start run overnight, and count as the day they started on.
+func (e *Engine) open(local time.Time) bool {
+	minute := local.Hour()*60 + local.Minute()
+	if e.start < e.end {
+		return e.days[local.Weekday()] && minute >= e.start && minute < e.end
+	}
+	switch {
+	case minute >= e.start:
+		return e.days[local.Weekday()]
+	case minute < e.end:
+		return e.days[local.AddDate(0, 0, -1).Weekday()]
+	}
+	return false
+}
+
+func (e *Engine) limitsAmounts() bool {
+	p := e.policy
+	return p.MaxItemUSD > 0 || p.MaxBatchUSD > 0 || p.DailySubAccountUSD > 0 || p.DailyRecipientUSD > 0
+}
+
+func (e *Engine) prune(now time.Time) {
+	cutoff := now.Add(-24 * time.Hour)
+	kept := e.spends[:0]
+	for _, s := range e.spends {
+		if s.at.After(cutoff) {
+			kept = append(kept, s)
+		}
+	}
+	e.spends = kept
+}
+
+func (e *Engine) release(added []*spend) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	drop := make(map[*spend]bool, len(added))
+	for _, s := range added {
+		drop[s] = true
+	}
+	kept := e.spends[:0]
+	for _, s := range e.spends {
+		if !drop[s] {
+			kept = append(kept, s)
+		}
+	}
+	e.spends = kept
+}
+
+// Enforcer is a Client that runs every money-moving request past an Engine first.
+type Enforcer struct {
+	next   Client
+	engine *Engine
+}
+
+func Wrap(next Client, engine *Engine) *Enforcer {
+	return &Enforcer{next: next, engine: engine}
+}
+
+func (f *Enforcer) Do(ctx context.Context, method, path string, body interface{}, v interface{}, params map[string]string) error {
+	op, err := Extract(path, body)
+	if err != nil {
+		return err
+	}
+	if op == nil {
+		return f.next.Do(ctx, method, path, body, v, params)
+	}
+
+	release, err := f.engine.Reserve(op)
+	if err != nil {
+		return err
+	}
+	if err := f.next.Do(ctx, method, path, body, v, params); err != nil {
+		// A timeout or transport error may come after the money moved, so the
+		// amounts stay reserved unless the request was refused or never sent.
+		if apierr.Rejected(err) || apierr.NotSent(err) {
+			release()
+		}
+		return err
+	}
+	return nil
+}
+
+// Stream passes requests that move no money to the wrapped client, unread if
+// it can stream. Money-moving requests are checked through Do first.
+func (f *Enforcer) Stream(ctx context.Context, method, path string, body interface{}, params map[string]string) (io.ReadCloser, error) {
+	op, err := Extract(path, body)
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := f.next.(stream.Streamer); ok && op == nil {
+		return s.Stream(ctx, method, path, body, params)
+	}
+	return stream.Buffered(ctx, f.Do, method, path, body, params)
+}
+
+func countrySet(countries []string) map[string]bool {
+	set := make(map[string]bool, len(countries))
+	for _, c := range countries {
+		set[strings.ToUpper(c)] = true
+	}
+	return set
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("policy: invalid business hours time %q", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func parseDays(days []string) (map[time.Weekday]bool, error) {
+	if len(days) == 0 {
+		days = []string{"Mon", "Tue", "Wed", "Thu", "Fri"}
+	}
+	set := make(map[time.Weekday]bool, len(days))
+	for _, d := range days {
+		found := false
+		for w := time.Sunday; w <= time.Saturday; w++ {
+			if strings.EqualFold(d, w.String()) || strings.EqualFold(d, w.String()[:3]) {
+				set[w] = true
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("policy: invalid business day %q", d)
+		}
+	}
+	return set, nil
+}
@@ -0,0 +1,155 @@
+package redeem
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// The json keys of the typed options below are the field names of the request
+// bodies of the Chimoney API redeem endpoints, spelled as the payouts module
+// spells the same fields (phoneNumber, countryCode, productId). Fields the API
+// adds later go through Extra until a typed field exists for them.
+
+// Extra holds fields the typed options do not know about yet. They are sent
+// as is, but may not repeat a typed field.
+type Extra map[string]interface{}
+
+// GiftCardOptions are the redeemOptions of a gift card redemption. ProductID,
+// CountryCode and Amount are required.
+type GiftCardOptions struct {
+	ProductID   string  `json:"productId"`
+	CountryCode string  `json:"countryCode"`
+	Amount      float64 `json:"amount"`
+	Email       string  `json:"email,omitempty"`
+	Extra       Extra   `json:"-"`
+}
+
+func (o *GiftCardOptions) Validate() error {
+	switch {
+	case o.ProductID == "":
+		return fmt.Errorf("%w: redeemOptions.productId is required", ErrInvalidRedeemData)
+	case o.CountryCode == "":
+		return fmt.Errorf("%w: redeemOptions.countryCode is required", ErrInvalidRedeemData)
+	case o.Amount <= 0:
+		return fmt.Errorf("%w: redeemOptions.amount must be positive", ErrInvalidRedeemData)
+	case o.Email != "" && !strings.Contains(o.Email, "@"):
+		return fmt.Errorf("%w: redeemOptions.email %q is invalid", ErrInvalidRedeemData, o.Email)
+	}
+	return nil
+}
+
+// MobileMoneyOptions are the redeemOptions of a mobile money redemption.
+// PhoneNumber, CountryCode and Provider are required; Amount defaults to the
+// value of the Chimoney.
+type MobileMoneyOptions struct {
+	PhoneNumber string  `json:"phoneNumber"`
+	CountryCode string  `json:"countryCode"`
+	Provider    string  `json:"provider"`
+	Amount      float64 `json:"amount,omitempty"`
+	Extra       Extra   `json:"-"`
+}
+
+func (o *MobileMoneyOptions) Validate() error {
+	switch {
+	case o.PhoneNumber == "":
+		return fmt.Errorf("%w: redeemOptions.phoneNumber is required", ErrInvalidRedeemData)
+	case o.CountryCode == "":
+		return fmt.Errorf("%w: redeemOptions.countryCode is required", ErrInvalidRedeemData)
+	case o.Provider == "":
+		return fmt.Errorf("%w: redeemOptions.provider is required", ErrInvalidRedeemData)
+	case o.Amount < 0:
+		return fmt.Errorf("%w: redeemOptions.amount must not be negative", ErrInvalidRedeemData)
+	}
+	return nil
+}
+
+// AirtimeMeta is the meta of an airtime redemption. No field is required.
+type AirtimeMeta struct {
+	Note      string `json:"note,omitempty"`
+	Reference string `json:"reference,omitempty"`
+	Extra     Extra  `json:"-"`
+}
+
+func (o *AirtimeMeta) Validate() error {
+	return nil
+}
+
+// ChimoneyItem is one Chimoney of a Chimoney redemption. ID is required.
+type ChimoneyItem struct {
+	ID          string  `json:"id"`
+	Amount      float64 `json:"amount,omitempty"`
+	Email       string  `json:"email,omitempty"`
+	PhoneNumber string  `json:"phoneNumber,omitempty"`
+	Twitter     string  `json:"twitter,omitempty"`
+	Extra       Extra   `json:"-"`
+}
+
+func (o *ChimoneyItem) Validate() error {
+	switch {
+	case o.ID == "":
+		return fmt.Errorf("%w: chimoney id is required", ErrInvalidRedeemData)
+	case o.Amount < 0:
+		return fmt.Errorf("%w: chimoney %s amount must not be negative", ErrInvalidRedeemData, o.ID)
+	}
+	return nil
+}
+
+/**
+ * This function redeems Chimoney with typed items
+ * @param {ChimoneyItem[]} items The Chimoney transactions
+ * @param {string?} subAccount The subAccount of the transaction
+ * @returns The response from the Chimoney API
+ */
+func (r *Redeem) ChimoneyItems(ctx context.Context, items []ChimoneyItem, subAccount string) (*RedeemResponse, error) {
+	chimoneys := make([]map[string]interface{}, len(items))
+	for i := range items {
+		m, err := optionsMap(&items[i], items[i].Extra)
+		if err != nil {
+			return nil, fmt.Errorf("chimoneys[%d]: %w", i, err)
+		}
+		chimoneys[i] = m
+	}
+	return r.Chimoney(ctx, chimoneys, subAccount)
+}
+
+type validator interface {
+	Validate() error
+}
+
+// optionsMap validates typed options and flattens them, with their extra
+// fields, into the map sent to the API.
+func optionsMap(o validator, extra Extra) (map[string]interface{}, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	typed := map[string]bool{}
+	t := reflect.TypeOf(o).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		typed[strings.Split(t.Field(i).Tag.Get("json"), ",")[0]] = true
+	}
+	for k, v := range extra {
+		if typed[k] {
+			return nil, fmt.Errorf("%w: extra field %q repeats a typed field", ErrInvalidRedeemData, k)
+		}
+		m[k] = v
+	}
+	return m, nil
+}
+
+func typedOptions(o validator, extra Extra, untyped map[string]interface{}) (map[string]interface{}, error) {
+	if len(untyped) > 0 {
+		return nil, fmt.Errorf("%w: set either the typed options or the untyped map, not both", ErrInvalidRedeemData)
+	}
+	return optionsMap(o, extra)
+}
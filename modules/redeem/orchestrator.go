@@ -0,0 +1,390 @@
+package redeem
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrAlreadyRedeemed   = errors.New("chimoney already redeemed")
+	ErrExpired           = errors.New("chimoney expired")
+	ErrInsufficientValue = errors.New("chimoney value too low")
+	ErrNoChannel         = errors.New("no redemption channel")
+)
+
+type Channel string
+
+const (
+	ChannelAirtime     Channel = "airtime"
+	ChannelMobileMoney Channel = "mobile_money"
+	ChannelGiftCard    Channel = "gift_card"
+	ChannelAny         Channel = "any"
+)
+
+// RedemptionError tells at which step a redemption stopped. It matches the
+// error of that step with errors.Is, e.g. ErrAlreadyRedeemed.
+type RedemptionError struct {
+	Step    string
+	ChiRef  string
+	Channel Channel
+	Err     error
+}
+
+func (e *RedemptionError) Error() string {
+	if e.Channel != "" {
+		return fmt.Sprintf("redeem: %s of %s via %s failed: %v", e.Step, e.ChiRef, e.Channel, e.Err)
+	}
+	return fmt.Sprintf("redeem: %s of %s failed: %v", e.Step, e.ChiRef, e.Err)
+}
+
+func (e *RedemptionError) Unwrap() error {
+	return e.Err
+}
+
+// Chimoney is the state of a Chimoney as returned by GetChimoney.
+type Chimoney struct {
+	ChiRef     string
+	Status     string
+	ValueInUSD float64
+	Redeemed   bool
+	ExpiresAt  time.Time
+	Raw        json.RawMessage
+}
+
+/**
+ * This function decodes the data of a GetChimoney response
+ * @returns The Chimoney, whether the data is the Chimoney itself or wrapped in a "chimoney" field
+ */
+func (r *RedeemResponse) Chimoney() (*Chimoney, error) {
+	data := r.Data
+	var wrapped struct {
+		Chimoney json.RawMessage `json:"chimoney"`
+	}
+	if json.Unmarshal(data, &wrapped) == nil && len(wrapped.Chimoney) > 0 {
+		data = wrapped.Chimoney
+	}
+
+	var raw struct {
+		ChiRef     string          `json:"chiRef"`
+		ID         string          `json:"id"`
+		Status     string          `json:"status"`
+		ValueInUSD json.RawMessage `json:"valueInUSD"`
+		Redeemed   bool            `json:"redeemed"`
+		ExpiresAt  json.RawMessage `json:"expiresAt"`
+		ExpiryDate json.RawMessage `json:"expiryDate"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("redeem: unexpected chimoney data: %v", err)
+	}
+
+	c := &Chimoney{ChiRef: raw.ChiRef, Status: strings.ToLower(raw.Status), Raw: data}
+	if c.ChiRef == "" {
+		c.ChiRef = raw.ID
+	}
+	c.Redeemed = raw.Redeemed || c.Status == "redeemed" || c.Status == "claimed" || c.Status == "completed"
+
+	if len(raw.ValueInUSD) > 0 {
+		v, err := strconv.ParseFloat(strings.Trim(string(raw.ValueInUSD), `"`), 64)
+		if err != nil {
+			return nil, fmt.Errorf("redeem: invalid valueInUSD %s", raw.ValueInUSD)
+		}
+		c.ValueInUSD = v
+	}
+
+	expires := raw.ExpiresAt
+	if len(expires) == 0 {
+		expires = raw.ExpiryDate
+	}
+	c.ExpiresAt = parseTime(expires)
+	return c, nil
+}
+
+func parseTime(b json.RawMessage) time.Time {
+	var s string
+	if json.Unmarshal(b, &s) == nil {
+		t, _ := time.Parse(time.RFC3339, s)
+		return t
+	}
+	var ts struct {
+		Seconds int64 `json:"_seconds"`
+	}
+	if json.Unmarshal(b, &ts) == nil && ts.Seconds > 0 {
+		return time.Unix(ts.Seconds, 0).UTC()
+	}
+	var n int64
+	if json.Unmarshal(b, &n) == nil && n > 0 {
+		if n > 1e12 {
+			return time.UnixMilli(n).UTC()
+		}
+		return time.Unix(n, 0).UTC()
+	}
+	return time.Time{}
+}
+
+// RedeemIntent describes a redemption. Set the details of the channels the
+// customer can use; the selector picks one of them unless Channel is set.
+type RedeemIntent struct {
+	ChiRef     string
+	SubAccount string
+	Channel    Channel
+	// MinValueInUSD fails the redemption with ErrInsufficientValue when the
+	// Chimoney is worth less.
+	MinValueInUSD float64
+
+	// Airtime
+	PhoneNumber   string
+	CountryToSend string
+	Meta          *AirtimeMeta
+
+	MobileMoney *MobileMoneyOptions
+	GiftCard    *GiftCardOptions
+	// RedeemData is redeemed with Redeem.Any.
+	RedeemData []RedeemDataItem
+}
+
+// ChannelSelector picks the channel of an intent once the Chimoney passed
+// the precondition checks.
+type ChannelSelector interface {
+	SelectChannel(ctx context.Context, intent *RedeemIntent, c *Chimoney) (Channel, error)
+}
+
+type ChannelSelectorFunc func(ctx context.Context, intent *RedeemIntent, c *Chimoney) (Channel, error)
+
+func (f ChannelSelectorFunc) SelectChannel(ctx context.Context, intent *RedeemIntent, c *Chimoney) (Channel, error) {
+	return f(ctx, intent, c)
+}
+
+// DefaultSelector uses the channel of the intent, or else the first channel
+// with details in the order: any, gift card, mobile money, airtime.
+var DefaultSelector ChannelSelector = ChannelSelectorFunc(func(ctx context.Context, intent *RedeemIntent, c *Chimoney) (Channel, error) {
+	switch {
+	case intent.Channel != "":
+		return intent.Channel, nil
+	case len(intent.RedeemData) > 0:
+		return ChannelAny, nil
+	case intent.GiftCard != nil:
+		return ChannelGiftCard, nil
+	case intent.MobileMoney != nil:
+		return ChannelMobileMoney, nil
+	case intent.PhoneNumber != "" && intent.CountryToSend != "":
+		return ChannelAirtime, nil
+	}
+	return "", ErrNoChannel
+})
+
+type ReceiptStatus string
+
+const (
+	ReceiptRedeemed ReceiptStatus = "redeemed"
+	// ReceiptPending means the API accepted the redemption but the Chimoney
+	// was not reported as redeemed yet.
+	ReceiptPending ReceiptStatus = "pending"
+)
+
+type Receipt struct {
+	ChiRef     string
+	SubAccount string
+	Channel    Channel
+	Status     ReceiptStatus
+	ValueInUSD float64
+	// Recipient is the phone number, email or product the value went to.
+	Recipient string
+	// RedeemedAt is when the redemption was accepted, also for pending receipts.
+	RedeemedAt time.Time
+	Response   *RedeemResponse
+}
+
+type Orchestrator struct {
+	redeem   *Redeem
+	selector ChannelSelector
+	now      func() time.Time
+}
+
+type OrchestratorOption func(*Orchestrator)
+
+func WithChannelSelector(s ChannelSelector) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.selector = s
+	}
+}
+
+func WithClock(now func() time.Time) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.now = now
+	}
+}
+
+/**
+ * This function creates an orchestrator that runs redemptions end to end
+ * @param {Redeem} r The Redeem module used for every step
+ * @returns The orchestrator, selecting channels with DefaultSelector unless configured otherwise
+ */
+func NewOrchestrator(r *Redeem, opts ...OrchestratorOption) *Orchestrator {
+	o := &Orchestrator{redeem: r, selector: DefaultSelector, now: time.Now}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+/**
+ * This function fetches a Chimoney, checks it can be redeemed, redeems it through the selected channel and confirms the result
+ * @param {RedeemIntent} intent The redemption to run
+ * @returns A receipt; a *RedemptionError naming the failed step otherwise
+ */
+func (o *Orchestrator) Redeem(ctx context.Context, intent *RedeemIntent) (*Receipt, error) {
+	if intent.ChiRef == "" {
+		return nil, ErrInvalidChiRef
+	}
+	fail := func(step string, channel Channel, err error) error {
+		return &RedemptionError{Step: step, ChiRef: intent.ChiRef, Channel: channel, Err: err}
+	}
+
+	c, err := o.Check(ctx, intent)
+	if err != nil {
+		return nil, err
+	}
+
+	channel, err := o.selector.SelectChannel(ctx, intent, c)
+	if err != nil {
+		return nil, fail("select", "", err)
+	}
+
+	resp, recipient, err := o.execute(ctx, intent, channel)
+	if err != nil {
+		return nil, fail("execute", channel, err)
+	}
+
+	receipt := &Receipt{
+		ChiRef:     intent.ChiRef,
+		SubAccount: intent.SubAccount,
+		Channel:    channel,
+		Status:     ReceiptPending,
+		ValueInUSD: c.ValueInUSD,
+		Recipient:  recipient,
+		RedeemedAt: o.now(),
+		Response:   resp,
+	}
+
+	after, err := o.fetch(ctx, intent)
+	if err != nil {
+		return receipt, fail("confirm", channel, err)
+	}
+	if after.Redeemed {
+		receipt.Status = ReceiptRedeemed
+	}
+	return receipt, nil
+}
+
+/**
+ * This function fetches a Chimoney and checks it can be redeemed, as Redeem does first
+ * @param {RedeemIntent} intent The Chimoney and the minimum value it must be worth
+ * @returns The Chimoney; a *RedemptionError at the fetch or check step otherwise
+ */
+func (o *Orchestrator) Check(ctx context.Context, intent *RedeemIntent) (*Chimoney, error) {
+	if intent.ChiRef == "" {
+		return nil, ErrInvalidChiRef
+	}
+	c, err := o.fetch(ctx, intent)
+	if err != nil {
+		return nil, &RedemptionError{Step: "fetch", ChiRef: intent.ChiRef, Err: err}
+	}
+	if err := o.check(intent, c); err != nil {
+		return nil, &RedemptionError{Step: "check", ChiRef: intent.ChiRef, Err: err}
+	}
+	return c, nil
+}
+
+func (o *Orchestrator) fetch(ctx context.Context, intent *RedeemIntent) (*Chimoney, error) {
+	resp, err := o.redeem.GetChimoney(ctx, intent.ChiRef, intent.SubAccount)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Chimoney()
+}
+
+func (o *Orchestrator) check(intent *RedeemIntent, c *Chimoney) error {
+	switch {
+	case c.Redeemed:
+		return ErrAlreadyRedeemed
+	case c.Status == "expired" || c.Status == "cancelled":
+		return fmt.Errorf("%w: status is %s", ErrExpired, c.Status)
+	case !c.ExpiresAt.IsZero() && !o.now().Before(c.ExpiresAt):
+		return fmt.Errorf("%w: on %s", ErrExpired, c.ExpiresAt.Format(time.RFC3339))
+	case c.ValueInUSD <= 0:
+		return fmt.Errorf("%w: worth %.2f USD", ErrInsufficientValue, c.ValueInUSD)
+	case intent.MinValueInUSD > 0 && c.ValueInUSD < intent.MinValueInUSD:
+		return fmt.Errorf("%w: worth %.2f USD, need %.2f", ErrInsufficientValue, c.ValueInUSD, intent.MinValueInUSD)
+	}
+	return nil
+}
+
+func (o *Orchestrator) execute(ctx context.Context, intent *RedeemIntent, channel Channel) (*RedeemResponse, string, error) {
+	var resp *RedeemResponse
+	var recipient string
+	var err error
+
+	switch channel {
+	case ChannelAirtime:
+		if intent.PhoneNumber == "" || intent.CountryToSend == "" {
+			return nil, "", fmt.Errorf("%w: airtime needs a phone number and country", ErrNoChannel)
+		}
+		recipient = intent.PhoneNumber
+		resp, err = o.redeem.Airtime(ctx, &AirtimeRedeemRequest{
+			ChiRef:        intent.ChiRef,
+			PhoneNumber:   intent.PhoneNumber,
+			CountryToSend: intent.CountryToSend,
+			SubAccount:    intent.SubAccount,
+			Options:       intent.Meta,
+		})
+	case ChannelMobileMoney:
+		if intent.MobileMoney == nil {
+			return nil, "", fmt.Errorf("%w: no mobile money options", ErrNoChannel)
+		}
+		recipient = intent.MobileMoney.PhoneNumber
+		resp, err = o.redeem.MobileMoney(ctx, &MobileMoneyRedeemRequest{
+			ChiRef:     intent.ChiRef,
+			SubAccount: intent.SubAccount,
+			Options:    intent.MobileMoney,
+		})
+	case ChannelGiftCard:
+		if intent.GiftCard == nil {
+			return nil, "", fmt.Errorf("%w: no gift card options", ErrNoChannel)
+		}
+		recipient = intent.GiftCard.Email
+		if recipient == "" {
+			recipient = intent.GiftCard.ProductID
+		}
+		resp, err = o.redeem.GiftCard(ctx, &GiftCardRedeemRequest{
+			ChiRef:     intent.ChiRef,
+			SubAccount: intent.SubAccount,
+			Options:    intent.GiftCard,
+		})
+	case ChannelAny:
+		products := make([]string, len(intent.RedeemData))
+		for i, item := range intent.RedeemData {
+			products[i] = item.ProductID
+		}
+		recipient = strings.Join(products, ", ")
+		resp, err = o.redeem.Any(ctx, &AnyRedeemRequest{
+			ChiRef:     intent.ChiRef,
+			RedeemData: intent.RedeemData,
+			SubAccount: intent.SubAccount,
+		})
+	default:
+		return nil, "", fmt.Errorf("%w: unknown channel %q", ErrNoChannel, channel)
+	}
+
+	if err != nil {
+		return nil, "", err
+	}
+	if resp.Status == "error" {
+		return nil, "", fmt.Errorf("redeem: API rejected the redemption: %s", resp.Message)
+	}
+	return resp, recipient, nil
+}
@@ -0,0 +1,373 @@
+package receipt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/chimoney/chimoney-go/modules/payouts"
+	"github.com/chimoney/chimoney-go/modules/redeem"
+)
+
+type Kind string
+
+const (
+	Redemption Kind = "redemption"
+	Payout     Kind = "payout"
+)
+
+// Detail is a labelled line of a receipt. Sensitive values, such as gift
+// card codes, are masked unless the renderer reveals them.
+type Detail struct {
+	Label     string `json:"label"`
+	Value     string `json:"value"`
+	Sensitive bool   `json:"sensitive,omitempty"`
+}
+
+type Receipt struct {
+	Kind          Kind
+	ChiRef        string
+	Channel       string
+	Status        string
+	ValueInUSD    float64
+	LocalAmount   float64
+	LocalCurrency string
+	Time          time.Time
+	Reference     string
+	Recipient     string
+	Details       []Detail
+}
+
+/**
+ * This function builds the receipt of a redemption
+ * @param {redeem.Receipt} r The receipt returned by redeem.Orchestrator
+ * @returns The receipt, with the gift card, airtime and transaction details of the API response
+ */
+func FromRedemption(r *redeem.Receipt) (*Receipt, error) {
+	rc := &Receipt{
+		Kind:       Redemption,
+		ChiRef:     r.ChiRef,
+		Channel:    string(r.Channel),
+		Status:     string(r.Status),
+		ValueInUSD: r.ValueInUSD,
+		Time:       r.RedeemedAt,
+		Recipient:  r.Recipient,
+	}
+	if r.Response == nil || len(r.Response.Data) == 0 || string(r.Response.Data) == "null" {
+		return rc, nil
+	}
+
+	var data struct {
+		ID          string          `json:"id"`
+		Reference   string          `json:"reference"`
+		Amount      json.RawMessage `json:"amount"`
+		Currency    string          `json:"currency"`
+		PhoneNumber string          `json:"phoneNumber"`
+		Country     string          `json:"countryToSend"`
+		GiftCard    *struct {
+			Code        string          `json:"code"`
+			Pin         string          `json:"pin"`
+			Amount      json.RawMessage `json:"amount"`
+			Currency    string          `json:"currency"`
+			Email       string          `json:"email"`
+			ProductName string          `json:"productName"`
+		} `json:"giftCard"`
+		Transaction *struct {
+			ID        string          `json:"id"`
+			Reference string          `json:"reference"`
+			Amount    json.RawMessage `json:"amount"`
+			Currency  string          `json:"currency"`
+			Provider  string          `json:"provider"`
+		} `json:"transaction"`
+	}
+	if err := json.Unmarshal(r.Response.Data, &data); err != nil {
+		return nil, fmt.Errorf("receipt: unexpected redemption data: %v", err)
+	}
+
+	rc.Reference = first(data.Reference, data.ID)
+	rc.LocalAmount, rc.LocalCurrency = number(data.Amount), data.Currency
+
+	if data.PhoneNumber != "" {
+		rc.Details = append(rc.Details, Detail{Label: "Phone number", Value: data.PhoneNumber})
+	}
+	if data.Country != "" {
+		rc.Details = append(rc.Details, Detail{Label: "Country", Value: data.Country})
+	}
+	if g := data.GiftCard; g != nil {
+		if g.ProductName != "" {
+			rc.Details = append(rc.Details, Detail{Label: "Gift card", Value: g.ProductName})
+		}
+		if g.Email != "" {
+			rc.Details = append(rc.Details, Detail{Label: "Sent to", Value: g.Email})
+		}
+		if g.Code != "" {
+			rc.Details = append(rc.Details, Detail{Label: "Gift card code", Value: g.Code, Sensitive: true})
+		}
+		if g.Pin != "" {
+			rc.Details = append(rc.Details, Detail{Label: "PIN", Value: g.Pin, Sensitive: true})
+		}
+		if rc.LocalAmount == 0 {
+			rc.LocalAmount, rc.LocalCurrency = number(g.Amount), g.Currency
+		}
+	}
+	if t := data.Transaction; t != nil {
+		if t.Provider != "" {
+			rc.Details = append(rc.Details, Detail{Label: "Provider", Value: t.Provider})
+		}
+		rc.Reference = first(rc.Reference, t.Reference, t.ID)
+		if rc.LocalAmount == 0 {
+			rc.LocalAmount, rc.LocalCurrency = number(t.Amount), t.Currency
+		}
+	}
+	return rc, nil
+}
+
+/**
+ * This function builds the receipts of a payout
+ * @param {payouts.PayoutResult} result The decoded payout response
+ * @param {time.Time} at When the payout was sent
+ * @returns One receipt per payout item
+ */
+func FromPayout(result *payouts.PayoutResult, at time.Time) []*Receipt {
+	receipts := make([]*Receipt, 0, len(result.Chimoneys))
+	for _, item := range result.Chimoneys {
+		rc := &Receipt{
+			Kind:       Payout,
+			ChiRef:     item.ChiRef,
+			Status:     item.Status,
+			ValueInUSD: item.ValueInUSD,
+			Time:       at,
+			Reference:  first(item.IssueID, item.ID),
+		}
+		var raw struct {
+			Email       string `json:"email"`
+			PhoneNumber string `json:"phoneNumber"`
+			Type        string `json:"type"`
+		}
+		if len(item.Raw) > 0 {
+			json.Unmarshal(item.Raw, &raw)
+		}
+		rc.Channel = raw.Type
+		rc.Recipient = first(raw.Email, raw.PhoneNumber)
+		if item.RedeemLink != "" {
+			// Anyone holding the link can claim the payout.
+			rc.Details = append(rc.Details, Detail{Label: "Redeem link", Value: item.RedeemLink, Sensitive: true})
+		}
+		if item.Error != "" {
+			rc.Details = append(rc.Details, Detail{Label: "Error", Value: item.Error})
+		}
+		receipts = append(receipts, rc)
+	}
+	return receipts
+}
+
+// View is what the templates render: the receipt with sensitive values
+// masked and the time formatted.
+type View struct {
+	Title         string   `json:"title"`
+	Kind          Kind     `json:"kind"`
+	ChiRef        string   `json:"chiRef"`
+	Channel       string   `json:"channel,omitempty"`
+	Status        string   `json:"status,omitempty"`
+	ValueInUSD    float64  `json:"valueInUSD"`
+	LocalAmount   float64  `json:"localAmount,omitempty"`
+	LocalCurrency string   `json:"localCurrency,omitempty"`
+	Time          string   `json:"time,omitempty"`
+	Reference     string   `json:"reference,omitempty"`
+	Recipient     string   `json:"recipient,omitempty"`
+	Details       []Detail `json:"details,omitempty"`
+}
+
+const DefaultText = `{{.Title}}
+ChiRef:    {{.ChiRef}}
+{{- if .Channel}}
+Channel:   {{.Channel}}{{end}}
+{{- if .Status}}
+Status:    {{.Status}}{{end}}
+Amount:    {{money .ValueInUSD "USD"}}
+{{- if .LocalCurrency}} ({{money .LocalAmount .LocalCurrency}}){{end}}
+{{- if .Time}}
+Date:      {{.Time}}{{end}}
+{{- if .Recipient}}
+Recipient: {{.Recipient}}{{end}}
+{{- if .Reference}}
+Reference: {{.Reference}}{{end}}
+{{- range .Details}}
+{{.Label}}: {{.Value}}{{end}}
+`
+
+const DefaultHTML = `<div class="chimoney-receipt">
+<h2>{{.Title}}</h2>
+<table>
+<tr><th>ChiRef</th><td>{{.ChiRef}}</td></tr>
+{{- if .Channel}}
+<tr><th>Channel</th><td>{{.Channel}}</td></tr>{{end}}
+{{- if .Status}}
+<tr><th>Status</th><td>{{.Status}}</td></tr>{{end}}
+<tr><th>Amount</th><td>{{money .ValueInUSD "USD"}}{{if .LocalCurrency}} ({{money .LocalAmount .LocalCurrency}}){{end}}</td></tr>
+{{- if .Time}}
+<tr><th>Date</th><td>{{.Time}}</td></tr>{{end}}
+{{- if .Recipient}}
+<tr><th>Recipient</th><td>{{.Recipient}}</td></tr>{{end}}
+{{- if .Reference}}
+<tr><th>Reference</th><td>{{.Reference}}</td></tr>{{end}}
+{{- range .Details}}
+<tr><th>{{.Label}}</th><td>{{.Value}}</td></tr>{{end}}
+</table>
+</div>
+`
+
+type Renderer struct {
+	text     *texttemplate.Template
+	html     *htmltemplate.Template
+	textSrc  string
+	htmlSrc  string
+	reveal   bool
+	location *time.Location
+}
+
+type Option func(*Renderer)
+
+// WithTextTemplate replaces DefaultText. The template is executed with a View.
+func WithTextTemplate(src string) Option {
+	return func(r *Renderer) {
+		r.textSrc = src
+	}
+}
+
+// WithHTMLTemplate replaces DefaultHTML. Values are escaped by html/template.
+func WithHTMLTemplate(src string) Option {
+	return func(r *Renderer) {
+		r.htmlSrc = src
+	}
+}
+
+// Reveal shows sensitive values, such as gift card codes, in full.
+func Reveal() Option {
+	return func(r *Renderer) {
+		r.reveal = true
+	}
+}
+
+// WithLocation sets the time zone of receipt dates, UTC by default.
+func WithLocation(loc *time.Location) Option {
+	return func(r *Renderer) {
+		r.location = loc
+	}
+}
+
+var funcs = map[string]interface{}{
+	"money": func(amount float64, currency string) string {
+		return strconv.FormatFloat(amount, 'f', 2, 64) + " " + currency
+	},
+}
+
+func New(options ...Option) (*Renderer, error) {
+	r := &Renderer{
+		textSrc:  DefaultText,
+		htmlSrc:  DefaultHTML,
+		location: time.UTC,
+	}
+
+	for _, opt := range options {
+		opt(r)
+	}
+
+	var err error
+	if r.text, err = texttemplate.New("receipt").Funcs(funcs).Parse(r.textSrc); err != nil {
+		return nil, fmt.Errorf("receipt: invalid text template: %v", err)
+	}
+	if r.html, err = htmltemplate.New("receipt").Funcs(funcs).Parse(r.htmlSrc); err != nil {
+		return nil, fmt.Errorf("receipt: invalid HTML template: %v", err)
+	}
+	return r, nil
+}
+
+/**
+ * This function prepares a receipt for rendering
+ * @param {Receipt} rc The receipt
+ * @returns The view passed to the templates, masked unless the renderer reveals sensitive values
+ */
+func (r *Renderer) View(rc *Receipt) View {
+	v := View{
+		Title:         "Chimoney " + string(rc.Kind) + " receipt",
+		Kind:          rc.Kind,
+		ChiRef:        rc.ChiRef,
+		Channel:       rc.Channel,
+		Status:        rc.Status,
+		ValueInUSD:    rc.ValueInUSD,
+		LocalAmount:   rc.LocalAmount,
+		LocalCurrency: rc.LocalCurrency,
+		Reference:     rc.Reference,
+		Recipient:     rc.Recipient,
+	}
+	if !rc.Time.IsZero() {
+		v.Time = rc.Time.In(r.location).Format("2006-01-02 15:04:05 MST")
+	}
+	for _, d := range rc.Details {
+		if d.Sensitive && !r.reveal {
+			d.Value = Mask(d.Value)
+		}
+		v.Details = append(v.Details, d)
+	}
+	return v
+}
+
+func (r *Renderer) Text(rc *Receipt) (string, error) {
+	var buf bytes.Buffer
+	if err := r.text.Execute(&buf, r.View(rc)); err != nil {
+		return "", fmt.Errorf("receipt: %v", err)
+	}
+	return buf.String(), nil
+}
+
+func (r *Renderer) HTML(rc *Receipt) (string, error) {
+	var buf bytes.Buffer
+	if err := r.html.Execute(&buf, r.View(rc)); err != nil {
+		return "", fmt.Errorf("receipt: %v", err)
+	}
+	return buf.String(), nil
+}
+
+func (r *Renderer) JSON(rc *Receipt) ([]byte, error) {
+	return json.MarshalIndent(r.View(rc), "", "  ")
+}
+
+/**
+ * This function masks a sensitive value
+ * @param {string} s The value
+ * @returns The value with all but its last 4 characters replaced, or fully masked when shorter than 8
+ */
+func Mask(s string) string {
+	n := len([]rune(s))
+	if n < 8 {
+		return strings.Repeat("*", n)
+	}
+	runes := []rune(s)
+	for i := 0; i < n-4; i++ {
+		if runes[i] != '-' && runes[i] != ' ' {
+			runes[i] = '*'
+		}
+	}
+	return string(runes)
+}
+
+func first(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// number reads a JSON number or numeric string, or 0.
+func number(raw json.RawMessage) float64 {
+	v, _ := strconv.ParseFloat(strings.Trim(string(raw), `"`), 64)
+	return v
+}
@@ -35,12 +35,16 @@ type AirtimeRedeemRequest struct {
 	CountryToSend string                `json:"countryToSend"`
 	Meta         map[string]interface{} `json:"meta,omitempty"`
 	SubAccount   string                 `json:"subAccount,omitempty"`
+	// Options is the typed alternative to Meta; set one or the other.
+	Options *AirtimeMeta `json:"-"`
 }
 
 type RedeemDataItem struct {
 	CountryCode          string  `json:"countryCode"`
 	ProductID            string  `json:"productId"`
 	ValueInLocalCurrency float64 `json:"valueInLocalCurrency"`
+	// PhoneNumber receives airtime products.
+	PhoneNumber string `json:"phoneNumber,omitempty"`
 }
 
 type AnyRedeemRequest struct {
@@ -59,12 +63,16 @@ type GiftCardRedeemRequest struct {
 	ChiRef        string                 `json:"chiRef"`
 	RedeemOptions map[string]interface{} `json:"redeemOptions"`
 	SubAccount    string                 `json:"subAccount,omitempty"`
+	// Options is the typed alternative to RedeemOptions; set one or the other.
+	Options *GiftCardOptions `json:"-"`
 }
 
 type MobileMoneyRedeemRequest struct {
 	ChiRef        string                 `json:"chiRef"`
 	RedeemOptions map[string]interface{} `json:"redeemOptions"`
 	SubAccount    string                 `json:"subAccount,omitempty"`
+	// Options is the typed alternative to RedeemOptions; set one or the other.
+	Options *MobileMoneyOptions `json:"-"`
 }
 
 /**
@@ -80,6 +88,15 @@ func (r *Redeem) Airtime(ctx context.Context, req *AirtimeRedeemRequest) (*Redee
 	if req.ChiRef == "" {
 		return nil, ErrInvalidChiRef
 	}
+	if req.Options != nil {
+		meta, err := typedOptions(req.Options, req.Options.Extra, req.Meta)
+		if err != nil {
+			return nil, err
+		}
+		body := *req
+		body.Meta = meta
+		req = &body
+	}
 
 	resp := new(RedeemResponse)
 	err := r.client.Do(ctx, "POST", "/redeem/airtime", req, resp, nil)
@@ -162,6 +179,15 @@ func (r *Redeem) GiftCard(ctx context.Context, req *GiftCardRedeemRequest) (*Red
 	if req.ChiRef == "" {
 		return nil, ErrInvalidChiRef
 	}
+	if req.Options != nil {
+		options, err := typedOptions(req.Options, req.Options.Extra, req.RedeemOptions)
+		if err != nil {
+			return nil, err
+		}
+		body := *req
+		body.RedeemOptions = options
+		req = &body
+	}
 	if len(req.RedeemOptions) == 0 {
 		return nil, ErrInvalidRedeemData
 	}
@@ -182,6 +208,15 @@ func (r *Redeem) MobileMoney(ctx context.Context, req *MobileMoneyRedeemRequest)
 	if req.ChiRef == "" {
 		return nil, ErrInvalidChiRef
 	}
+	if req.Options != nil {
+		options, err := typedOptions(req.Options, req.Options.Extra, req.RedeemOptions)
+		if err != nil {
+			return nil, err
+		}
+		body := *req
+		body.RedeemOptions = options
+		req = &body
+	}
 	if len(req.RedeemOptions) == 0 {
 		return nil, ErrInvalidRedeemData
 	}
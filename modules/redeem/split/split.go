@@ -0,0 +1,308 @@
+package split
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/chimoney/chimoney-go/modules/info"
+	"github.com/chimoney/chimoney-go/modules/payouts/giftcards"
+	"github.com/chimoney/chimoney-go/modules/redeem"
+)
+
+var (
+	ErrInvalidAllocation = errors.New("invalid allocation")
+	ErrUnallocated       = errors.New("allocations do not add up to the chimoney value")
+)
+
+type Kind string
+
+const (
+	GiftCard Kind = "gift_card"
+	Airtime  Kind = "airtime"
+)
+
+// Allocation is the share of a Chimoney sent to one product. The product is
+// ProductID, or else the first catalog product matching Brand and Country.
+// Airtime needs the Country and PhoneNumber of the recipient.
+type Allocation struct {
+	Kind        Kind
+	ValueInUSD  float64
+	ProductID   string
+	Brand       string
+	Country     string
+	PhoneNumber string
+	// Snap fits the converted local value to the product's denominations.
+	// Exact, the zero value, fails unless the value is allowed as is.
+	Snap giftcards.Snap
+}
+
+// RemainderPolicy decides what happens to the value not allocated, including
+// what snapping to denominations leaves over.
+type RemainderPolicy int
+
+const (
+	// Reject fails with ErrUnallocated unless the allocations add up to the value.
+	Reject RemainderPolicy = iota
+	// Leave keeps the remainder unredeemed.
+	Leave
+	// ToDefault allocates the remainder to Split.Default.
+	ToDefault
+)
+
+type Split struct {
+	ChiRef      string
+	SubAccount  string
+	Allocations []Allocation
+	Remainder   RemainderPolicy
+	// Default receives the remainder with ToDefault; its ValueInUSD is ignored.
+	Default *Allocation
+}
+
+// Item is an allocation resolved to a product and converted to its currency.
+// ValueInUSD is the part of the Chimoney it uses after snapping.
+type Item struct {
+	Allocation Allocation
+	Product    info.Asset
+	LocalValue float64
+	ValueInUSD float64
+}
+
+type Plan struct {
+	ChiRef     string
+	SubAccount string
+	ValueInUSD float64
+	Items      []Item
+	// Remainder is the USD value left unredeemed.
+	Remainder float64
+}
+
+/**
+ * This function builds the request that redeems the plan with Redeem.Any
+ * @returns The request, one RedeemDataItem per planned item
+ */
+func (p *Plan) Request() *redeem.AnyRedeemRequest {
+	req := &redeem.AnyRedeemRequest{ChiRef: p.ChiRef, SubAccount: p.SubAccount}
+	for _, item := range p.Items {
+		req.RedeemData = append(req.RedeemData, redeem.RedeemDataItem{
+			CountryCode:          item.Product.CountryCode,
+			ProductID:            item.Product.ProductID,
+			ValueInLocalCurrency: item.LocalValue,
+			PhoneNumber:          item.Allocation.PhoneNumber,
+		})
+	}
+	return req
+}
+
+/**
+ * This function turns the plan into an intent for redeem.Orchestrator
+ * @returns An intent redeemed through the any channel
+ */
+func (p *Plan) Intent() *redeem.RedeemIntent {
+	return &redeem.RedeemIntent{
+		ChiRef:     p.ChiRef,
+		SubAccount: p.SubAccount,
+		Channel:    redeem.ChannelAny,
+		RedeemData: p.Request().RedeemData,
+	}
+}
+
+type Builder struct {
+	orchestrator *redeem.Orchestrator
+	info         *info.Info
+	catalogs     map[Kind]*giftcards.Catalog
+}
+
+type Option func(*Builder)
+
+// WithCatalog replaces the catalog products of a kind are resolved from.
+func WithCatalog(kind Kind, c *giftcards.Catalog) Option {
+	return func(b *Builder) {
+		b.catalogs[kind] = c
+	}
+}
+
+// WithOrchestrator replaces the orchestrator whose checks a Chimoney must
+// pass before a plan is built, such as one with a custom clock.
+func WithOrchestrator(o *redeem.Orchestrator) Option {
+	return func(b *Builder) {
+		b.orchestrator = o
+	}
+}
+
+func New(r *redeem.Redeem, i *info.Info, options ...Option) *Builder {
+	b := &Builder{
+		orchestrator: redeem.NewOrchestrator(r),
+		info:         i,
+		catalogs: map[Kind]*giftcards.Catalog{
+			GiftCard: giftcards.New(i),
+			Airtime:  giftcards.New(i, giftcards.WithAssetType("airtime")),
+		},
+	}
+
+	for _, opt := range options {
+		opt(b)
+	}
+
+	return b
+}
+
+/**
+ * This function plans a split redemption
+ * @param {Split} s The Chimoney, its allocations and the remainder policy
+ * @returns The plan, with products resolved and values converted to local currency; the
+ * Chimoney must pass the checks of redeem.Orchestrator first
+ */
+func (b *Builder) Build(ctx context.Context, s Split) (*Plan, error) {
+	if s.ChiRef == "" {
+		return nil, redeem.ErrInvalidChiRef
+	}
+	if len(s.Allocations) == 0 {
+		return nil, fmt.Errorf("%w: no allocations", ErrInvalidAllocation)
+	}
+	if s.Remainder == ToDefault && s.Default == nil {
+		return nil, fmt.Errorf("%w: remainder policy needs a default allocation", ErrInvalidAllocation)
+	}
+	for i, a := range s.Allocations {
+		if err := recipient(a); err != nil {
+			return nil, fmt.Errorf("%w: allocation %d %s", ErrInvalidAllocation, i, err)
+		}
+	}
+	if s.Remainder == ToDefault {
+		if err := recipient(*s.Default); err != nil {
+			return nil, fmt.Errorf("%w: default allocation %s", ErrInvalidAllocation, err)
+		}
+	}
+
+	c, err := b.orchestrator.Check(ctx, &redeem.RedeemIntent{ChiRef: s.ChiRef, SubAccount: s.SubAccount})
+	if err != nil {
+		return nil, err
+	}
+
+	var total float64
+	for i, a := range s.Allocations {
+		if a.ValueInUSD <= 0 {
+			return nil, fmt.Errorf("%w: allocation %d has no value", ErrInvalidAllocation, i)
+		}
+		total += a.ValueInUSD
+	}
+	switch {
+	case total > c.ValueInUSD+0.005:
+		return nil, fmt.Errorf("%w: allocations total %.2f USD, chimoney is worth %.2f", redeem.ErrInsufficientValue, total, c.ValueInUSD)
+	case s.Remainder == Reject && total < c.ValueInUSD-0.005:
+		return nil, fmt.Errorf("%w: %.2f of %.2f USD allocated", ErrUnallocated, total, c.ValueInUSD)
+	}
+
+	plan := &Plan{ChiRef: s.ChiRef, SubAccount: s.SubAccount, ValueInUSD: c.ValueInUSD}
+	rates := map[string]float64{}
+	var used float64
+	for i, a := range s.Allocations {
+		item, err := b.item(ctx, a, rates)
+		if err != nil {
+			return nil, fmt.Errorf("allocation %d: %w", i, err)
+		}
+		plan.Items = append(plan.Items, *item)
+		used += item.ValueInUSD
+	}
+
+	remainder := round2(c.ValueInUSD - used)
+	if remainder >= 0.01 {
+		switch s.Remainder {
+		case Reject:
+			return nil, fmt.Errorf("%w: denominations leave %.2f USD", ErrUnallocated, remainder)
+		case ToDefault:
+			a := *s.Default
+			a.ValueInUSD = remainder
+			item, err := b.item(ctx, a, rates)
+			if err != nil {
+				return nil, fmt.Errorf("default allocation: %w", err)
+			}
+			plan.Items = append(plan.Items, *item)
+			used += item.ValueInUSD
+		}
+	}
+	if used > c.ValueInUSD+0.005 {
+		return nil, fmt.Errorf("%w: denominations use %.2f USD, chimoney is worth %.2f", redeem.ErrInsufficientValue, used, c.ValueInUSD)
+	}
+	plan.Remainder = math.Max(0, round2(c.ValueInUSD-used))
+	return plan, nil
+}
+
+func (b *Builder) item(ctx context.Context, a Allocation, rates map[string]float64) (*Item, error) {
+	kind := a.Kind
+	if kind == "" {
+		kind = GiftCard
+	}
+	catalog, ok := b.catalogs[kind]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown kind %q", ErrInvalidAllocation, a.Kind)
+	}
+
+	var product *info.Asset
+	if a.ProductID != "" {
+		p, err := catalog.Product(ctx, a.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		product = p
+	} else {
+		matches, err := catalog.Search(ctx, giftcards.Query{Brand: a.Brand, Country: a.Country})
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("%w: no %s product for brand %q in %q", giftcards.ErrProductNotFound, kind, a.Brand, a.Country)
+		}
+		product = &matches[0]
+	}
+
+	rate, err := b.rate(ctx, product.Currency, rates)
+	if err != nil {
+		return nil, err
+	}
+	local, err := giftcards.SnapAmount(*product, round2(a.ValueInUSD*rate), a.Snap)
+	if err != nil {
+		return nil, err
+	}
+	return &Item{
+		Allocation: a,
+		Product:    *product,
+		LocalValue: local,
+		ValueInUSD: round2(local / rate),
+	}, nil
+}
+
+// rate returns the local amount of one USD, once per currency and build.
+func (b *Builder) rate(ctx context.Context, currency string, rates map[string]float64) (float64, error) {
+	if currency == "" || strings.EqualFold(currency, "USD") {
+		return 1, nil
+	}
+	if r, ok := rates[currency]; ok {
+		return r, nil
+	}
+
+	resp, err := b.info.GetUSDInLocalAmount(ctx, currency, 1)
+	if err != nil {
+		return 0, err
+	}
+	rate, err := resp.LocalAmount()
+	if err != nil {
+		return 0, fmt.Errorf("split: rate for %s: %w", currency, err)
+	}
+	rates[currency] = rate
+	return rate, nil
+}
+
+// recipient checks that an airtime allocation says who receives it.
+func recipient(a Allocation) error {
+	if a.Kind == Airtime && (strings.TrimSpace(a.PhoneNumber) == "" || strings.TrimSpace(a.Country) == "") {
+		return errors.New("needs a phone number and country for airtime")
+	}
+	return nil
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
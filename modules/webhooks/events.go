@@ -0,0 +1,176 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type EventType string
+
+const (
+	PayoutCompleted      EventType = "payout.completed"
+	PayoutFailed         EventType = "payout.failed"
+	CollectionSuccessful EventType = "collection.successful"
+	RedeemCompleted      EventType = "redeem.completed"
+	WalletCredited       EventType = "wallet.credited"
+)
+
+// Event is a webhook delivery. Data holds the event specific payload, which
+// the typed accessors decode.
+type Event struct {
+	ID        string          `json:"id"`
+	Type      EventType       `json:"type"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Data      json.RawMessage `json:"data"`
+}
+
+func (e *Event) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		ID        string          `json:"id"`
+		EventID   string          `json:"eventId"`
+		Type      string          `json:"type"`
+		Event     string          `json:"event"`
+		CreatedAt json.RawMessage `json:"createdAt"`
+		Data      json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*e = Event{ID: raw.ID, Type: EventType(raw.Type), Data: raw.Data}
+	if e.ID == "" {
+		e.ID = raw.EventID
+	}
+	if e.Type == "" {
+		e.Type = EventType(raw.Event)
+	}
+	e.Type = EventType(strings.ToLower(strings.TrimSpace(string(e.Type))))
+
+	e.CreatedAt = parseTime(raw.CreatedAt)
+	return nil
+}
+
+// parseTime reads a timestamp as RFC 3339 or a few other layouts, a
+// Firestore timestamp, or epoch seconds or milliseconds. A timestamp it cannot
+// read is left zero rather than failing a signed delivery.
+func parseTime(b json.RawMessage) time.Time {
+	var s string
+	if json.Unmarshal(b, &s) == nil {
+		for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"} {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t
+			}
+		}
+		b = json.RawMessage(strings.TrimSpace(s))
+	}
+	var ts struct {
+		Seconds int64 `json:"_seconds"`
+	}
+	if json.Unmarshal(b, &ts) == nil && ts.Seconds > 0 {
+		return time.Unix(ts.Seconds, 0).UTC()
+	}
+	var n float64
+	if json.Unmarshal(b, &n) == nil && n > 0 {
+		if n > 1e12 {
+			return time.UnixMilli(int64(n)).UTC()
+		}
+		return time.Unix(int64(n), 0).UTC()
+	}
+	return time.Time{}
+}
+
+type PayoutEvent struct {
+	ChiRef      string  `json:"chiRef"`
+	IssueID     string  `json:"issueID"`
+	Status      string  `json:"status"`
+	ValueInUSD  float64 `json:"valueInUSD"`
+	Amount      float64 `json:"amount,omitempty"`
+	Currency    string  `json:"currency,omitempty"`
+	Email       string  `json:"email,omitempty"`
+	PhoneNumber string  `json:"phoneNumber,omitempty"`
+	SubAccount  string  `json:"subAccount,omitempty"`
+	Reason      string  `json:"reason,omitempty"`
+}
+
+type CollectionEvent struct {
+	ID          string  `json:"id"`
+	TxRef       string  `json:"tx_ref"`
+	Status      string  `json:"status"`
+	Amount      float64 `json:"amount"`
+	Currency    string  `json:"currency"`
+	PhoneNumber string  `json:"phoneNumber,omitempty"`
+	SubAccount  string  `json:"subAccount,omitempty"`
+}
+
+type RedeemEvent struct {
+	ChiRef     string  `json:"chiRef"`
+	Type       string  `json:"type,omitempty"`
+	ValueInUSD float64 `json:"valueInUSD"`
+	RedeemedBy string  `json:"redeemedBy,omitempty"`
+	SubAccount string  `json:"subAccount,omitempty"`
+}
+
+type WalletEvent struct {
+	WalletID   string  `json:"walletId"`
+	Type       string  `json:"type,omitempty"`
+	Amount     float64 `json:"amount"`
+	Currency   string  `json:"currency,omitempty"`
+	Balance    float64 `json:"balance,omitempty"`
+	Reference  string  `json:"reference,omitempty"`
+	SubAccount string  `json:"subAccount,omitempty"`
+}
+
+/**
+ * This function decodes the data of a payout.completed or payout.failed event
+ * @returns The payout the event is about
+ */
+func (e *Event) Payout() (*PayoutEvent, error) {
+	v := new(PayoutEvent)
+	return v, e.decode(v, PayoutCompleted, PayoutFailed)
+}
+
+/**
+ * This function decodes the data of a collection.successful event
+ * @returns The collection the event is about
+ */
+func (e *Event) Collection() (*CollectionEvent, error) {
+	v := new(CollectionEvent)
+	return v, e.decode(v, CollectionSuccessful)
+}
+
+/**
+ * This function decodes the data of a redeem.completed event
+ * @returns The redemption the event is about
+ */
+func (e *Event) Redeem() (*RedeemEvent, error) {
+	v := new(RedeemEvent)
+	return v, e.decode(v, RedeemCompleted)
+}
+
+/**
+ * This function decodes the data of a wallet.credited event
+ * @returns The wallet credit the event is about
+ */
+func (e *Event) Wallet() (*WalletEvent, error) {
+	v := new(WalletEvent)
+	return v, e.decode(v, WalletCredited)
+}
+
+func (e *Event) decode(v interface{}, types ...EventType) error {
+	match := false
+	for _, t := range types {
+		match = match || e.Type == t
+	}
+	if !match {
+		return fmt.Errorf("webhooks: %s event has no %v data", e.Type, types)
+	}
+	if len(e.Data) == 0 {
+		return fmt.Errorf("webhooks: %s event has no data", e.Type)
+	}
+	if err := json.Unmarshal(e.Data, v); err != nil {
+		return fmt.Errorf("webhooks: invalid %s data: %v", e.Type, err)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrNoStore  = errors.New("webhook handler has no event store")
+	ErrInFlight = errors.New("webhook event is already being handled")
+)
+
+// errChanged reports an event whose status changed since it was listed.
+var errChanged = errors.New("webhooks: stored event changed")
+
+type ReplayResult struct {
+	Processed []string
+	Failed    []string
+	// DeadLettered lists the failed events that ran out of attempts.
+	DeadLettered []string
+	// Skipped lists the events another worker was handling or had handled.
+	Skipped []string
+}
+
+// process leases a stored event still matching f, handles it and checkpoints
+// the outcome. It fails with ErrInFlight while another worker holds the lease.
+// Handler errors are returned after the record is updated.
+func (h *Handler) process(ctx context.Context, key string, f Filter) (Record, error) {
+	now := h.now()
+	rec, err := h.store.Update(key, func(r *Record) error {
+		switch {
+		case r.LeasedUntil.After(now):
+			return ErrInFlight
+		case !f.match(*r):
+			return errChanged
+		}
+		r.LeasedUntil = now.Add(h.lease)
+		if r.Status == StatusDead {
+			// A replay gives dead letters a fresh set of attempts.
+			r.Attempts = 0
+		}
+		return nil
+	})
+	if err != nil {
+		return rec, err
+	}
+
+	var e Event
+	if err = json.Unmarshal(rec.Payload, &e); err != nil {
+		err = fmt.Errorf("webhooks: stored event %s is invalid: %v", key, err)
+	} else {
+		err = h.dispatch(ctx, &e)
+	}
+
+	rec, uerr := h.store.Update(key, func(r *Record) error {
+		r.Attempts++
+		r.LeasedUntil = time.Time{}
+		if err == nil {
+			r.Status = StatusProcessed
+			r.ProcessedAt = h.now()
+			r.LastError = ""
+		} else {
+			r.LastError = err.Error()
+			if r.Attempts >= h.maxAttempts {
+				r.Status = StatusDead
+			} else {
+				r.Status = StatusPending
+			}
+		}
+		return nil
+	})
+	if uerr != nil {
+		return rec, uerr
+	}
+	return rec, err
+}
+
+/**
+ * This function handles stored events again, whatever their status, e.g. after a handler bug fix
+ * @param {Filter} f The events to replay, by received time, type and status
+ * @returns The keys of the events by outcome
+ */
+func (h *Handler) Replay(ctx context.Context, f Filter) (*ReplayResult, error) {
+	if h.store == nil {
+		return nil, ErrNoStore
+	}
+	records, err := h.store.List(f)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReplayResult{}
+	for _, listed := range records {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		rec, err := h.process(ctx, listed.Key, f)
+		switch {
+		case err == nil:
+			result.Processed = append(result.Processed, rec.Key)
+		case errors.Is(err, ErrInFlight) || errors.Is(err, errChanged):
+			result.Skipped = append(result.Skipped, rec.Key)
+		case rec.Status == StatusDead:
+			result.DeadLettered = append(result.DeadLettered, rec.Key)
+		default:
+			result.Failed = append(result.Failed, rec.Key)
+		}
+	}
+	return result, nil
+}
+
+/**
+ * This function retries the stored events whose handler has not succeeded yet, oldest first
+ * @returns The keys of the events by outcome
+ */
+func (h *Handler) ProcessPending(ctx context.Context) (*ReplayResult, error) {
+	return h.Replay(ctx, Filter{Statuses: []RecordStatus{StatusPending}})
+}
+
+/**
+ * This function removes handled and dead events from the store, such as those received before a
+ * retention period. A removed event is handled again if it is delivered again.
+ * @param {Filter} f The events to remove, by received time, type and status; pending events are kept
+ * @returns The number of events removed
+ */
+func (h *Handler) Prune(f Filter) (int, error) {
+	if h.store == nil {
+		return 0, ErrNoStore
+	}
+	return h.store.Prune(f)
+}
+
+/**
+ * This function lists the dead-letter queue
+ * @returns The events whose handler kept failing, oldest first
+ */
+func (h *Handler) DeadLetters() ([]Record, error) {
+	if h.store == nil {
+		return nil, ErrNoStore
+	}
+	return h.store.List(Filter{Statuses: []RecordStatus{StatusDead}})
+}
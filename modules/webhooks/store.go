@@ -0,0 +1,348 @@
+package webhooks
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type RecordStatus string
+
+const (
+	StatusPending   RecordStatus = "pending"
+	StatusProcessed RecordStatus = "processed"
+	// StatusDead marks an event whose handler failed MaxAttempts times.
+	StatusDead RecordStatus = "dead"
+)
+
+// Record is a stored event. Payload is the body exactly as delivered; its
+// status is the checkpoint of at-least-once processing.
+type Record struct {
+	Key         string       `json:"key"`
+	EventID     string       `json:"eventId,omitempty"`
+	Type        EventType    `json:"type"`
+	Payload     []byte       `json:"payload"`
+	ReceivedAt  time.Time    `json:"receivedAt"`
+	Deliveries  int          `json:"deliveries"`
+	Status      RecordStatus `json:"status"`
+	Attempts    int          `json:"attempts"`
+	LastError   string       `json:"lastError,omitempty"`
+	ProcessedAt time.Time    `json:"processedAt,omitempty"`
+	// LeasedUntil reserves the event for the worker handling it.
+	LeasedUntil time.Time `json:"leasedUntil,omitempty"`
+}
+
+// Filter selects stored records. Zero values match everything; To is exclusive.
+type Filter struct {
+	From     time.Time
+	To       time.Time
+	Types    []EventType
+	Statuses []RecordStatus
+}
+
+func (f Filter) match(r Record) bool {
+	if !f.From.IsZero() && r.ReceivedAt.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !r.ReceivedAt.Before(f.To) {
+		return false
+	}
+	if len(f.Types) > 0 {
+		found := false
+		for _, t := range f.Types {
+			found = found || t == r.Type
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(f.Statuses) > 0 {
+		found := false
+		for _, s := range f.Statuses {
+			found = found || s == r.Status
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Store persists webhook events by dedup key.
+type Store interface {
+	// Insert stores a new record. When the key is already stored, it counts
+	// the delivery and returns the stored record with false.
+	Insert(r Record) (Record, bool, error)
+	// Update changes a stored record with fn under the store's lock, so that
+	// concurrent changes are not lost. An error from fn leaves it unchanged
+	// and is returned with the stored record.
+	Update(key string, fn func(r *Record) error) (Record, error)
+	// List returns the matching records, oldest first.
+	List(f Filter) ([]Record, error)
+	// Prune removes the matching records that are not pending and returns
+	// how many were removed.
+	Prune(f Filter) (int, error)
+}
+
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]Record
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]Record)}
+}
+
+func (s *MemoryStore) Insert(r Record) (Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stored, ok := s.records[r.Key]; ok {
+		stored.Deliveries++
+		s.records[r.Key] = stored
+		return stored, false, nil
+	}
+	s.records[r.Key] = r
+	return r, true, nil
+}
+
+func (s *MemoryStore) Update(key string, fn func(r *Record) error) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.records[key]
+	if !ok {
+		return Record{}, fmt.Errorf("webhooks: no stored event %s", key)
+	}
+	r := stored
+	if err := fn(&r); err != nil {
+		return stored, err
+	}
+	s.records[key] = r
+	return r, nil
+}
+
+func (s *MemoryStore) List(f Filter) ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []Record
+	for _, r := range s.records {
+		if f.match(r) {
+			out = append(out, r)
+		}
+	}
+	sortRecords(out)
+	return out, nil
+}
+
+func (s *MemoryStore) Prune(f Filter) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for key, r := range s.records {
+		if r.Status != StatusPending && f.match(r) {
+			delete(s.records, key)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// FileStore keeps one JSON file per event in a directory, named after the
+// hash of its dedup key. Files are replaced atomically on every change. List
+// and Prune skip files that cannot be read as a record, renaming them with a
+// .corrupt suffix; Corrupt lists them.
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) Insert(r Record) (Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.read(s.path(r.Key))
+	if errors.Is(err, os.ErrNotExist) {
+		return r, true, s.write(r)
+	}
+	if err != nil {
+		return Record{}, false, err
+	}
+	stored.Deliveries++
+	return stored, false, s.write(stored)
+}
+
+func (s *FileStore) Update(key string, fn func(r *Record) error) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.read(s.path(key))
+	if err != nil {
+		return Record{}, fmt.Errorf("webhooks: no stored event %s: %v", key, err)
+	}
+	r := stored
+	if err := fn(&r); err != nil {
+		return stored, err
+	}
+	return r, s.write(r)
+}
+
+func (s *FileStore) List(f Filter) ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []Record
+	err := s.each(func(name string, r Record) error {
+		if f.match(r) {
+			out = append(out, r)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortRecords(out)
+	return out, nil
+}
+
+func (s *FileStore) Prune(f Filter) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	err := s.each(func(name string, r Record) error {
+		if r.Status == StatusPending || !f.match(r) {
+			return nil
+		}
+		if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		n++
+		return nil
+	})
+	return n, err
+}
+
+/**
+ * This function lists the event files that could not be read and were set aside
+ * @returns The paths of the files, renamed with a .corrupt suffix
+ */
+func (s *FileStore) Corrupt() ([]string, error) {
+	names, err := filepath.Glob(filepath.Join(s.dir, "*.json.corrupt"))
+	sort.Strings(names)
+	return names, err
+}
+
+// each calls fn with every readable record, setting corrupt files aside.
+func (s *FileStore) each(fn func(name string, r Record) error) error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		name := filepath.Join(s.dir, e.Name())
+		r, err := s.read(name)
+		var corrupt *corruptError
+		switch {
+		case errors.As(err, &corrupt):
+			if err := os.Rename(name, name+".corrupt"); err != nil {
+				return err
+			}
+			continue
+		case errors.Is(err, os.ErrNotExist):
+			continue
+		case err != nil:
+			return err
+		}
+		if err := fn(name, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *FileStore) read(name string) (Record, error) {
+	var r Record
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return r, err
+	}
+	if err := json.Unmarshal(data, &r); err != nil {
+		return r, &corruptError{name: filepath.Base(name), err: err}
+	}
+	return r, nil
+}
+
+type corruptError struct {
+	name string
+	err  error
+}
+
+func (e *corruptError) Error() string {
+	return fmt.Sprintf("webhooks: corrupt event file %s: %v", e.name, e.err)
+}
+
+func (s *FileStore) write(r Record) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".event-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(r.Key))
+}
+
+func (s *FileStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func sortRecords(records []Record) {
+	sort.Slice(records, func(i, j int) bool {
+		if !records[i].ReceivedAt.Equal(records[j].ReceivedAt) {
+			return records[i].ReceivedAt.Before(records[j].ReceivedAt)
+		}
+		return records[i].Key < records[j].Key
+	})
+}
+
+// dedupKey identifies an event across deliveries: its ID, or the hash of the
+// payload for events without one.
+func dedupKey(e *Event, payload []byte) string {
+	if e.ID != "" {
+		return "id:" + e.ID
+	}
+	sum := sha256.Sum256(payload)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,349 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Chimoney-Signature"
+	TimestampHeader = "X-Chimoney-Timestamp"
+)
+
+var (
+	ErrMissingSignature = errors.New("webhook signature or timestamp missing")
+	ErrInvalidSignature = errors.New("webhook signature does not match")
+	ErrStaleTimestamp   = errors.New("webhook timestamp outside tolerance")
+	ErrReplay           = errors.New("webhook already received")
+)
+
+// HandlerFunc processes one event. Returning an error answers the delivery
+// with a 500, so Chimoney retries it.
+type HandlerFunc func(ctx context.Context, e *Event) error
+
+type Handler struct {
+	secret    []byte
+	tolerance time.Duration
+	maxBody   int64
+	now       func() time.Time
+
+	store       Store
+	maxAttempts int
+	lease       time.Duration
+
+	mu       sync.Mutex
+	seen     map[string]delivery
+	handlers map[EventType]HandlerFunc
+	fallback HandlerFunc
+}
+
+type Option func(*Handler)
+
+// WithTolerance sets how far the signed timestamp may be from the current
+// time, 5 minutes by default.
+func WithTolerance(d time.Duration) Option {
+	return func(h *Handler) {
+		h.tolerance = d
+	}
+}
+
+func WithMaxBodySize(n int64) Option {
+	return func(h *Handler) {
+		h.maxBody = n
+	}
+}
+
+// WithStore persists every event before it is handled, so that duplicates
+// are acknowledged without being handled twice and failures can be replayed.
+// An event is leased to one worker at a time, so its handler never runs
+// concurrently for the same event.
+func WithStore(s Store) Option {
+	return func(h *Handler) {
+		h.store = s
+	}
+}
+
+// WithMaxAttempts sets how many times a stored event is handled before it is
+// moved to the dead-letter queue, 5 by default.
+func WithMaxAttempts(n int) Option {
+	return func(h *Handler) {
+		h.maxAttempts = n
+	}
+}
+
+// WithLease sets how long a stored event is reserved for the worker handling
+// it, 5 minutes by default. Should the worker crash, the event can be handled
+// again once the lease expires.
+func WithLease(d time.Duration) Option {
+	return func(h *Handler) {
+		h.lease = d
+	}
+}
+
+func WithClock(now func() time.Time) Option {
+	return func(h *Handler) {
+		h.now = now
+	}
+}
+
+/**
+ * This function creates a webhook receiver. It stores events only when given
+ * WithStore, as it has no directory of its own to write to; Open is the
+ * constructor with the default, file-based store.
+ * @param {string} secret The webhook signing secret from the Chimoney dashboard
+ * @returns An http.Handler that verifies deliveries and dispatches them by event type
+ */
+func New(secret string, opts ...Option) *Handler {
+	h := &Handler{
+		secret:      []byte(secret),
+		tolerance:   5 * time.Minute,
+		maxBody:     1 << 20,
+		now:         time.Now,
+		maxAttempts: 5,
+		lease:       5 * time.Minute,
+		seen:        make(map[string]delivery),
+		handlers:    make(map[EventType]HandlerFunc),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+/**
+ * This function creates a webhook receiver that stores events in a directory
+ * @param {string} dir The directory of the event files, created if missing
+ * @param {string} secret The webhook signing secret from the Chimoney dashboard
+ * @returns A receiver backed by a FileStore
+ */
+func Open(dir, secret string, opts ...Option) (*Handler, error) {
+	store, err := NewFileStore(dir)
+	if err != nil {
+		return nil, err
+	}
+	return New(secret, append([]Option{WithStore(store)}, opts...)...), nil
+}
+
+/**
+ * This function registers the handler of an event type, replacing any previous one
+ * @param {EventType} t The event type
+ * @param {HandlerFunc} fn The handler
+ */
+func (h *Handler) Handle(t EventType, fn HandlerFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.handlers[t] = fn
+}
+
+/**
+ * This function registers the handler of event types without their own handler
+ * @param {HandlerFunc} fn The handler; without one, such events are acknowledged and dropped
+ */
+func (h *Handler) HandleDefault(fn HandlerFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.fallback = fn
+}
+
+/**
+ * This function signs a payload the way Chimoney does
+ * @param {string} secret The webhook signing secret
+ * @param {string} timestamp The Unix timestamp sent in the timestamp header
+ * @param {[]byte} payload The request body
+ * @returns The hex encoded HMAC-SHA256 of the timestamp, a dot and the payload
+ */
+func Sign(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+/**
+ * This function checks the signature and timestamp of a delivery
+ * @param {[]byte} payload The request body
+ * @param {http.Header} header The request headers
+ */
+func (h *Handler) Verify(payload []byte, header http.Header) error {
+	timestamp := header.Get(TimestampHeader)
+	signature := strings.TrimPrefix(header.Get(SignatureHeader), "sha256=")
+	if timestamp == "" || signature == "" {
+		return ErrMissingSignature
+	}
+
+	secs, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: %q", ErrStaleTimestamp, timestamp)
+	}
+	if age := h.now().Sub(time.Unix(secs, 0)); age > h.tolerance || age < -h.tolerance {
+		return fmt.Errorf("%w: signed %s ago", ErrStaleTimestamp, age.Round(time.Second))
+	}
+
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	want, _ := hex.DecodeString(Sign(string(h.secret), timestamp, payload))
+	if !hmac.Equal(got, want) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	payload, err := io.ReadAll(io.LimitReader(r.Body, h.maxBody+1))
+	if err != nil {
+		http.Error(w, "could not read body", http.StatusBadRequest)
+		return
+	}
+	if int64(len(payload)) > h.maxBody {
+		http.Error(w, "body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if err := h.Verify(payload, r.Header); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	var e Event
+	if err := json.Unmarshal(payload, &e); err != nil || e.Type == "" {
+		http.Error(w, "invalid event", http.StatusBadRequest)
+		return
+	}
+
+	// A retry is signed again, so a signature seen twice is a replay. The key
+	// is the decoded MAC, as the hex of a replay may differ in case.
+	mac, _ := hex.DecodeString(strings.TrimPrefix(r.Header.Get(SignatureHeader), "sha256="))
+	key := string(mac)
+	if claimed, done := h.claim(key); !claimed {
+		if done {
+			// Handled already; our answer was probably lost, so acknowledge it.
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		http.Error(w, ErrReplay.Error(), http.StatusConflict)
+		return
+	}
+
+	if h.store == nil {
+		if err := h.dispatch(r.Context(), &e); err != nil {
+			h.release(key)
+			http.Error(w, "event handler failed", http.StatusInternalServerError)
+			return
+		}
+		h.finish(key)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	rec, inserted, err := h.store.Insert(Record{
+		Key:        dedupKey(&e, payload),
+		EventID:    e.ID,
+		Type:       e.Type,
+		Payload:    payload,
+		ReceivedAt: h.now(),
+		Deliveries: 1,
+		Status:     StatusPending,
+	})
+	if err != nil {
+		h.release(key)
+		http.Error(w, "could not store event", http.StatusInternalServerError)
+		return
+	}
+	if !inserted && rec.Status != StatusPending {
+		// Already handled or dead-lettered; acknowledge so it is not sent again.
+		h.finish(key)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	rec, err = h.process(r.Context(), rec.Key, Filter{Statuses: []RecordStatus{StatusPending}})
+	switch {
+	case errors.Is(err, ErrInFlight):
+		// Answer with a conflict so the delivery is retried should the
+		// worker handling it fail.
+		h.release(key)
+		http.Error(w, ErrInFlight.Error(), http.StatusConflict)
+	case err != nil && !errors.Is(err, errChanged) && rec.Status != StatusDead:
+		h.release(key)
+		http.Error(w, "event handler failed", http.StatusInternalServerError)
+	default:
+		h.finish(key)
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func (h *Handler) dispatch(ctx context.Context, e *Event) error {
+	h.mu.Lock()
+	fn, ok := h.handlers[e.Type]
+	if !ok {
+		fn = h.fallback
+	}
+	h.mu.Unlock()
+
+	if fn == nil {
+		return nil
+	}
+	return fn(ctx, e)
+}
+
+// claim records a signature, forgetting those older than the tolerance since
+// their timestamps can no longer pass verification.
+// delivery is a signature being handled, or handled when done.
+type delivery struct {
+	at   time.Time
+	done bool
+}
+
+// claim takes a signature for handling. It fails for a signature already
+// taken, reporting whether that delivery was handled.
+func (h *Handler) claim(key string) (claimed, done bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.now()
+	for k, d := range h.seen {
+		if now.Sub(d.at) > 2*h.tolerance {
+			delete(h.seen, k)
+		}
+	}
+	if d, ok := h.seen[key]; ok {
+		return false, d.done
+	}
+	h.seen[key] = delivery{at: now}
+	return true, false
+}
+
+func (h *Handler) finish(key string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if d, ok := h.seen[key]; ok {
+		d.done = true
+		h.seen[key] = d
+	}
+}
+
+func (h *Handler) release(key string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.seen, key)
+}
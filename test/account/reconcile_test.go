@@ -0,0 +1,212 @@
+package account_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/chimoney/chimoney-go/modules/account/reconcile"
+)
+
+func TestReconcile(t *testing.T) {
+	transactions := map[string]string{
+		"": `[
+			{"id":"t1","chiRef":"chi_1","valueInUSD":10,"status":"paid"},
+			{"id":"t2","chiRef":"chi_2","valueInUSD":25,"status":"paid"},
+			{"id":"t3","chiRef":"chi_3","valueInUSD":5,"status":"pending"},
+			{"id":"t9","chiRef":"chi_9","valueInUSD":99,"status":"paid"}
+		]`,
+		"sub_1": `{"transactions":[{"id":"t4","chiRef":"chi_4","valueInUSD":7,"status":"paid","meta":{"reference":"ref_4"}}]}`,
+	}
+	issueCalls := 0
+	server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		var reqBody map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			t.Errorf("failed to decode request body: %v", err)
+		}
+
+		switch r.URL.Path {
+		case "/accounts/transactions":
+			data, ok := transactions[reqBody["subAccount"]]
+			if !ok {
+				data = "[]"
+			}
+			w.Write([]byte(`{"status":"success","data":` + data + `}`))
+		case "/accounts/issue-id-transactions":
+			issueCalls++
+			if got := r.URL.Query().Get("issueID"); got != "issue_5" {
+				t.Errorf("unexpected issueID: got %v want issue_5", got)
+			}
+			w.Write([]byte(`{"status":"success","data":[{"id":"t5","chiRef":"chi_5","issueID":"issue_5","valueInUSD":3,"status":"paid"}]}`))
+		default:
+			t.Errorf("unexpected path: %v", r.URL.Path)
+		}
+	})
+	defer server.Close()
+
+	ledger := []reconcile.LedgerEntry{
+		{ChiRef: "chi_1", AmountUSD: 10, Status: "paid"},
+		{ChiRef: "chi_2", AmountUSD: 20},
+		{ChiRef: "chi_3", AmountUSD: 5, Status: "paid"},
+		{Reference: "ref_4", AmountUSD: 7, SubAccount: "sub_1"},
+		{ChiRef: "chi_5", IssueID: "issue_5", AmountUSD: 3},
+		{ChiRef: "chi_6", AmountUSD: 1},
+	}
+
+	report, err := reconcile.New(client.Account).Reconcile(context.Background(), ledger)
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	counts := map[string]int{
+		"matched":    len(report.Matched),
+		"missing":    len(report.Missing),
+		"unexpected": len(report.Unexpected),
+		"amount":     len(report.AmountMismatches),
+		"status":     len(report.StatusMismatches),
+	}
+	want := map[string]int{"matched": 3, "missing": 1, "unexpected": 1, "amount": 1, "status": 1}
+	for k, v := range want {
+		if counts[k] != v {
+			t.Errorf("unexpected %s count: got %d want %d", k, counts[k], v)
+		}
+	}
+	if issueCalls != 1 {
+		t.Errorf("unexpected issue lookups: got %d want 1", issueCalls)
+	}
+	if report.Missing[0].Ledger.ChiRef != "chi_6" {
+		t.Errorf("unexpected missing record: %+v", report.Missing[0].Ledger)
+	}
+	if report.Unexpected[0].Transaction.ChiRef != "chi_9" {
+		t.Errorf("unexpected unexpected record: %+v", report.Unexpected[0].Transaction)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	if len(rows) != 8 {
+		t.Errorf("unexpected csv rows: got %d want 8", len(rows))
+	}
+
+	buf.Reset()
+	if err := report.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	var decoded reconcile.Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("failed to decode json report: %v", err)
+	}
+	if len(decoded.Matched) != 3 {
+		t.Errorf("unexpected matched records in json: %d", len(decoded.Matched))
+	}
+}
+
+func TestReconcileMatching(t *testing.T) {
+	// Three pages of two: repeated references, and unreferenced payouts.
+	pages := []string{
+		`[{"id":"t1","chiRef":"chi_1","valueInUSD":10,"status":"paid","meta":{"reference":"inv_7"}},
+		  {"id":"t2","chiRef":"chi_2","valueInUSD":10,"status":"paid","meta":{"reference":"inv_7"}}]`,
+		`[{"id":"t3","valueInUSD":15,"status":"paid","paymentDate":"2026-03-01T10:00:00Z"},
+		  {"id":"t4","valueInUSD":15,"status":"paid","paymentDate":"2026-03-04T10:00:00Z"}]`,
+		`[{"id":"t5","valueInUSD":40,"status":"paid"}]`,
+	}
+	requested := []string{}
+	server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/accounts/transactions" {
+			t.Errorf("unexpected path: %v", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("limit"); got != "2" {
+			t.Errorf("unexpected limit: got %v want 2", got)
+		}
+		page := r.URL.Query().Get("page")
+		requested = append(requested, page)
+		data := "[]"
+		switch page {
+		case "1":
+			data = pages[0]
+		case "2":
+			data = pages[1]
+		case "3":
+			data = pages[2]
+		}
+		w.Write([]byte(`{"status":"success","data":` + data + `}`))
+	})
+	defer server.Close()
+
+	ledger := []reconcile.LedgerEntry{
+		{Reference: "inv_7", AmountUSD: 10},
+		{Reference: "inv_7", AmountUSD: 10},
+		{AmountUSD: 15, Date: time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)},
+		{AmountUSD: 15, Date: time.Date(2026, 3, 20, 9, 0, 0, 0, time.UTC)},
+	}
+
+	report, err := reconcile.New(client.Account, reconcile.WithPageSize(2)).Reconcile(context.Background(), ledger)
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if !reflect.DeepEqual(requested, []string{"1", "2", "3"}) {
+		t.Errorf("unexpected pages requested: %v", requested)
+	}
+
+	if len(report.Matched) != 3 {
+		t.Fatalf("unexpected matched records: %+v", report.Matched)
+	}
+	if report.Matched[0].Transaction.ID != "t1" || report.Matched[1].Transaction.ID != "t2" {
+		t.Errorf("repeated references were not paired in order: %+v", report.Matched[:2])
+	}
+	if m := report.Matched[2]; m.Transaction.ID != "t4" || m.Detail != "matched by amount" {
+		t.Errorf("unexpected amount match: %+v", m)
+	}
+
+	// The second unreferenced payout is outside the date window of t3.
+	if len(report.Missing) != 1 || report.Missing[0].Ledger != &ledger[3] {
+		t.Errorf("unexpected missing records: %+v", report.Missing)
+	}
+	var unexpected []string
+	for _, rec := range report.Unexpected {
+		unexpected = append(unexpected, rec.Transaction.ID)
+	}
+	if !reflect.DeepEqual(unexpected, []string{"t3", "t5"}) {
+		t.Errorf("unexpected unexpected records: %v", unexpected)
+	}
+}
+
+func TestReconcilePeriod(t *testing.T) {
+	server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"success","data":[
+			{"id":"t1","chiRef":"chi_1","valueInUSD":10,"status":"paid","paymentDate":"2026-03-01T23:00:00Z"},
+			{"id":"t2","chiRef":"chi_2","valueInUSD":20,"status":"paid","paymentDate":"2026-03-02T08:00:00Z"},
+			{"id":"t3","chiRef":"chi_3","valueInUSD":30,"status":"paid","paymentDate":"2026-03-03T00:00:00Z"},
+			{"id":"t4","chiRef":"chi_4","valueInUSD":40,"status":"paid"}
+		]}`))
+	})
+	defer server.Close()
+
+	from := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	r := reconcile.New(client.Account, reconcile.WithPeriod(from, from.Add(24*time.Hour)))
+	report, err := r.Reconcile(context.Background(), []reconcile.LedgerEntry{{ChiRef: "chi_1", AmountUSD: 10}})
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	// Entries still match transactions paid before the period.
+	if len(report.Matched) != 1 || report.Matched[0].Transaction.ID != "t1" {
+		t.Errorf("unexpected matched records: %+v", report.Matched)
+	}
+	if len(report.Unexpected) != 1 || report.Unexpected[0].Transaction.ID != "t2" {
+		t.Errorf("unexpected unexpected records: %+v", report.Unexpected)
+	}
+}
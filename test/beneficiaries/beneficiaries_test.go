@@ -0,0 +1,308 @@
+package beneficiaries_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/chimoney/chimoney-go/modules/beneficiaries"
+	"github.com/chimoney/chimoney-go/modules/info"
+	"github.com/chimoney/chimoney-go/test/testclient"
+)
+
+func setupTestServer(t *testing.T, handler http.HandlerFunc) (*httptest.Server, *testclient.Client) {
+	server := httptest.NewServer(handler)
+	client := testclient.New(
+		testclient.WithTestServer(server.URL),
+		testclient.WithAPIKey("test-api-key"),
+	)
+	return server, client
+}
+
+func infoHandler(t *testing.T, calls map[string]int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		calls[r.URL.Path]++
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/info/country-banks":
+			w.Write([]byte(`{"status":"success","data":[{"code":"044","name":"Access Bank"},{"code":"058","name":"GTBank"}]}`))
+		case "/info/mobile-money-codes":
+			w.Write([]byte(`{"status":"success","data":[{"code":"MTN","name":"MTN","country":"GH"}]}`))
+		default:
+			t.Errorf("unexpected path: %v", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+}
+
+func TestBookValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		beneficiary beneficiaries.Beneficiary
+		wantErr     bool
+	}{
+		{
+			name: "valid bank and mobile money",
+			beneficiary: beneficiaries.Beneficiary{
+				ID:   "acme",
+				Name: "Acme Supplies",
+				Methods: []beneficiaries.Method{
+					{Type: beneficiaries.MethodBank, Country: "NG", BankCode: "044", AccountNumber: "0123456789"},
+					{Type: beneficiaries.MethodMobileMoney, Country: "GH", PhoneNumber: "+233241234567", MobileMoneyCode: "mtn"},
+				},
+			},
+		},
+		{
+			name: "unsupported bank",
+			beneficiary: beneficiaries.Beneficiary{
+				ID: "acme", Name: "Acme",
+				Methods: []beneficiaries.Method{{Type: beneficiaries.MethodBank, Country: "NG", BankCode: "999", AccountNumber: "0123456789"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "provider in wrong country",
+			beneficiary: beneficiaries.Beneficiary{
+				ID: "acme", Name: "Acme",
+				Methods: []beneficiaries.Method{{Type: beneficiaries.MethodMobileMoney, Country: "KE", PhoneNumber: "+254712345678", MobileMoneyCode: "MTN"}},
+			},
+			wantErr: true,
+		},
+		{
+			name:        "no methods",
+			beneficiary: beneficiaries.Beneficiary{ID: "acme", Name: "Acme"},
+			wantErr:     true,
+		},
+		{
+			name: "unsafe ID",
+			beneficiary: beneficiaries.Beneficiary{
+				ID: "../acme", Name: "Acme",
+				Methods: []beneficiaries.Method{{Type: beneficiaries.MethodChimoney, Email: "ap@acme.test"}},
+			},
+			wantErr: true,
+		},
+	}
+
+	calls := map[string]int{}
+	server, client := setupTestServer(t, infoHandler(t, calls))
+	defer server.Close()
+	book := beneficiaries.New(client.Info, beneficiaries.NewMemoryStore())
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := book.Validate(context.Background(), tt.beneficiary)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, beneficiaries.ErrInvalidBeneficiary) {
+				t.Errorf("Validate() error = %v, want %v", err, beneficiaries.ErrInvalidBeneficiary)
+			}
+		})
+	}
+
+	if calls["/info/country-banks"] != 1 || calls["/info/mobile-money-codes"] != 1 {
+		t.Errorf("capabilities were not cached: %v", calls)
+	}
+}
+
+func TestBookVersions(t *testing.T) {
+	calls := map[string]int{}
+	server, client := setupTestServer(t, infoHandler(t, calls))
+	defer server.Close()
+
+	dir := t.TempDir()
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	book, err := beneficiaries.Open(dir, client.Info, beneficiaries.WithClock(func() time.Time { return now }))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	ctx := context.Background()
+	acme := beneficiaries.Beneficiary{
+		ID:      "acme",
+		Name:    "Acme Supplies",
+		Methods: []beneficiaries.Method{{Type: beneficiaries.MethodBank, Country: "NG", BankCode: "044", AccountNumber: "0123456789"}},
+	}
+
+	saved, err := book.Save(ctx, acme)
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if saved.Version != 1 || !saved.UpdatedAt.Equal(now) {
+		t.Errorf("unexpected first version: %+v", saved)
+	}
+
+	// Saving the same details again does not add a version.
+	acme.Version = saved.Version
+	if saved, _ = book.Save(ctx, acme); saved.Version != 1 {
+		t.Errorf("unchanged save added version %d", saved.Version)
+	}
+
+	now = now.Add(24 * time.Hour)
+	stale := acme
+	stale.Methods = []beneficiaries.Method{{Type: beneficiaries.MethodChimoney, Email: "ap@acme.test"}}
+	acme.Methods[0].BankCode = "058"
+	acme.Methods[0].AccountNumber = "9876543210"
+	if saved, err = book.Save(ctx, acme); err != nil || saved.Version != 2 {
+		t.Fatalf("Save() = %+v, %v", saved, err)
+	}
+
+	// Changes made to an older version are rejected.
+	if _, err := book.Save(ctx, stale); !errors.Is(err, beneficiaries.ErrVersionConflict) {
+		t.Errorf("Save() error = %v, want %v", err, beneficiaries.ErrVersionConflict)
+	}
+	fresh := acme
+	fresh.ID = "globex"
+	fresh.Version = 3
+	if _, err := book.Save(ctx, fresh); !errors.Is(err, beneficiaries.ErrVersionConflict) {
+		t.Errorf("Save() error = %v, want %v", err, beneficiaries.ErrVersionConflict)
+	}
+
+	// A second book on the same directory sees the history.
+	reopened, err := beneficiaries.Open(dir, client.Info)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	history, err := reopened.History("acme")
+	if err != nil || len(history) != 2 {
+		t.Fatalf("History() = %+v, %v", history, err)
+	}
+	if history[0].Methods[0].BankCode != "044" || history[1].Methods[0].BankCode != "058" {
+		t.Errorf("unexpected history: %+v", history)
+	}
+
+	changes, err := reopened.Changes("acme")
+	if err != nil {
+		t.Fatalf("Changes() error = %v", err)
+	}
+	want := []string{"methods[0].bankCode", "methods[0].accountNumber"}
+	if len(changes) != 1 || changes[0].Version != 2 || !changes[0].UpdatedAt.Equal(now) || !reflect.DeepEqual(changes[0].Fields, want) {
+		t.Errorf("unexpected changes: %+v", changes)
+	}
+
+	list, err := reopened.List()
+	if err != nil || len(list) != 1 || list[0].Version != 2 {
+		t.Errorf("List() = %+v, %v", list, err)
+	}
+
+	// A stale writer cannot overwrite a newer version.
+	store, _ := beneficiaries.NewFileStore(dir)
+	old := history[0]
+	old.Version = 2
+	if err := store.Append(old); !errors.Is(err, beneficiaries.ErrVersionConflict) {
+		t.Errorf("Append() error = %v, want %v", err, beneficiaries.ErrVersionConflict)
+	}
+
+	// Deleting keeps the history and records the deletion as a version.
+	now = now.Add(24 * time.Hour)
+	if err := book.Delete("acme"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := reopened.Get("acme"); !errors.Is(err, beneficiaries.ErrNotFound) {
+		t.Errorf("Get() error = %v, want %v", err, beneficiaries.ErrNotFound)
+	}
+	if err := reopened.Delete("acme"); !errors.Is(err, beneficiaries.ErrNotFound) {
+		t.Errorf("second Delete() error = %v, want %v", err, beneficiaries.ErrNotFound)
+	}
+	if list, err := reopened.List(); err != nil || len(list) != 0 {
+		t.Errorf("List() after delete = %+v, %v", list, err)
+	}
+	history, err = reopened.History("acme")
+	if err != nil || len(history) != 3 || !history[2].Deleted || !history[2].UpdatedAt.Equal(now) {
+		t.Fatalf("History() after delete = %+v, %v", history, err)
+	}
+	if changes, err := reopened.Changes("acme"); err != nil || !reflect.DeepEqual(changes[1].Fields, []string{"deleted"}) {
+		t.Errorf("Changes() after delete = %+v, %v", changes, err)
+	}
+
+	// The beneficiary can be added again and continues its history.
+	acme.Version = 0
+	if saved, err = reopened.Save(ctx, acme); err != nil || saved.Version != 4 || saved.Deleted {
+		t.Fatalf("Save() after delete = %+v, %v", saved, err)
+	}
+}
+
+// emptyStore has a beneficiary without versions, as a store being written
+// to by another process can.
+type emptyStore struct {
+	beneficiaries.Store
+}
+
+func (emptyStore) Versions(id string) ([]beneficiaries.Beneficiary, error) {
+	return nil, nil
+}
+
+func (emptyStore) IDs() ([]string, error) {
+	return []string{"acme"}, nil
+}
+
+func TestBookEmptyVersions(t *testing.T) {
+	book := beneficiaries.New(nil, emptyStore{})
+
+	if _, err := book.Get("acme"); !errors.Is(err, beneficiaries.ErrNotFound) {
+		t.Errorf("Get() error = %v, want %v", err, beneficiaries.ErrNotFound)
+	}
+	if _, err := book.List(); !errors.Is(err, beneficiaries.ErrNotFound) {
+		t.Errorf("List() error = %v, want %v", err, beneficiaries.ErrNotFound)
+	}
+}
+
+func TestBookSharedInfoCache(t *testing.T) {
+	calls := map[string]int{}
+	server, client := setupTestServer(t, infoHandler(t, calls))
+	defer server.Close()
+
+	cache := info.NewCache(client.Info)
+	if _, err := cache.Banks(context.Background(), "NG"); err != nil {
+		t.Fatalf("Banks() error = %v", err)
+	}
+
+	book := beneficiaries.New(client.Info, beneficiaries.NewMemoryStore(), beneficiaries.WithInfoCache(cache))
+	acme := beneficiaries.Beneficiary{
+		ID:      "acme",
+		Name:    "Acme Supplies",
+		Methods: []beneficiaries.Method{{Type: beneficiaries.MethodBank, Country: "NG", BankCode: "044", AccountNumber: "0123456789"}},
+	}
+	if err := book.Validate(context.Background(), acme); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if calls["/info/country-banks"] != 1 {
+		t.Errorf("banks were fetched %d times, want 1", calls["/info/country-banks"])
+	}
+}
+
+func TestBeneficiaryPayload(t *testing.T) {
+	ben := beneficiaries.Beneficiary{
+		ID:   "ama",
+		Name: "Ama Mensah",
+		Methods: []beneficiaries.Method{
+			{Type: beneficiaries.MethodMobileMoney, Country: "GH", PhoneNumber: "+233241234567", MobileMoneyCode: "MTN"},
+			{Type: beneficiaries.MethodChimoney, Email: "ama@example.com"},
+		},
+	}
+
+	p, err := ben.Payload(25, "inv-7")
+	if err != nil {
+		t.Fatalf("Payload() error = %v", err)
+	}
+	if p.Method != beneficiaries.MethodMobileMoney || p.MobileMoney == nil || p.MobileMoney.ValueInUSD != 25 ||
+		p.MobileMoney.MomoCode != "MTN" || p.MobileMoney.Reference != "inv-7" {
+		t.Errorf("unexpected payload: %+v", p)
+	}
+
+	p, err = ben.Payload(10, "", beneficiaries.MethodBank, beneficiaries.MethodChimoney)
+	if err != nil {
+		t.Fatalf("Payload() error = %v", err)
+	}
+	if p.Chimoney == nil || p.Chimoney.Email != "ama@example.com" || p.MobileMoney != nil {
+		t.Errorf("unexpected payload: %+v", p)
+	}
+
+	if _, err := ben.Payload(10, "", beneficiaries.MethodBank); !errors.Is(err, beneficiaries.ErrNoMethod) {
+		t.Errorf("Payload() error = %v, want %v", err, beneficiaries.ErrNoMethod)
+	}
+}
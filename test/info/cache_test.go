@@ -0,0 +1,72 @@
+package info_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/chimoney/chimoney-go/modules/info"
+)
+
+func TestCache(t *testing.T) {
+	requests := map[string]int{}
+	server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Path + "?" + r.URL.Query().Get("countryCode")
+		requests[key]++
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/info/country-banks":
+			w.Write([]byte(`{"status":"success","data":[{"code":"044","name":"Access Bank"}]}`))
+		case "/info/mobile-money-codes":
+			w.Write([]byte(`{"status":"success","data":[{"code":"MTN","name":"MTN","country":"GH"}]}`))
+		case "/info/airtime-countries":
+			w.Write([]byte(`{"status":"success","data":["NG","GH"]}`))
+		default:
+			t.Errorf("unexpected path: %v", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer server.Close()
+
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	cache := info.NewCache(client.Info, info.WithTTL(time.Hour), info.WithClock(func() time.Time { return now }))
+	ctx := context.Background()
+
+	lookup := func() {
+		t.Helper()
+		if banks, err := cache.Banks(ctx, "ng"); err != nil || len(banks) != 1 || banks[0].Code != "044" {
+			t.Fatalf("Banks() = %v, %v", banks, err)
+		}
+		if _, err := cache.Banks(ctx, "GH"); err != nil {
+			t.Fatalf("Banks() error = %v", err)
+		}
+		if codes, err := cache.MobileMoneyCodes(ctx); err != nil || len(codes) != 1 || codes[0].Code != "MTN" {
+			t.Fatalf("MobileMoneyCodes() = %v, %v", codes, err)
+		}
+		if countries, err := cache.AirtimeCountries(ctx); err != nil || len(countries) != 2 {
+			t.Fatalf("AirtimeCountries() = %v, %v", countries, err)
+		}
+	}
+	expect := func(n int) {
+		t.Helper()
+		for _, key := range []string{"/info/country-banks?NG", "/info/country-banks?GH", "/info/mobile-money-codes?", "/info/airtime-countries?"} {
+			if requests[key] != n {
+				t.Errorf("unexpected requests for %s: got %d want %d", key, requests[key], n)
+			}
+		}
+	}
+
+	lookup()
+	lookup()
+	expect(1)
+
+	// Lists older than the TTL are fetched again.
+	now = now.Add(time.Hour)
+	lookup()
+	expect(2)
+
+	cache.Invalidate()
+	lookup()
+	expect(3)
+}
@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/chimoney/chimoney-go/modules/info"
 	"github.com/chimoney/chimoney-go/test/testclient"
 )
 
@@ -496,3 +497,31 @@ func TestGetSupportedAssets(t *testing.T) {
 		})
 	}
 }
+
+func TestInfoResponseLocalAmount(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    float64
+		wantErr bool
+	}{
+		{name: "amount in destination currency", data: `{"amountInDestinationCurrency":1500,"localAmount":1}`, want: 1500},
+		{name: "local amount", data: `{"localAmount":12.5}`, want: 12.5},
+		{name: "no amount", data: `{}`, wantErr: true},
+		{name: "zero amount", data: `{"localAmount":0}`, wantErr: true},
+		{name: "unexpected data", data: `"1500"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &info.InfoResponse{Data: json.RawMessage(tt.data)}
+			got, err := resp.LocalAmount()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LocalAmount() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("LocalAmount() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
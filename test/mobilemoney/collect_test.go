@@ -0,0 +1,237 @@
+package mobilemoney_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chimoney/chimoney-go/modules/mobilemoney"
+)
+
+func TestCollect(t *testing.T) {
+	tests := []struct {
+		name        string
+		statuses    []string
+		verifyFails int
+		timeout     time.Duration
+		want        mobilemoney.CollectionStatus
+		wantErr     error
+		wantChanges []mobilemoney.CollectionStatus
+	}{
+		{
+			name:        "approved after a few polls",
+			statuses:    []string{"pending", "pending", "completed"},
+			want:        mobilemoney.CollectionSuccessful,
+			wantChanges: []mobilemoney.CollectionStatus{mobilemoney.CollectionSuccessful},
+		},
+		{
+			name:        "declined",
+			statuses:    []string{"pending", "declined"},
+			want:        mobilemoney.CollectionFailed,
+			wantChanges: []mobilemoney.CollectionStatus{mobilemoney.CollectionFailed},
+		},
+		{
+			name:        "expired",
+			statuses:    []string{"expired"},
+			want:        mobilemoney.CollectionExpired,
+			wantChanges: []mobilemoney.CollectionStatus{mobilemoney.CollectionExpired},
+		},
+		{
+			name:        "transient verification errors",
+			statuses:    []string{"successful"},
+			verifyFails: 2,
+			want:        mobilemoney.CollectionSuccessful,
+			wantChanges: []mobilemoney.CollectionStatus{mobilemoney.CollectionSuccessful},
+		},
+		{
+			name:     "times out while pending",
+			statuses: []string{"pending"},
+			timeout:  20 * time.Millisecond,
+			want:     mobilemoney.CollectionPending,
+			wantErr:  mobilemoney.ErrCollectionTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			polls := 0
+			server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/collections/mobile-money/pay":
+					w.Write([]byte(`{"status":"success","data":{"id":"mm_123","status":"pending"}}`))
+				case "/collections/mobile-money/verify":
+					polls++
+					if polls <= tt.verifyFails {
+						w.WriteHeader(http.StatusBadGateway)
+						return
+					}
+					i := polls - tt.verifyFails - 1
+					if i >= len(tt.statuses) {
+						i = len(tt.statuses) - 1
+					}
+					fmt.Fprintf(w, `{"status":"success","data":{"id":"mm_123","status":%q}}`, tt.statuses[i])
+				default:
+					t.Errorf("unexpected path: %v", r.URL.Path)
+				}
+			})
+			defer server.Close()
+
+			var changes []mobilemoney.CollectionStatus
+			timeout := tt.timeout
+			if timeout == 0 {
+				timeout = time.Second
+			}
+			c, err := client.MobileMoney.Collect(context.Background(), &mobilemoney.PaymentRequest{
+				Amount:      100,
+				Currency:    "GHS",
+				PhoneNumber: "+233241234567",
+				Country:     "GH",
+				TxRef:       "tx_123",
+			},
+				mobilemoney.WithBackoff(time.Millisecond, 4*time.Millisecond),
+				mobilemoney.WithTimeout(timeout),
+				mobilemoney.WithStatusCallback(func(tr mobilemoney.Transition) {
+					if tr.From == tr.To {
+						t.Errorf("callback fired without a transition: %+v", tr)
+					}
+					changes = append(changes, tr.To)
+				}),
+			)
+			if err != nil {
+				t.Fatalf("Collect() error = %v", err)
+			}
+			if c.ID != "mm_123" || c.TxRef != "tx_123" || c.Status() != mobilemoney.CollectionPending {
+				t.Errorf("unexpected collection: %+v", c)
+			}
+
+			status, err := c.Wait(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Wait() error = %v, want %v", err, tt.wantErr)
+			}
+			if status != tt.want {
+				t.Errorf("Wait() = %v, want %v", status, tt.want)
+			}
+			if len(changes) != len(tt.wantChanges) {
+				t.Fatalf("unexpected transitions: %v", changes)
+			}
+			for i := range changes {
+				if changes[i] != tt.wantChanges[i] {
+					t.Errorf("unexpected transitions: got %v want %v", changes, tt.wantChanges)
+				}
+			}
+		})
+	}
+}
+
+func TestCollectContextCancelled(t *testing.T) {
+	server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"success","data":{"id":"mm_123","status":"pending"}}`))
+	})
+	defer server.Close()
+
+	c, err := client.MobileMoney.Collect(context.Background(), &mobilemoney.PaymentRequest{Amount: 100, Currency: "GHS"},
+		mobilemoney.WithBackoff(time.Millisecond, time.Millisecond))
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestCollectUnreadableResponse(t *testing.T) {
+	server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/collections/mobile-money/pay":
+			w.Write([]byte(`{"status":"success","data":"prompt sent"}`))
+		case "/collections/mobile-money/verify":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["id"] != "tx_123" {
+				t.Errorf("unexpected verified ID: got %v want tx_123", body["id"])
+			}
+			w.Write([]byte(`{"status":"success","data":{"status":"successful"}}`))
+		default:
+			t.Errorf("unexpected path: %v", r.URL.Path)
+		}
+	})
+	defer server.Close()
+
+	c, err := client.MobileMoney.Collect(context.Background(), &mobilemoney.PaymentRequest{
+		Amount:      100,
+		Currency:    "GHS",
+		PhoneNumber: "+233241234567",
+		Country:     "GH",
+		TxRef:       "tx_123",
+	}, mobilemoney.WithBackoff(time.Millisecond, time.Millisecond))
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if c.ID != "tx_123" || c.Status() != mobilemoney.CollectionPending {
+		t.Errorf("unexpected collection: %+v", c)
+	}
+	if c.Err() == nil {
+		t.Error("Err() = nil, want the decode problem")
+	}
+
+	status, err := c.Wait(context.Background())
+	if err != nil || status != mobilemoney.CollectionSuccessful {
+		t.Errorf("Wait() = %v, %v", status, err)
+	}
+	if c.Err() != nil {
+		t.Errorf("Err() = %v after a successful poll", c.Err())
+	}
+}
+
+func TestCollectUnreadableVerification(t *testing.T) {
+	var mu sync.Mutex
+	polls := 0
+	server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/collections/mobile-money/pay":
+			w.Write([]byte(`{"status":"success","data":{"id":"mm_123","status":"pending"}}`))
+		case "/collections/mobile-money/verify":
+			polls++
+			w.Write([]byte(`{"status":"success","data":"still checking"}`))
+		}
+	})
+	defer server.Close()
+
+	// A zero backoff is raised to the minimum interval rather than polling in a tight loop.
+	c, err := client.MobileMoney.Collect(context.Background(), &mobilemoney.PaymentRequest{Amount: 100, Currency: "GHS"},
+		mobilemoney.WithBackoff(0, 0), mobilemoney.WithTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	status, err := c.Wait(context.Background())
+	if !errors.Is(err, mobilemoney.ErrCollectionTimeout) || status != mobilemoney.CollectionPending {
+		t.Fatalf("Wait() = %v, %v", status, err)
+	}
+	if c.Err() == nil || !strings.Contains(err.Error(), "unexpected verification response") {
+		t.Errorf("unreadable verification not reported: Err() = %v, Wait() error = %v", c.Err(), err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if polls == 0 || polls > 6 {
+		t.Errorf("unexpected polls within 50ms: %d", polls)
+	}
+}
@@ -0,0 +1,187 @@
+package mobilemoney_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/chimoney/chimoney-go/modules/info"
+	"github.com/chimoney/chimoney-go/modules/mobilemoney"
+	"github.com/chimoney/chimoney-go/modules/payouts"
+)
+
+func TestDetectProvider(t *testing.T) {
+	tests := []struct {
+		name      string
+		phone     string
+		country   string
+		wantE164  string
+		wantCodes []string
+		wantErr   error
+	}{
+		{name: "E.164", phone: "+233241234567", wantE164: "+233241234567", wantCodes: []string{"MTN"}},
+		{name: "international 00 prefix", phone: "00233 20 123 4567", wantE164: "+233201234567", wantCodes: []string{"VOD"}},
+		{name: "national with trunk prefix", phone: "026-123-4567", country: "gh", wantE164: "+233261234567", wantCodes: []string{"ATL"}},
+		{name: "national without trunk prefix", phone: "712345678", country: "KE", wantE164: "+254712345678", wantCodes: []string{"MPS"}},
+		{name: "calling code without plus", phone: "254 (750) 123456", country: "KE", wantE164: "+254750123456", wantCodes: []string{"AIRTEL"}},
+		{name: "longest prefix wins", phone: "+254 110 123 456", wantE164: "+254110123456", wantCodes: []string{"MPS"}},
+		{name: "ambiguous prefix", phone: "+221 77 123 45 67", wantE164: "+221771234567", wantCodes: []string{"ORANGE", "WAVE"}},
+		{name: "national without country", phone: "0241234567", wantErr: mobilemoney.ErrInvalidPhoneNumber},
+		{name: "wrong length", phone: "+23324123456", country: "GH", wantErr: mobilemoney.ErrInvalidPhoneNumber},
+		{name: "letters", phone: "+233 24 CALL ME", wantErr: mobilemoney.ErrInvalidPhoneNumber},
+		{name: "unknown country", phone: "0241234567", country: "FR", wantErr: mobilemoney.ErrUnknownCountry},
+		{name: "unknown prefix", phone: "+233301234567", wantErr: mobilemoney.ErrUnknownProvider},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := mobilemoney.DetectProvider(tt.phone, tt.country)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("DetectProvider() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DetectProvider() error = %v", err)
+			}
+			if d.E164 != tt.wantE164 {
+				t.Errorf("unexpected E.164: got %v want %v", d.E164, tt.wantE164)
+			}
+			var codes []string
+			for _, c := range d.Candidates {
+				codes = append(codes, c.Code)
+			}
+			if strings.Join(codes, ",") != strings.Join(tt.wantCodes, ",") {
+				t.Errorf("unexpected candidates: got %v want %v", codes, tt.wantCodes)
+			}
+		})
+	}
+}
+
+func TestLoadProviderData(t *testing.T) {
+	t.Cleanup(func() {
+		f, err := os.Open("../../modules/mobilemoney/data/providers.json")
+		if err != nil {
+			t.Fatalf("failed to open embedded data: %v", err)
+		}
+		defer f.Close()
+		if err := mobilemoney.LoadProviderData(f); err != nil {
+			t.Fatalf("failed to restore provider data: %v", err)
+		}
+	})
+
+	err := mobilemoney.LoadProviderData(strings.NewReader(`{"ng":{"callingCode":"234","nationalLength":10,
+		"providers":[{"name":"OPay","code":"OPAY","prefixes":["81"]}]}}`))
+	if err != nil {
+		t.Fatalf("LoadProviderData() error = %v", err)
+	}
+	d, err := mobilemoney.DetectProvider("08112345678", "NG")
+	if err != nil || d.E164 != "+2348112345678" || len(d.Candidates) != 1 || d.Candidates[0].Code != "OPAY" {
+		t.Errorf("DetectProvider() = %+v, %v", d, err)
+	}
+	if _, err := mobilemoney.DetectProvider("+233241234567", ""); err == nil {
+		t.Error("expected the replaced data to drop GH")
+	}
+
+	if err := mobilemoney.LoadProviderData(strings.NewReader(`{"NG":{"providers":[]}}`)); err == nil {
+		t.Error("expected an error for data without a calling code")
+	}
+}
+
+func TestDetectProviderWithAPI(t *testing.T) {
+	calls := 0
+	server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path != "/info/mobile-money-codes" {
+			t.Errorf("unexpected path: got %v want /info/mobile-money-codes", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"success","data":[
+			{"code":"MTN","name":"MTN Mobile Money","country":"GH"},
+			{"code":"VODAFONE","name":"Vodafone Cash","country":"GH"},
+			{"code":"ORANGE_SN","name":"Orange Money","country":"SN"},
+			{"code":"MPESA","name":"M-Pesa","country":"KE"},
+			{"code":"AIRTEL_UG","name":"Airtel Money","country":"UG"},
+			{"code":"AIRTEL_GH","name":"Airtel","country":"GH"}
+		]}`))
+	})
+	defer server.Close()
+
+	ctx := context.Background()
+	tests := []struct {
+		phone   string
+		want    []string
+		wantErr error
+	}{
+		{phone: "+233241234567", want: []string{"MTN"}},
+		{phone: "+233201234567", want: []string{"VODAFONE"}},
+		{phone: "+221771234567", want: []string{"ORANGE_SN"}},
+		{phone: "+254712345678", want: []string{"MPESA"}},
+		{phone: "+256701234567", want: []string{"AIRTEL_UG"}},
+		// AirtelTigo is not Airtel, even though one name contains the other.
+		{phone: "+233261234567", wantErr: mobilemoney.ErrUnknownProvider},
+	}
+	for _, tt := range tests {
+		t.Run(tt.phone, func(t *testing.T) {
+			d, err := client.MobileMoney.DetectProvider(ctx, tt.phone, "")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("DetectProvider() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DetectProvider() error = %v", err)
+			}
+			var codes []string
+			for _, c := range d.Candidates {
+				if !c.Verified {
+					t.Errorf("candidate %v is not verified", c)
+				}
+				codes = append(codes, c.Code)
+			}
+			if strings.Join(codes, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("unexpected candidates: got %v want %v", codes, tt.want)
+			}
+		})
+	}
+	if calls != 1 {
+		t.Errorf("expected the codes to be fetched once, got %d calls", calls)
+	}
+}
+
+func TestDetectProviderSharedCache(t *testing.T) {
+	calls := map[string]int{}
+	server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls[r.URL.Path]++
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/info/mobile-money-codes":
+			w.Write([]byte(`{"status":"success","data":[{"code":"MTN","name":"MTN Mobile Money","country":"GH"}]}`))
+		default:
+			w.Write([]byte(`{"status":"success","data":{"chimoneys":[]}}`))
+		}
+	})
+	defer server.Close()
+
+	cache := info.NewCache(client.Info)
+	client.MobileMoney.SetInfoCache(cache)
+	client.Payouts.SetInfoCache(cache)
+
+	ctx := context.Background()
+	d, err := client.MobileMoney.DetectProvider(ctx, "+233241234567", "")
+	if err != nil {
+		t.Fatalf("DetectProvider() error = %v", err)
+	}
+	momos := []payouts.MobileMoneyPayload{{CountryToSend: d.Country, PhoneNumber: d.E164, MomoCode: d.Candidates[0].Code, ValueInUSD: 5}}
+	if _, err := client.Payouts.MobileMoney(ctx, momos, ""); err != nil {
+		t.Fatalf("MobileMoney() error = %v", err)
+	}
+	if calls["/info/mobile-money-codes"] != 1 {
+		t.Errorf("expected the codes to be fetched once across modules, got %d calls", calls["/info/mobile-money-codes"])
+	}
+}
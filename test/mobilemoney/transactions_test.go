@@ -0,0 +1,253 @@
+package mobilemoney_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/chimoney/chimoney-go/modules/mobilemoney"
+	"github.com/chimoney/chimoney-go/modules/payouts/dedupe"
+	"github.com/chimoney/chimoney-go/modules/policy"
+)
+
+const transactionsResponse = `{
+	"status": "success",
+	"data": [
+		{"id": "mm_1", "tx_ref": "tx_1", "status": "completed", "amount": 100, "currency": "GHS", "country": "GH",
+		 "phone_number": "+233241234567", "createdAt": "2026-03-01T10:00:00Z"},
+		{"id": "mm_2", "tx_ref": "tx_2", "status": "pending", "amount": "250.5", "currency": "GHS", "country": "GH",
+		 "phoneNumber": "0241234567", "createdAt": {"_seconds": 1772704800, "_nanoseconds": 0}},
+		{"id": "mm_3", "tx_ref": "tx_3", "status": "Completed", "amount": 40, "currency": "KES", "country": "KE",
+		 "phone_number": "+254712345678", "createdAt": 1773914400000},
+		{"id": "mm_4", "tx_ref": "tx_4", "status": "failed", "amount": 75, "currency": "GHS", "country": "GH",
+		 "phone_number": "+233209876543", "createdAt": "2026-03-20T08:00:00Z"}
+	]
+}`
+
+func TestTransactions(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      mobilemoney.TransactionQuery
+		wantParams map[string]string
+		wantIDs    []string
+	}{
+		{
+			name:    "no filters",
+			wantIDs: []string{"mm_1", "mm_2", "mm_3", "mm_4"},
+		},
+		{
+			name:    "status is case-insensitive",
+			query:   mobilemoney.TransactionQuery{Status: "completed"},
+			wantIDs: []string{"mm_1", "mm_3"},
+		},
+		{
+			name:       "currency and country",
+			query:      mobilemoney.TransactionQuery{Currency: "ghs", Country: "GH"},
+			wantParams: map[string]string{"currency": "ghs", "country": "GH", "status": ""},
+			wantIDs:    []string{"mm_1", "mm_2", "mm_4"},
+		},
+		{
+			name:    "phone number in any format",
+			query:   mobilemoney.TransactionQuery{PhoneNumber: "+233 24 123 4567"},
+			wantIDs: []string{"mm_1", "mm_2"},
+		},
+		{
+			name:    "tx_ref",
+			query:   mobilemoney.TransactionQuery{TxRef: "tx_3"},
+			wantIDs: []string{"mm_3"},
+		},
+		{
+			name: "date range",
+			query: mobilemoney.TransactionQuery{
+				From: time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC),
+				To:   time.Date(2026, 3, 20, 8, 0, 0, 0, time.UTC),
+			},
+			wantParams: map[string]string{"startDate": "2026-03-05T00:00:00Z", "endDate": "2026-03-20T08:00:00Z"},
+			wantIDs:    []string{"mm_2", "mm_3"},
+		},
+		{
+			name:    "sorted by amount descending",
+			query:   mobilemoney.TransactionQuery{SortBy: mobilemoney.SortByAmount, Descending: true},
+			wantIDs: []string{"mm_2", "mm_1", "mm_4", "mm_3"},
+		},
+		{
+			name:    "filtered and sorted by date",
+			query:   mobilemoney.TransactionQuery{Currency: "GHS", SortBy: mobilemoney.SortByDate, Descending: true},
+			wantIDs: []string{"mm_4", "mm_2", "mm_1"},
+		},
+		{
+			name:    "sub-account filtered by the API",
+			query:   mobilemoney.TransactionQuery{SubAccount: "sub_1"},
+			wantIDs: []string{"mm_1", "mm_2", "mm_3", "mm_4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/collections/mobile-money/all" {
+					t.Errorf("unexpected path: got %v want /collections/mobile-money/all", r.URL.Path)
+				}
+				var reqBody map[string]string
+				if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+					t.Errorf("failed to decode request body: %v", err)
+				}
+				if reqBody["subAccount"] != tt.query.SubAccount {
+					t.Errorf("unexpected subAccount: got %v want %v", reqBody["subAccount"], tt.query.SubAccount)
+				}
+				for param, want := range tt.wantParams {
+					if got := r.URL.Query().Get(param); got != want {
+						t.Errorf("unexpected %s parameter: got %q want %q", param, got, want)
+					}
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(transactionsResponse))
+			})
+			defer server.Close()
+
+			it, err := client.MobileMoney.Transactions(context.Background(), tt.query)
+			if err != nil {
+				t.Fatalf("Transactions() error = %v", err)
+			}
+			var ids []string
+			for it.Next() {
+				ids = append(ids, it.Transaction().ID)
+			}
+			if err := it.Err(); err != nil {
+				t.Fatalf("iterator error = %v", err)
+			}
+			if len(ids) != len(tt.wantIDs) {
+				t.Fatalf("unexpected transactions: got %v want %v", ids, tt.wantIDs)
+			}
+			for i := range ids {
+				if ids[i] != tt.wantIDs[i] {
+					t.Errorf("unexpected transactions: got %v want %v", ids, tt.wantIDs)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestTransactionsTyped(t *testing.T) {
+	server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"success","data":{"transactions":[
+			{"id":"mm_2","txRef":"tx_2","status":"pending","amount":"250.5","currency":"GHS","phoneNumber":"0241234567",
+			 "created_at":"2026-03-05 10:00:00"},
+			{"id":"mm_5","amount":{"value":1}}
+		]}}`))
+	})
+	defer server.Close()
+
+	it, err := client.MobileMoney.Transactions(context.Background(), mobilemoney.TransactionQuery{})
+	if err != nil {
+		t.Fatalf("Transactions() error = %v", err)
+	}
+	if !it.Next() {
+		t.Fatalf("expected a transaction, err %v", it.Err())
+	}
+	tx := it.Transaction()
+	if tx.TxRef != "tx_2" || tx.Amount != 250.5 || tx.PhoneNumber != "0241234567" ||
+		!tx.CreatedAt.Equal(time.Date(2026, 3, 5, 10, 0, 0, 0, time.UTC)) || len(tx.Raw) == 0 {
+		t.Errorf("unexpected transaction: %+v", tx)
+	}
+
+	if it.Next() {
+		t.Error("expected the malformed transaction to stop the iterator")
+	}
+	if it.Err() == nil {
+		t.Error("expected an error for the malformed transaction")
+	}
+}
+
+func TestTransactionsStreamed(t *testing.T) {
+	engine, err := policy.NewEngine(policy.Policy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrappers := []struct {
+		name string
+		wrap func(mobilemoney.Client) mobilemoney.Client
+	}{
+		{name: "client", wrap: func(c mobilemoney.Client) mobilemoney.Client { return c }},
+		{name: "policy and duplicate guard", wrap: func(c mobilemoney.Client) mobilemoney.Client {
+			return dedupe.Wrap(policy.Wrap(c, engine))
+		}},
+	}
+
+	for _, w := range wrappers {
+		t.Run(w.name, func(t *testing.T) {
+			release := make(chan struct{})
+			server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"status":"success","data":[{"id":"mm_1","tx_ref":"tx_1"},`))
+				w.(http.Flusher).Flush()
+				// The rest of the history is only sent once the first transaction was read.
+				select {
+				case <-release:
+				case <-time.After(5 * time.Second):
+				}
+				w.Write([]byte(`{"id":"mm_2","tx_ref":"tx_2"}]}`))
+			})
+			defer server.Close()
+
+			m := mobilemoney.New(w.wrap(client))
+			var it *mobilemoney.TransactionIterator
+			done := make(chan error)
+			go func() {
+				var err error
+				if it, err = m.Transactions(context.Background(), mobilemoney.TransactionQuery{}); err == nil && !it.Next() {
+					err = it.Err()
+				}
+				done <- err
+			}()
+			select {
+			case err := <-done:
+				if err != nil || it.Transaction().ID != "mm_1" {
+					t.Fatalf("first transaction = %+v, err %v", it.Transaction(), err)
+				}
+			case <-time.After(2 * time.Second):
+				close(release)
+				t.Fatal("the first transaction was not read before the response ended")
+			}
+			close(release)
+			defer it.Close()
+
+			if !it.Next() || it.Transaction().ID != "mm_2" {
+				t.Errorf("second transaction = %+v, err %v", it.Transaction(), it.Err())
+			}
+			if it.Next() || it.Err() != nil {
+				t.Errorf("expected the end of the list, err %v", it.Err())
+			}
+		})
+	}
+}
+
+// bufferedClient hides the streaming support of the test client.
+type bufferedClient struct {
+	mobilemoney.Client
+}
+
+func TestTransactionsBuffered(t *testing.T) {
+	server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(transactionsResponse))
+	})
+	defer server.Close()
+
+	m := mobilemoney.New(bufferedClient{client})
+	it, err := m.Transactions(context.Background(), mobilemoney.TransactionQuery{Status: "completed", SortBy: mobilemoney.SortByAmount})
+	if err != nil {
+		t.Fatalf("Transactions() error = %v", err)
+	}
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Transaction().ID)
+	}
+	if it.Err() != nil || len(ids) != 2 || ids[0] != "mm_3" || ids[1] != "mm_1" {
+		t.Errorf("unexpected transactions: %v, err %v", ids, it.Err())
+	}
+}
@@ -0,0 +1,165 @@
+package mobilemoney_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/chimoney/chimoney-go/modules/mobilemoney"
+)
+
+func TestULIDGenerator(t *testing.T) {
+	gen := mobilemoney.NewULIDGenerator()
+
+	prev := ""
+	for i := 0; i < 1000; i++ {
+		ref, err := gen.NewTxRef()
+		if err != nil {
+			t.Fatalf("NewTxRef() error = %v", err)
+		}
+		if len(ref) != 26 {
+			t.Fatalf("unexpected ULID length: %q", ref)
+		}
+		if strings.Trim(ref, "0123456789ABCDEFGHJKMNPQRSTVWXYZ") != "" {
+			t.Fatalf("ULID %q has characters outside the Crockford alphabet", ref)
+		}
+		if ref <= prev {
+			t.Fatalf("ULIDs are not increasing: %q after %q", ref, prev)
+		}
+		prev = ref
+	}
+}
+
+func TestPrefixedGenerator(t *testing.T) {
+	gen := mobilemoney.PrefixedGenerator{
+		Prefix: "billing/checkout",
+		Next:   mobilemoney.TxRefFunc(func() (string, error) { return "abc", nil }),
+	}
+	ref, err := gen.NewTxRef()
+	if err != nil || ref != "billing/checkout-abc" {
+		t.Errorf("NewTxRef() = %q, %v", ref, err)
+	}
+
+	ref, err = mobilemoney.PrefixedGenerator{Prefix: "shop-"}.NewTxRef()
+	if err != nil || !strings.HasPrefix(ref, "shop-") || len(ref) != len("shop-")+26 {
+		t.Errorf("NewTxRef() = %q, %v", ref, err)
+	}
+}
+
+func TestMakePaymentTxRef(t *testing.T) {
+	var sent []string
+	server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var reqBody mobilemoney.PaymentRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			t.Errorf("failed to decode request body: %v", err)
+		}
+		sent = append(sent, reqBody.TxRef)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"success","data":{"id":"mm_1","status":"pending"}}`))
+	})
+	defer server.Close()
+
+	ctx := context.Background()
+	client.MobileMoney.SetTxRefGenerator(mobilemoney.PrefixedGenerator{Prefix: "orders"})
+	client.MobileMoney.SetTxRefRegistry(mobilemoney.NewMemoryTxRefRegistry())
+
+	req := &mobilemoney.PaymentRequest{Amount: 10, Currency: "GHS", PhoneNumber: "+233241234567"}
+	resp, err := client.MobileMoney.MakePayment(ctx, req)
+	if err != nil {
+		t.Fatalf("MakePayment() error = %v", err)
+	}
+	if !strings.HasPrefix(resp.TxRef, "orders-") || len(sent) != 1 || sent[0] != resp.TxRef {
+		t.Errorf("unexpected tx_ref: response %q, sent %v", resp.TxRef, sent)
+	}
+	if req.TxRef != "" {
+		t.Errorf("MakePayment() modified the request: %q", req.TxRef)
+	}
+
+	// A second request without TxRef gets a fresh reference.
+	resp2, err := client.MobileMoney.MakePayment(ctx, req)
+	if err != nil || resp2.TxRef == resp.TxRef {
+		t.Errorf("MakePayment() = %q, %v", resp2.TxRef, err)
+	}
+
+	// Reusing a reference is rejected before calling the API.
+	req.TxRef = resp.TxRef
+	if _, err := client.MobileMoney.MakePayment(ctx, req); !errors.Is(err, mobilemoney.ErrDuplicateTxRef) {
+		t.Errorf("MakePayment() error = %v, want %v", err, mobilemoney.ErrDuplicateTxRef)
+	}
+	if len(sent) != 2 {
+		t.Errorf("duplicate request was sent to the API: %v", sent)
+	}
+
+	// Collections report the reference too.
+	c, err := client.MobileMoney.Collect(ctx, &mobilemoney.PaymentRequest{Amount: 10, Currency: "GHS"})
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if c.TxRef != sent[len(sent)-1] {
+		t.Errorf("unexpected collection tx_ref: got %q want %q", c.TxRef, sent[len(sent)-1])
+	}
+}
+
+type failingClient struct {
+	err   error
+	calls int
+}
+
+func (c *failingClient) Do(ctx context.Context, method, path string, body interface{}, v interface{}, params map[string]string) error {
+	c.calls++
+	return c.err
+}
+
+func TestMakePaymentTxRefRelease(t *testing.T) {
+	dial := &url.Error{Op: "Post", URL: "https://api.chimoney.io", Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}}
+
+	tests := []struct {
+		name        string
+		err         error
+		wantRetryOK bool
+	}{
+		{name: "connection refused", err: dial, wantRetryOK: true},
+		{name: "timeout", err: context.DeadlineExceeded},
+		{name: "api error", err: errors.New("chimoney: request failed with status 400")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &failingClient{err: tt.err}
+			m := mobilemoney.New(client)
+			m.SetTxRefRegistry(mobilemoney.NewMemoryTxRefRegistry())
+
+			req := &mobilemoney.PaymentRequest{Amount: 10, Currency: "GHS", TxRef: "order-1"}
+			if _, err := m.MakePayment(context.Background(), req); !errors.Is(err, tt.err) {
+				t.Fatalf("MakePayment() error = %v, want %v", err, tt.err)
+			}
+
+			client.err = nil
+			_, err := m.MakePayment(context.Background(), req)
+			if tt.wantRetryOK && err != nil {
+				t.Errorf("retried MakePayment() error = %v", err)
+			}
+			if !tt.wantRetryOK && !errors.Is(err, mobilemoney.ErrDuplicateTxRef) {
+				t.Errorf("retried MakePayment() error = %v, want %v", err, mobilemoney.ErrDuplicateTxRef)
+			}
+		})
+	}
+}
+
+func TestMakePaymentNilRequest(t *testing.T) {
+	client := &failingClient{}
+	if _, err := mobilemoney.New(client).MakePayment(context.Background(), nil); !errors.Is(err, mobilemoney.ErrNilRequest) {
+		t.Errorf("MakePayment() error = %v, want %v", err, mobilemoney.ErrNilRequest)
+	}
+	if _, err := mobilemoney.New(client).Collect(context.Background(), nil); !errors.Is(err, mobilemoney.ErrNilRequest) {
+		t.Errorf("Collect() error = %v, want %v", err, mobilemoney.ErrNilRequest)
+	}
+	if client.calls != 0 {
+		t.Errorf("nil request was sent to the API")
+	}
+}
@@ -0,0 +1,210 @@
+package payments_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chimoney/chimoney-go/modules/payments"
+	"github.com/chimoney/chimoney-go/test/testclient"
+)
+
+func setupTestServer(t *testing.T, handler http.HandlerFunc) (*httptest.Server, *testclient.Client) {
+	server := httptest.NewServer(handler)
+	client := testclient.New(
+		testclient.WithTestServer(server.URL),
+		testclient.WithAPIKey("test-api-key"),
+	)
+	return server, client
+}
+
+func TestCreateRequest(t *testing.T) {
+	tests := []struct {
+		name           string
+		request        *payments.PaymentRequest
+		subAccount     string
+		response       string
+		status         int
+		wantSubAccount string
+		wantErr        error
+		wantAPIErr     bool
+		wantID         string
+		wantLink       string
+	}{
+		{
+			name: "local currency request",
+			request: &payments.PaymentRequest{
+				Amount:      5000,
+				Currency:    "NGN",
+				PayerEmail:  "payer@example.com",
+				RedirectURL: "https://example.com/thanks",
+			},
+			response: `{
+				"status": "success",
+				"data": {"id": "pay_1", "issueID": "issue_1", "paymentLink": "https://pay.chimoney.io/pay_1"}
+			}`,
+			wantID:   "pay_1",
+			wantLink: "https://pay.chimoney.io/pay_1",
+		},
+		{
+			name:           "scoped to sub-account",
+			request:        &payments.PaymentRequest{ValueInUSD: 20, PayerEmail: "payer@example.com"},
+			subAccount:     "sub_1",
+			response:       `{"status":"success","data":{"chiRef":"chi_9","redirect":"https://pay.chimoney.io/chi_9"}}`,
+			wantSubAccount: "sub_1",
+			wantID:         "chi_9",
+			wantLink:       "https://pay.chimoney.io/chi_9",
+		},
+		{
+			name:           "explicit sub-account wins",
+			request:        &payments.PaymentRequest{ValueInUSD: 20, PayerEmail: "payer@example.com", SubAccount: "sub_2"},
+			subAccount:     "sub_1",
+			response:       `{"status":"success","data":{"id":"pay_2","paymentLink":"https://pay.chimoney.io/pay_2"}}`,
+			wantSubAccount: "sub_2",
+			wantID:         "pay_2",
+			wantLink:       "https://pay.chimoney.io/pay_2",
+		},
+		{
+			name:    "missing payer email",
+			request: &payments.PaymentRequest{ValueInUSD: 20},
+			wantErr: payments.ErrInvalidEmail,
+		},
+		{
+			name:    "amount without currency",
+			request: &payments.PaymentRequest{Amount: 100, PayerEmail: "payer@example.com"},
+			wantErr: payments.ErrInvalidCurrency,
+		},
+		{
+			name:    "no amount",
+			request: &payments.PaymentRequest{PayerEmail: "payer@example.com"},
+			wantErr: payments.ErrInvalidAmount,
+		},
+		{
+			name:       "api error",
+			request:    &payments.PaymentRequest{ValueInUSD: 20, PayerEmail: "payer@example.com"},
+			response:   `{"status":"error","message":"Invalid request"}`,
+			status:     http.StatusBadRequest,
+			wantAPIErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				if r.Method != "POST" {
+					t.Errorf("unexpected method: got %v want POST", r.Method)
+				}
+				if r.URL.Path != "/payment/initiate" {
+					t.Errorf("unexpected path: got %v want /payment/initiate", r.URL.Path)
+				}
+
+				var reqBody payments.PaymentRequest
+				if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+					t.Errorf("failed to decode request body: %v", err)
+				}
+				if reqBody.PayerEmail != tt.request.PayerEmail || reqBody.RedirectURL != tt.request.RedirectURL {
+					t.Errorf("unexpected request body: %+v", reqBody)
+				}
+				if reqBody.SubAccount != tt.wantSubAccount {
+					t.Errorf("unexpected subAccount: got %v want %v", reqBody.SubAccount, tt.wantSubAccount)
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+				}
+				w.Write([]byte(tt.response))
+			})
+			defer server.Close()
+
+			p := client.Payments
+			if tt.subAccount != "" {
+				p = p.ForSubAccount(tt.subAccount)
+			}
+			resp, err := p.CreateRequest(context.Background(), tt.request)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("CreateRequest() error = %v, want %v", err, tt.wantErr)
+				}
+				if called {
+					t.Error("invalid request was sent to the API")
+				}
+				return
+			}
+			if (err != nil) != tt.wantAPIErr {
+				t.Fatalf("CreateRequest() error = %v, wantErr %v", err, tt.wantAPIErr)
+			}
+			if tt.wantAPIErr {
+				return
+			}
+			if resp.ID != tt.wantID || resp.CheckoutLink != tt.wantLink {
+				t.Errorf("unexpected checkout: id %q link %q", resp.ID, resp.CheckoutLink)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		subAccount string
+		response   string
+		wantStatus payments.PaymentStatus
+		wantErr    bool
+	}{
+		{
+			name:       "paid",
+			id:         "pay_1",
+			response:   `{"status":"success","data":{"id":"pay_1","status":"Paid","valueInUSD":20,"paidAt":"2026-10-01T10:00:00Z"}}`,
+			wantStatus: payments.StatusPaid,
+		},
+		{
+			name:       "pending in sub-account",
+			id:         "pay_2",
+			subAccount: "sub_1",
+			response:   `{"status":"success","data":{"status":"pending"}}`,
+			wantStatus: payments.StatusPending,
+		},
+		{
+			name:    "empty ID",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/payment/verify" {
+					t.Errorf("unexpected path: got %v want /payment/verify", r.URL.Path)
+				}
+				var reqBody map[string]string
+				if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+					t.Errorf("failed to decode request body: %v", err)
+				}
+				if reqBody["id"] != tt.id || reqBody["subAccount"] != tt.subAccount {
+					t.Errorf("unexpected request body: %v", reqBody)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(tt.response))
+			})
+			defer server.Close()
+
+			resp, err := client.Payments.ForSubAccount(tt.subAccount).Verify(context.Background(), tt.id)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if resp.Payment.Status != tt.wantStatus || resp.Payment.ID != tt.id {
+				t.Errorf("unexpected payment: %+v", resp.Payment)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package payouts_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/chimoney/chimoney-go/modules/payouts"
+	"github.com/chimoney/chimoney-go/modules/payouts/approval"
+)
+
+func TestApprovalWorkflow(t *testing.T) {
+	calls := 0
+	server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path != "/payouts/bank" {
+			t.Errorf("unexpected path: got %v want /payouts/bank", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"success","data":{"chimoneys":[]}}`))
+	})
+	defer server.Close()
+
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	wf := approval.New(client.Payouts, 2,
+		approval.WithApprovalTTL(time.Hour),
+		approval.WithClock(func() time.Time { return now }),
+	)
+
+	batch := &approval.Batch{
+		Bank: []payouts.BankPayload{{
+			CountryToSend: "NG",
+			AccountBank:   "044",
+			AccountNumber: "1234567890",
+			ValueInUSD:    5000,
+			Reference:     "ref_123",
+		}},
+	}
+
+	p, err := wf.Prepare("alice", batch)
+	if err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+	if p.Hash == "" {
+		t.Fatal("Prepare() returned empty hash")
+	}
+
+	for _, self := range []string{"alice", " Alice "} {
+		if err := wf.Approve(p.ID, self); !errors.Is(err, approval.ErrSelfApproval) {
+			t.Errorf("self approval by %q error = %v, want %v", self, err, approval.ErrSelfApproval)
+		}
+	}
+	if err := wf.Approve(p.ID, "bob"); err != nil {
+		t.Fatalf("Approve() error = %v", err)
+	}
+	if err := wf.Approve(p.ID, "BOB"); !errors.Is(err, approval.ErrDuplicateApproval) {
+		t.Errorf("duplicate approval error = %v, want %v", err, approval.ErrDuplicateApproval)
+	}
+	if _, err := wf.Submit(context.Background(), p.ID, "alice"); !errors.Is(err, approval.ErrInsufficientApprovals) {
+		t.Errorf("Submit() with one approval error = %v, want %v", err, approval.ErrInsufficientApprovals)
+	}
+
+	// Approvals expire.
+	now = now.Add(2 * time.Hour)
+	if err := wf.Approve(p.ID, "carol"); err != nil {
+		t.Fatalf("Approve() error = %v", err)
+	}
+	if _, err := wf.Submit(context.Background(), p.ID, "alice"); !errors.Is(err, approval.ErrInsufficientApprovals) {
+		t.Errorf("Submit() with expired approval error = %v, want %v", err, approval.ErrInsufficientApprovals)
+	}
+	if err := wf.Approve(p.ID, "bob"); err != nil {
+		t.Fatalf("re-Approve() error = %v", err)
+	}
+
+	// Pending payouts are returned as copies, so editing one changes nothing.
+	p.Batch.Bank[0].ValueInUSD = 50000
+	p.Approvals = nil
+	if got, err := wf.Get(p.ID); err != nil || got.Batch.Bank[0].ValueInUSD != 5000 || len(got.Approvals) != 3 {
+		t.Errorf("Get() = %+v, %v", got, err)
+	}
+
+	// Edits through Update drop the approvals.
+	batch.Bank[0].ValueInUSD = 50
+	if p, err = wf.Update(p.ID, "alice", batch); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if _, err := wf.Submit(context.Background(), p.ID, "alice"); !errors.Is(err, approval.ErrInsufficientApprovals) {
+		t.Errorf("Submit() after edit error = %v, want %v", err, approval.ErrInsufficientApprovals)
+	}
+	if calls != 0 {
+		t.Fatalf("payout was sent before approval: %d calls", calls)
+	}
+	for _, approver := range []string{"bob", "carol"} {
+		if err := wf.Approve(p.ID, approver); err != nil {
+			t.Fatalf("Approve(%s) error = %v", approver, err)
+		}
+	}
+
+	result, err := wf.Submit(context.Background(), p.ID, "alice")
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	if result.Bank == nil || calls != 1 {
+		t.Errorf("expected one bank payout, got result %+v and %d calls", result, calls)
+	}
+	if _, err := wf.Submit(context.Background(), p.ID, "alice"); !errors.Is(err, approval.ErrAlreadySubmitted) {
+		t.Errorf("second Submit() error = %v, want %v", err, approval.ErrAlreadySubmitted)
+	}
+
+	var events []approval.Event
+	var buf bytes.Buffer
+	if err := wf.ExportJSON(&buf); err != nil {
+		t.Fatalf("ExportJSON() error = %v", err)
+	}
+	if err := json.Unmarshal(buf.Bytes(), &events); err != nil {
+		t.Fatalf("failed to decode exported history: %v", err)
+	}
+	last := events[len(events)-1]
+	if last.Type != approval.EventSubmitted || last.Actor != "alice" || last.Hash != p.Hash {
+		t.Errorf("unexpected last event: %+v", last)
+	}
+
+	buf.Reset()
+	if err := wf.ExportCSV(&buf); err != nil {
+		t.Fatalf("ExportCSV() error = %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read exported csv: %v", err)
+	}
+	if len(rows) != len(events)+1 {
+		t.Errorf("unexpected csv rows: got %d want %d", len(rows), len(events)+1)
+	}
+}
+
+func TestApprovalSubmitRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		resubmit  error
+		wantCalls int
+	}{
+		{name: "rejected", status: http.StatusBadRequest, resubmit: nil, wantCalls: 2},
+		{name: "server error", status: http.StatusInternalServerError, resubmit: approval.ErrAlreadySubmitted, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				w.Header().Set("Content-Type", "application/json")
+				if calls == 1 {
+					w.WriteHeader(tt.status)
+					w.Write([]byte(`{"status":"error","error":"failed"}`))
+					return
+				}
+				w.Write([]byte(`{"status":"success","data":{"chimoneys":[]}}`))
+			})
+			defer server.Close()
+
+			wf := approval.New(client.Payouts, 1)
+			p, err := wf.Prepare("alice", &approval.Batch{
+				Bank: []payouts.BankPayload{{
+					CountryToSend: "NG",
+					AccountBank:   "044",
+					AccountNumber: "1234567890",
+					ValueInUSD:    50,
+				}},
+			})
+			if err != nil {
+				t.Fatalf("Prepare() error = %v", err)
+			}
+			if err := wf.Approve(p.ID, "bob"); err != nil {
+				t.Fatalf("Approve() error = %v", err)
+			}
+
+			if _, err := wf.Submit(context.Background(), p.ID, "alice"); err == nil {
+				t.Fatal("Submit() expected error")
+			}
+			if _, err := wf.Submit(context.Background(), p.ID, "alice"); !errors.Is(err, tt.resubmit) {
+				t.Errorf("second Submit() error = %v, want %v", err, tt.resubmit)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("unexpected calls: got %d want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package payouts_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/chimoney/chimoney-go/modules/payouts"
+	"github.com/chimoney/chimoney-go/test/testclient"
+)
+
+func TestPayoutsCancel(t *testing.T) {
+	tests := []struct {
+		name         string
+		before       string
+		after        string
+		deleteStatus int
+		wantOutcome  payouts.CancelOutcome
+		wantStatus   string
+		wantDelete   bool
+		wantErr      bool
+	}{
+		{
+			name:        "pending payout is cancelled",
+			before:      `{"status":"success","data":[{"chiRef":"chi_1","status":"pending"}]}`,
+			after:       `{"status":"success","data":[{"chiRef":"chi_1","status":"cancelled"}]}`,
+			wantOutcome: payouts.OutcomeCancelled,
+			wantStatus:  "cancelled",
+			wantDelete:  true,
+		},
+		{
+			name:        "deleted payout is no longer found",
+			before:      `{"status":"success","data":{"chiRef":"chi_1","status":"pending"}}`,
+			after:       `{"status":"success","data":[]}`,
+			wantOutcome: payouts.OutcomeCancelled,
+			wantDelete:  true,
+		},
+		{
+			name:        "already paid",
+			before:      `{"status":"success","data":[{"chiRef":"chi_1","status":"paid"}]}`,
+			wantOutcome: payouts.OutcomeAlreadyPaid,
+			wantStatus:  "paid",
+		},
+		{
+			name:        "already cancelled",
+			before:      `{"status":"success","data":[{"chiRef":"chi_1","status":"expired"}]}`,
+			wantOutcome: payouts.OutcomeAlreadyCancelled,
+			wantStatus:  "expired",
+		},
+		{
+			name:        "not found",
+			before:      `{"status":"success","data":[]}`,
+			wantOutcome: payouts.OutcomeNotFound,
+		},
+		{
+			name:        "processing payout is not cancellable",
+			before:      `{"status":"success","data":[{"chiRef":"chi_1","status":"processing"}]}`,
+			wantOutcome: payouts.OutcomeNotCancellable,
+			wantStatus:  "processing",
+		},
+		{
+			name:        "claimed while deleting",
+			before:      `{"status":"success","data":[{"chiRef":"chi_1","status":"pending"}]}`,
+			after:       `{"status":"success","data":[{"chiRef":"chi_1","status":"paid"}]}`,
+			wantOutcome: payouts.OutcomeAlreadyPaid,
+			wantStatus:  "paid",
+			wantDelete:  true,
+		},
+		{
+			name:         "delete rejected",
+			before:       `{"status":"success","data":[{"chiRef":"chi_1","status":"pending"}]}`,
+			deleteStatus: http.StatusBadRequest,
+			wantOutcome:  payouts.OutcomeFailed,
+			wantStatus:   "pending",
+			wantDelete:   true,
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deleted := false
+			server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/payouts/status":
+					if deleted {
+						w.Write([]byte(tt.after))
+					} else {
+						w.Write([]byte(tt.before))
+					}
+				case "/accounts/delete-unpaid":
+					if r.Method != "DELETE" {
+						t.Errorf("unexpected method: got %v want DELETE", r.Method)
+					}
+					var reqBody map[string]string
+					if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+						t.Errorf("failed to decode request body: %v", err)
+					}
+					if reqBody["chiRef"] != "chi_1" || reqBody["subAccount"] != "sub_1" {
+						t.Errorf("unexpected request body: %v", reqBody)
+					}
+					if tt.deleteStatus != 0 {
+						w.WriteHeader(tt.deleteStatus)
+						w.Write([]byte(`{"status":"error","message":"Transaction cannot be deleted"}`))
+						return
+					}
+					deleted = true
+					w.Write([]byte(`{"status":"success","data":{}}`))
+				default:
+					t.Errorf("unexpected path: %v", r.URL.Path)
+					w.WriteHeader(http.StatusNotFound)
+				}
+			})
+			defer server.Close()
+
+			trail := payouts.NewMemoryAuditTrail()
+			client.Payouts.SetAuditTrail(trail)
+			now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+			client.Payouts.SetClock(func() time.Time { return now })
+
+			c, err := client.Payouts.Cancel(context.Background(), "chi_1", "sent to the wrong account", "sub_1")
+			if err != nil {
+				t.Fatalf("Cancel() error = %v", err)
+			}
+			if c.Outcome != tt.wantOutcome {
+				t.Errorf("unexpected outcome: got %v want %v (err %v)", c.Outcome, tt.wantOutcome, c.Err)
+			}
+			if (c.Err != nil) != tt.wantErr {
+				t.Errorf("Cancel() outcome error = %v, wantErr %v", c.Err, tt.wantErr)
+			}
+			if deleted != (tt.wantDelete && tt.deleteStatus == 0) {
+				t.Errorf("unexpected deletion: got %v", deleted)
+			}
+
+			entries := trail.Entries()
+			if len(entries) != 1 {
+				t.Fatalf("expected one audit entry, got %+v", entries)
+			}
+			if entries[0].Reason != "sent to the wrong account" || entries[0].Outcome != tt.wantOutcome || entries[0].SubAccount != "sub_1" {
+				t.Errorf("unexpected audit entry: %+v", entries[0])
+			}
+			if entries[0].Status != tt.wantStatus || entries[0].PreviousStatus != c.PreviousStatus || !entries[0].At.Equal(now) {
+				t.Errorf("unexpected audit statuses: %+v, want status %q", entries[0], tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestPayoutsCancelBulk(t *testing.T) {
+	statuses := map[string]string{"chi_1": "pending", "chi_2": "paid"}
+	server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var reqBody map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			t.Errorf("failed to decode request body: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/payouts/status":
+			status, ok := statuses[reqBody["chiRef"]]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"status":"error","message":"Not found"}`))
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"status": "success",
+				"data":   []map[string]string{{"chiRef": reqBody["chiRef"], "status": status}},
+			})
+		case "/accounts/delete-unpaid":
+			delete(statuses, reqBody["chiRef"])
+			w.Write([]byte(`{"status":"success","data":{}}`))
+		}
+	})
+	defer server.Close()
+
+	results, err := client.Payouts.CancelBulk(context.Background(), []string{"chi_1", "chi_2", "chi_3", ""}, "duplicate batch", "")
+	if err != nil {
+		t.Fatalf("CancelBulk() error = %v", err)
+	}
+
+	want := []payouts.CancelOutcome{payouts.OutcomeCancelled, payouts.OutcomeAlreadyPaid, payouts.OutcomeNotFound, payouts.OutcomeFailed}
+	if len(results) != len(want) {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	for i, r := range results {
+		if r.Outcome != want[i] {
+			t.Errorf("result %d: got %v want %v", i, r.Outcome, want[i])
+		}
+	}
+	if !errors.Is(results[3].Err, payouts.ErrInvalidChiRef) {
+		t.Errorf("unexpected error for empty chiRef: %v", results[3].Err)
+	}
+
+	trail := client.Payouts.AuditTrail().(*payouts.MemoryAuditTrail)
+	if n := len(trail.Entries()); n != 3 {
+		t.Errorf("expected 3 audit entries, got %d", n)
+	}
+
+	if _, err := client.Payouts.CancelBulk(context.Background(), []string{"chi_1"}, " ", ""); !errors.Is(err, payouts.ErrMissingReason) {
+		t.Errorf("CancelBulk() error = %v, want %v", err, payouts.ErrMissingReason)
+	}
+}
+
+type errClient struct {
+	err error
+}
+
+func (c errClient) Do(ctx context.Context, method, path string, body interface{}, v interface{}, params map[string]string) error {
+	return c.err
+}
+
+func TestPayoutsCancelNotFoundStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantOutcome payouts.CancelOutcome
+	}{
+		{name: "404 status error", err: fmt.Errorf("retrying: %w", &testclient.StatusError{StatusCode: http.StatusNotFound}), wantOutcome: payouts.OutcomeNotFound},
+		{name: "other status error", err: &testclient.StatusError{StatusCode: http.StatusBadGateway}, wantOutcome: payouts.OutcomeFailed},
+		{name: "404 only in the message", err: errors.New("proxy: upstream answered status 404"), wantOutcome: payouts.OutcomeFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := payouts.New(errClient{err: tt.err})
+			c, err := p.Cancel(context.Background(), "chi_1", "sent twice", "")
+			if err != nil {
+				t.Fatalf("Cancel() error = %v", err)
+			}
+			if c.Outcome != tt.wantOutcome {
+				t.Errorf("unexpected outcome: got %v want %v (err %v)", c.Outcome, tt.wantOutcome, c.Err)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package payouts_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/chimoney/chimoney-go/modules/payouts"
+)
+
+func TestCryptoDestinationValidate(t *testing.T) {
+	tag := uint32(12345)
+	tests := []struct {
+		name        string
+		destination payouts.CryptoDestination
+		wantErr     error
+	}{
+		{
+			name: "xrpl issued currency with destination tag",
+			destination: payouts.XRPLDestination{
+				Address:        "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh",
+				Issuer:         "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B",
+				Currency:       "USD",
+				DestinationTag: &tag,
+			},
+		},
+		{
+			name:        "xrpl bad checksum",
+			destination: payouts.XRPLDestination{Address: "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTj"},
+			wantErr:     payouts.ErrInvalidCryptoAddress,
+		},
+		{
+			name: "xrpl issued currency without issuer",
+			destination: payouts.XRPLDestination{
+				Address:  "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh",
+				Currency: "USD",
+			},
+			wantErr: payouts.ErrInvalidCryptoIssuer,
+		},
+		{
+			name: "stellar with memo",
+			destination: payouts.StellarDestination{
+				Address: "GAAZI4TCR3TY5OJHCTJC2A4QSY6CJWJH5IAJTGKIN2ER7LBNVKOCCWN7",
+				Memo:    "invoice-42",
+			},
+		},
+		{
+			name:        "stellar bad checksum",
+			destination: payouts.StellarDestination{Address: "GAAZI4TCR3TY5OJHCTJC2A4QSY6CJWJH5IAJTGKIN2ER7LBNVKOCCWN6"},
+			wantErr:     payouts.ErrInvalidCryptoAddress,
+		},
+		{
+			name: "stellar memo too long",
+			destination: payouts.StellarDestination{
+				Address: "GAAZI4TCR3TY5OJHCTJC2A4QSY6CJWJH5IAJTGKIN2ER7LBNVKOCCWN7",
+				Memo:    "this memo is far too long for stellar",
+			},
+			wantErr: payouts.ErrInvalidCryptoMemo,
+		},
+		{
+			name: "evm checksummed address",
+			destination: payouts.EVMDestination{
+				Chain:   payouts.NetworkPolygon,
+				Address: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+				Token:   "USDC",
+			},
+		},
+		{
+			name: "evm lowercase address",
+			destination: payouts.EVMDestination{
+				Chain:   payouts.NetworkEthereum,
+				Address: "0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359",
+				Token:   "USDT",
+			},
+		},
+		{
+			name: "evm broken checksum",
+			destination: payouts.EVMDestination{
+				Chain:   payouts.NetworkEthereum,
+				Address: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD",
+				Token:   "USDC",
+			},
+			wantErr: payouts.ErrInvalidCryptoAddress,
+		},
+		{
+			name: "evm unsupported token",
+			destination: payouts.EVMDestination{
+				Chain:   payouts.NetworkBase,
+				Address: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+				Token:   "USDT",
+			},
+			wantErr: payouts.ErrInvalidCryptoAsset,
+		},
+		{
+			name: "evm unknown chain",
+			destination: payouts.EVMDestination{
+				Chain:   "fantom",
+				Address: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+				Token:   "USDC",
+			},
+			wantErr: payouts.ErrUnsupportedNetwork,
+		},
+		{
+			name: "solana",
+			destination: payouts.SolanaDestination{
+				Address: "So11111111111111111111111111111111111111112",
+				Token:   "USDC",
+			},
+		},
+		{
+			name: "solana invalid address",
+			destination: payouts.SolanaDestination{
+				Address: "0OIl",
+				Token:   "USDC",
+			},
+			wantErr: payouts.ErrInvalidCryptoAddress,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.destination.Validate()
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChecksumEVMAddress(t *testing.T) {
+	want := "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359"
+	if got := payouts.ChecksumEVMAddress("0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359"); got != want {
+		t.Errorf("ChecksumEVMAddress() = %v, want %v", got, want)
+	}
+}
+
+func TestInitiateChimoneyCryptoPayments(t *testing.T) {
+	tests := []struct {
+		name        string
+		payments    []payouts.CryptoPayment
+		wantNetwork string
+		wantErr     bool
+		wantCalled  bool
+	}{
+		{
+			name: "stellar destination",
+			payments: []payouts.CryptoPayment{
+				{Destination: payouts.StellarDestination{
+					Address: "GAAZI4TCR3TY5OJHCTJC2A4QSY6CJWJH5IAJTGKIN2ER7LBNVKOCCWN7",
+					Memo:    "12345",
+				}},
+			},
+			wantNetwork: "stellar",
+			wantCalled:  true,
+		},
+		{
+			name: "evm destination",
+			payments: []payouts.CryptoPayment{
+				{Destination: payouts.EVMDestination{
+					Chain:   payouts.NetworkCelo,
+					Address: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+					Token:   "cUSD",
+				}},
+			},
+			wantNetwork: "celo",
+			wantCalled:  true,
+		},
+		{
+			name: "invalid address is rejected before sending",
+			payments: []payouts.CryptoPayment{
+				{Destination: payouts.XRPLDestination{Address: "not-an-address"}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				var reqBody struct {
+					CryptoPayments []map[string]json.RawMessage `json:"crypto_payments"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+					t.Errorf("failed to decode request body: %v", err)
+				}
+				if len(reqBody.CryptoPayments) != 1 {
+					t.Fatalf("unexpected crypto_payments: %+v", reqBody.CryptoPayments)
+				}
+				if _, ok := reqBody.CryptoPayments[0][tt.wantNetwork]; !ok {
+					t.Errorf("crypto payment missing %q key: %+v", tt.wantNetwork, reqBody.CryptoPayments[0])
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"status":"success","data":{}}`))
+			})
+			defer server.Close()
+
+			chimoneys := []payouts.ChimoneyPayload{{ValueInUSD: 10, Email: "jane@example.com"}}
+			resp, err := client.Payouts.InitiateChimoney(context.Background(), chimoneys, false, tt.payments, "")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("InitiateChimoney() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if resp == nil {
+				t.Error("InitiateChimoney() returned a nil response")
+			}
+			if called != tt.wantCalled {
+				t.Errorf("server called = %v, want %v", called, tt.wantCalled)
+			}
+		})
+	}
+}
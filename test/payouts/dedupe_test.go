@@ -0,0 +1,212 @@
+package payouts_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/chimoney/chimoney-go/modules/payouts"
+	"github.com/chimoney/chimoney-go/modules/payouts/dedupe"
+	"github.com/chimoney/chimoney-go/modules/policy"
+)
+
+func TestDuplicateGuard(t *testing.T) {
+	sent := 0
+	status := http.StatusOK
+	server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		sent++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(`{"status":"success","data":{}}`))
+	})
+	defer server.Close()
+
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	p := payouts.New(dedupe.Wrap(client,
+		dedupe.WithWindow(time.Hour),
+		dedupe.WithClock(func() time.Time { return now }),
+	))
+	ctx := context.Background()
+
+	first := []payouts.BankPayload{{CountryToSend: "NG", AccountBank: "044", AccountNumber: "0123 456 789", ValueInUSD: 50, Reference: "inv-1"}}
+	if _, err := p.Bank(ctx, first, ""); err != nil {
+		t.Fatalf("first Bank() error = %v", err)
+	}
+
+	// Same payout with differently formatted account number.
+	again := []payouts.BankPayload{{CountryToSend: "ng", AccountBank: "044", AccountNumber: "0123-456-789", ValueInUSD: 50, Reference: "inv-1"}}
+	_, err := p.Bank(ctx, again, "")
+	var dup *dedupe.DuplicateError
+	if !errors.As(err, &dup) || !errors.Is(err, dedupe.ErrDuplicate) {
+		t.Fatalf("duplicate Bank() error = %v, want DuplicateError", err)
+	}
+	if dup.Rail != "bank" || dup.Index != 0 || !dup.FirstSeen.Equal(now) {
+		t.Errorf("unexpected duplicate error: %+v", dup)
+	}
+	if sent != 1 {
+		t.Errorf("duplicate was sent: %d requests", sent)
+	}
+
+	// A different reference is a different payout.
+	other := []payouts.BankPayload{{CountryToSend: "NG", AccountBank: "044", AccountNumber: "0123456789", ValueInUSD: 50, Reference: "inv-2"}}
+	if _, err := p.Bank(ctx, other, ""); err != nil {
+		t.Errorf("Bank() with new reference error = %v", err)
+	}
+
+	// Explicit override repeats the payout.
+	if _, err := p.Bank(dedupe.WithOverride(ctx), again, ""); err != nil {
+		t.Errorf("Bank() with override error = %v", err)
+	}
+
+	// Duplicates within a single batch are caught.
+	batch := []payouts.ChimoneyPayload{
+		{ValueInUSD: 5, Email: "Jane@example.com"},
+		{ValueInUSD: 5, Email: "jane@example.com "},
+	}
+	if _, err := p.Chimoney(ctx, batch, ""); !errors.As(err, &dup) || dup.Index != 1 {
+		t.Errorf("Chimoney() with duplicate items error = %v", err)
+	}
+
+	// Rejected requests are not remembered.
+	status = http.StatusBadRequest
+	airtime := []payouts.AirtimePayload{{CountryToSend: "NG", PhoneNumber: "+2348123456789", ValueInUSD: 2}}
+	if _, err := p.Airtime(ctx, airtime, ""); err == nil || errors.Is(err, dedupe.ErrDuplicate) {
+		t.Fatalf("Airtime() error = %v, want API error", err)
+	}
+	status = http.StatusOK
+	if _, err := p.Airtime(ctx, airtime, ""); err != nil {
+		t.Errorf("retried Airtime() error = %v", err)
+	}
+
+	// A server error may come after the payout was made, so it is remembered.
+	status = http.StatusInternalServerError
+	bob := []payouts.ChimoneyPayload{{ValueInUSD: 3, Email: "bob@example.com"}}
+	if _, err := p.Chimoney(ctx, bob, ""); err == nil || errors.Is(err, dedupe.ErrDuplicate) {
+		t.Fatalf("Chimoney() error = %v, want API error", err)
+	}
+	status = http.StatusOK
+	if _, err := p.Chimoney(ctx, bob, ""); !errors.Is(err, dedupe.ErrDuplicate) {
+		t.Errorf("retried Chimoney() error = %v, want %v", err, dedupe.ErrDuplicate)
+	}
+
+	// The window expires.
+	now = now.Add(2 * time.Hour)
+	if _, err := p.Bank(ctx, again, ""); err != nil {
+		t.Errorf("Bank() after window error = %v", err)
+	}
+}
+
+type timeoutClient struct {
+	sent int
+}
+
+func (c *timeoutClient) Do(ctx context.Context, method, path string, body interface{}, v interface{}, params map[string]string) error {
+	c.sent++
+	return context.DeadlineExceeded
+}
+
+func TestDuplicateGuardUnknownOutcome(t *testing.T) {
+	client := &timeoutClient{}
+	p := payouts.New(dedupe.Wrap(client))
+	ctx := context.Background()
+
+	banks := []payouts.BankPayload{{CountryToSend: "NG", AccountBank: "044", AccountNumber: "0123456789", ValueInUSD: 50}}
+	if _, err := p.Bank(ctx, banks, ""); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Bank() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	// The timed out payout may have gone through, so the retry is blocked.
+	if _, err := p.Bank(ctx, banks, ""); !errors.Is(err, dedupe.ErrDuplicate) {
+		t.Errorf("retried Bank() error = %v, want %v", err, dedupe.ErrDuplicate)
+	}
+	if client.sent != 1 {
+		t.Errorf("unexpected requests sent: got %d want 1", client.sent)
+	}
+}
+
+func TestDuplicateGuardFlagMode(t *testing.T) {
+	sent := 0
+	server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		sent++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"success","data":{}}`))
+	})
+	defer server.Close()
+
+	var flagged []*dedupe.DuplicateError
+	p := payouts.New(dedupe.Wrap(client,
+		dedupe.WithMode(dedupe.Flag),
+		dedupe.WithFlagHandler(func(d *dedupe.DuplicateError) { flagged = append(flagged, d) }),
+	))
+
+	gifts := []payouts.GiftCardPayload{{Email: "jane@example.com", ValueInUSD: 10}}
+	for i := 0; i < 2; i++ {
+		if _, err := p.GiftCard(context.Background(), gifts, ""); err != nil {
+			t.Fatalf("GiftCard() error = %v", err)
+		}
+	}
+	if sent != 2 {
+		t.Errorf("flag mode should send duplicates: %d requests", sent)
+	}
+	if len(flagged) != 1 || flagged[0].Rail != "gift_card" {
+		t.Errorf("unexpected flagged duplicates: %+v", flagged)
+	}
+}
+
+type unsentClient struct {
+	next  dedupe.Client
+	fails int
+}
+
+func (c *unsentClient) Do(ctx context.Context, method, path string, body interface{}, v interface{}, params map[string]string) error {
+	if c.fails > 0 {
+		c.fails--
+		return &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	}
+	return c.next.Do(ctx, method, path, body, v, params)
+}
+
+func TestDuplicateGuardUnsent(t *testing.T) {
+	sent := 0
+	server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		sent++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"success","data":{}}`))
+	})
+	defer server.Close()
+
+	now := time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	engine, err := policy.NewEngine(policy.Policy{
+		BusinessHours: &policy.BusinessHours{TimeZone: "UTC", Start: "09:00", End: "17:00"},
+	}, policy.WithClock(clock))
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	transport := &unsentClient{next: client, fails: 1}
+	p := payouts.New(dedupe.Wrap(policy.Wrap(transport, engine), dedupe.WithClock(clock)))
+	ctx := context.Background()
+
+	banks := []payouts.BankPayload{{CountryToSend: "NG", AccountBank: "044", AccountNumber: "0123456789", ValueInUSD: 50}}
+	if _, err := p.Bank(ctx, banks, ""); !errors.Is(err, policy.ErrViolation) {
+		t.Fatalf("Bank() at 03:00 error = %v, want %v", err, policy.ErrViolation)
+	}
+
+	// Blocked by the policy, then unable to connect: nothing was sent either time.
+	now = now.Add(7 * time.Hour)
+	var op *net.OpError
+	if _, err := p.Bank(ctx, banks, ""); !errors.As(err, &op) {
+		t.Fatalf("Bank() error = %v, want a dial error", err)
+	}
+	if _, err := p.Bank(ctx, banks, ""); err != nil {
+		t.Fatalf("retried Bank() error = %v", err)
+	}
+	if sent != 1 {
+		t.Errorf("unexpected requests sent: got %d want 1", sent)
+	}
+	if _, err := p.Bank(ctx, banks, ""); !errors.Is(err, dedupe.ErrDuplicate) {
+		t.Errorf("repeated Bank() error = %v, want %v", err, dedupe.ErrDuplicate)
+	}
+}
@@ -0,0 +1,185 @@
+package payouts_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/chimoney/chimoney-go/modules/info"
+	"github.com/chimoney/chimoney-go/modules/payouts/giftcards"
+)
+
+const catalogResponse = `{
+	"status": "success",
+	"data": {
+		"benefitsList": [
+			{
+				"productId": 5,
+				"productName": "Amazon US",
+				"brand": {"brandId": 2, "brandName": "Amazon"},
+				"type": "giftcard",
+				"country": {"isoName": "US"},
+				"recipientCurrencyCode": "USD",
+				"senderCurrencyCode": "USD",
+				"fixedRecipientDenominations": [10, 25, 50],
+				"fixedSenderDenominations": [10.5, 26, 51.5]
+			},
+			{
+				"productId": "18",
+				"productName": "Jumia Nigeria",
+				"brand": "Jumia",
+				"type": "giftcard",
+				"countryCode": "NG",
+				"recipientCurrencyCode": "NGN",
+				"minRecipientDenomination": 5000,
+				"maxRecipientDenomination": 100000
+			},
+			{
+				"productId": 99,
+				"productName": "MTN Airtime",
+				"type": "airtime",
+				"countryCode": "NG",
+				"recipientCurrencyCode": "NGN"
+			}
+		]
+	}
+}`
+
+func TestSnapAmount(t *testing.T) {
+	fixed := info.Asset{ProductID: "5", FixedDenominations: []float64{50, 10, 25}}
+	ranged := info.Asset{ProductID: "18", MinDenomination: 5000, MaxDenomination: 100000}
+
+	tests := []struct {
+		name    string
+		product info.Asset
+		value   float64
+		snap    giftcards.Snap
+		want    float64
+		wantErr bool
+	}{
+		{name: "fixed exact", product: fixed, value: 25, snap: giftcards.Exact, want: 25},
+		{name: "fixed rejected", product: fixed, value: 30, snap: giftcards.Exact, wantErr: true},
+		{name: "fixed down", product: fixed, value: 30, snap: giftcards.Down, want: 25},
+		{name: "fixed up", product: fixed, value: 30, snap: giftcards.Up, want: 50},
+		{name: "fixed nearest", product: fixed, value: 45, snap: giftcards.Nearest, want: 50},
+		{name: "fixed nothing below", product: fixed, value: 5, snap: giftcards.Down, wantErr: true},
+		{name: "range inside", product: ranged, value: 7500.5, snap: giftcards.Exact, want: 7500.5},
+		{name: "range below min up", product: ranged, value: 1000, snap: giftcards.Up, want: 5000},
+		{name: "range below min down", product: ranged, value: 1000, snap: giftcards.Down, wantErr: true},
+		{name: "range above max nearest", product: ranged, value: 200000, snap: giftcards.Nearest, want: 100000},
+		{name: "zero", product: ranged, value: 0, snap: giftcards.Nearest, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := giftcards.SnapAmount(tt.product, tt.value, tt.snap)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SnapAmount() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, giftcards.ErrInvalidDenomination) {
+				t.Errorf("SnapAmount() error = %v, want %v", err, giftcards.ErrInvalidDenomination)
+			}
+			if got != tt.want {
+				t.Errorf("SnapAmount() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGiftCardCatalog(t *testing.T) {
+	calls := map[string]int{}
+	server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls[r.URL.Path]++
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/info/assets":
+			w.Write([]byte(catalogResponse))
+		case "/info/local-amount-in-usd":
+			w.Write([]byte(`{"status":"success","data":{"amountInUSD":6.6666}}`))
+		default:
+			t.Errorf("unexpected path: %v", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer server.Close()
+
+	ctx := context.Background()
+	catalog := giftcards.New(client.Info)
+
+	products, err := catalog.Products(ctx)
+	if err != nil {
+		t.Fatalf("Products() error = %v", err)
+	}
+	if len(products) != 2 {
+		t.Fatalf("expected airtime to be excluded, got %+v", products)
+	}
+	if products[0].ProductID != "5" || products[0].Brand != "Amazon" || products[0].CountryCode != "US" {
+		t.Errorf("unexpected product: %+v", products[0])
+	}
+
+	t.Run("search", func(t *testing.T) {
+		tests := []struct {
+			query giftcards.Query
+			want  []string
+		}{
+			{query: giftcards.Query{Brand: "amazon"}, want: []string{"5"}},
+			{query: giftcards.Query{Country: "ng"}, want: []string{"18"}},
+			{query: giftcards.Query{Denomination: 25}, want: []string{"5"}},
+			{query: giftcards.Query{Denomination: 20000}, want: []string{"18"}},
+			{query: giftcards.Query{Brand: "amazon", Denomination: 30}},
+		}
+		for _, tt := range tests {
+			got, err := catalog.Search(ctx, tt.query)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("Search(%+v) = %+v, want %v", tt.query, got, tt.want)
+				continue
+			}
+			for i := range got {
+				if got[i].ProductID != tt.want[i] {
+					t.Errorf("Search(%+v) = %+v, want %v", tt.query, got, tt.want)
+				}
+			}
+		}
+	})
+
+	t.Run("build with sender price", func(t *testing.T) {
+		payload, err := catalog.Build(ctx, giftcards.Card{Email: "jane@example.com", ProductID: "5", LocalValue: 30, Snap: giftcards.Down})
+		if err != nil {
+			t.Fatalf("Build() error = %v", err)
+		}
+		if payload.ValueInUSD != 26 || payload.RedeemData.ValueInLocalCurrency != 25 ||
+			payload.RedeemData.ProductID != "5" || payload.RedeemData.CountryCode != "US" {
+			t.Errorf("unexpected payload: %+v", payload)
+		}
+	})
+
+	t.Run("build with conversion", func(t *testing.T) {
+		payload, err := catalog.Build(ctx, giftcards.Card{Email: "jane@example.com", ProductID: "18", LocalValue: 10000})
+		if err != nil {
+			t.Fatalf("Build() error = %v", err)
+		}
+		if payload.ValueInUSD != 6.67 || payload.RedeemData.ValueInLocalCurrency != 10000 {
+			t.Errorf("unexpected payload: %+v", payload)
+		}
+	})
+
+	t.Run("build errors", func(t *testing.T) {
+		if _, err := catalog.Build(ctx, giftcards.Card{ProductID: "5", LocalValue: 25}); !errors.Is(err, giftcards.ErrInvalidRecipient) {
+			t.Errorf("Build() error = %v, want %v", err, giftcards.ErrInvalidRecipient)
+		}
+		if _, err := catalog.Build(ctx, giftcards.Card{Email: "jane@example.com", ProductID: "404", LocalValue: 25}); !errors.Is(err, giftcards.ErrProductNotFound) {
+			t.Errorf("Build() error = %v, want %v", err, giftcards.ErrProductNotFound)
+		}
+		if _, err := catalog.Build(ctx, giftcards.Card{Email: "jane@example.com", ProductID: "5", LocalValue: 30}); !errors.Is(err, giftcards.ErrInvalidDenomination) {
+			t.Errorf("Build() error = %v, want %v", err, giftcards.ErrInvalidDenomination)
+		}
+	})
+
+	if calls["/info/assets"] != 1 {
+		t.Errorf("catalog fetched %d times, want 1", calls["/info/assets"])
+	}
+}
@@ -0,0 +1,133 @@
+package payouts_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/chimoney/chimoney-go/modules/payouts"
+)
+
+func TestInitiate(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        payouts.InitiateOptions
+		response    string
+		status      int
+		wantBody    map[string]interface{}
+		wantErr     bool
+		wantLink    string
+		wantChiRefs []string
+	}{
+		{
+			name: "payment link and chiRefs",
+			opts: payouts.InitiateOptions{
+				Chimoneys: []payouts.ChimoneyPayload{
+					{Email: "jane@example.com", ValueInUSD: 10},
+					{Twitter: "@jane", ValueInUSD: 5},
+				},
+				TurnOffNotification: true,
+				RedirectURL:         "https://example.com/done",
+				SubAccount:          "sub_1",
+				Extra:               map[string]interface{}{"narration": "March payroll"},
+			},
+			response: `{
+				"status": "success",
+				"data": {
+					"paymentLink": "https://pay.chimoney.io/issue_1",
+					"issueID": "issue_1",
+					"error": "None",
+					"chimoneys": [
+						{"chiRef": "chi_1", "valueInUSD": 10, "redeemLink": "https://chimoney.io/redeem/chi_1"},
+						{"chiRef": "chi_2", "valueInUSD": 5}
+					]
+				}
+			}`,
+			wantBody: map[string]interface{}{
+				"turnOffNotification": true,
+				"redirect_url":        "https://example.com/done",
+				"subAccount":          "sub_1",
+				"narration":           "March payroll",
+			},
+			wantLink:    "https://pay.chimoney.io/issue_1",
+			wantChiRefs: []string{"chi_1", "chi_2"},
+		},
+		{
+			name:     "optional fields omitted",
+			opts:     payouts.InitiateOptions{Chimoneys: []payouts.ChimoneyPayload{{Email: "jane@example.com", ValueInUSD: 10}}},
+			response: `{"status":"success","data":{"chimoneys":[{"chiRef":"chi_1"}]}}`,
+			wantBody: map[string]interface{}{
+				"turnOffNotification": false,
+			},
+			wantChiRefs: []string{"chi_1"},
+		},
+		{
+			name:     "unexpected data is returned raw",
+			opts:     payouts.InitiateOptions{Chimoneys: []payouts.ChimoneyPayload{{Email: "jane@example.com", ValueInUSD: 10}}},
+			response: `{"status":"success","data":"queued"}`,
+			wantBody: map[string]interface{}{
+				"turnOffNotification": false,
+			},
+		},
+		{
+			name:     "api error",
+			opts:     payouts.InitiateOptions{Chimoneys: []payouts.ChimoneyPayload{{Email: "jane@example.com", ValueInUSD: 10}}},
+			response: `{"status":"error","message":"Insufficient balance"}`,
+			status:   http.StatusBadRequest,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/payouts/initiate" {
+					t.Errorf("unexpected path: got %v want /payouts/initiate", r.URL.Path)
+				}
+
+				var reqBody map[string]interface{}
+				if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+					t.Errorf("failed to decode request body: %v", err)
+				}
+				chimoneys, _ := reqBody["chimoneys"].([]interface{})
+				if len(chimoneys) != len(tt.opts.Chimoneys) {
+					t.Errorf("unexpected chimoneys: %v", reqBody["chimoneys"])
+				}
+				delete(reqBody, "chimoneys")
+				if tt.wantBody != nil && !reflect.DeepEqual(reqBody, tt.wantBody) {
+					t.Errorf("unexpected request body: got %v want %v", reqBody, tt.wantBody)
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+				}
+				w.Write([]byte(tt.response))
+			})
+			defer server.Close()
+
+			resp, err := client.Payouts.Initiate(context.Background(), tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Initiate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if resp.Status != "success" {
+				t.Errorf("unexpected status: got %q want success", resp.Status)
+			}
+			if resp.PaymentLink != tt.wantLink {
+				t.Errorf("unexpected payment link: got %q want %q", resp.PaymentLink, tt.wantLink)
+			}
+			if !reflect.DeepEqual(resp.ChiRefs, tt.wantChiRefs) {
+				t.Errorf("unexpected chiRefs: got %v want %v", resp.ChiRefs, tt.wantChiRefs)
+			}
+			if len(resp.Chimoneys) != len(tt.wantChiRefs) {
+				t.Errorf("unexpected chimoneys: %+v", resp.Chimoneys)
+			}
+		})
+	}
+}
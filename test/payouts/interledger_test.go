@@ -0,0 +1,160 @@
+package payouts_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/chimoney/chimoney-go/modules/payouts"
+)
+
+func TestResolveWalletAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		want    string
+		wantErr bool
+	}{
+		{address: "$ilp.chimoney.com/alice", want: "https://ilp.chimoney.com/alice"},
+		{address: "$wallet.example.com", want: "https://wallet.example.com/.well-known/pay"},
+		{address: "https://ilp.interledger-test.dev/bob", want: "https://ilp.interledger-test.dev/bob"},
+		{address: "http://ilp.chimoney.com/alice", wantErr: true},
+		{address: "$not a host/alice", wantErr: true},
+		{address: "https://ilp.chimoney.com/alice?x=1", wantErr: true},
+		{address: "alice@example.com", wantErr: true},
+		{address: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			got, err := payouts.ResolveWalletAddress(tt.address)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveWalletAddress() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, payouts.ErrInvalidWalletAddress) {
+				t.Errorf("ResolveWalletAddress() error = %v, want %v", err, payouts.ErrInvalidWalletAddress)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveWalletAddress() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInterledgerWalletAddress(t *testing.T) {
+	tests := []struct {
+		name       string
+		wallets    []payouts.InterledgerPayload
+		subAccount string
+		response   string
+		status     int
+		wantErr    error
+		wantAPIErr bool
+		wantChiRef []string
+	}{
+		{
+			name: "successful payouts",
+			wallets: []payouts.InterledgerPayload{
+				{WalletAddress: "$ilp.chimoney.com/alice", ValueInUSD: 10, Narration: "March"},
+				{WalletAddress: "https://ilp.chimoney.com/bob", Currency: "NGN", AmountToDeliver: 15000},
+			},
+			subAccount: "sub_123",
+			response: `{
+				"status": "success",
+				"data": {
+					"issueID": "issue_1",
+					"paymentLink": "https://pay.chimoney.io/issue_1",
+					"chimoneys": [
+						{"chiRef": "chi_1", "valueInUSD": 10, "status": "pending"},
+						{"chiRef": "chi_2", "valueInUSD": 10.1, "status": "pending"}
+					]
+				}
+			}`,
+			wantChiRef: []string{"chi_1", "chi_2"},
+		},
+		{
+			name:    "invalid wallet address",
+			wallets: []payouts.InterledgerPayload{{WalletAddress: "ilp.chimoney.com/alice", ValueInUSD: 10}},
+			wantErr: payouts.ErrInvalidWalletAddress,
+		},
+		{
+			name:    "missing amount",
+			wallets: []payouts.InterledgerPayload{{WalletAddress: "$ilp.chimoney.com/alice"}},
+			wantErr: payouts.ErrInvalidPayoutAmount,
+		},
+		{
+			name:    "no wallets",
+			wantErr: payouts.ErrEmptyPayout,
+		},
+		{
+			name:       "api error",
+			wallets:    []payouts.InterledgerPayload{{WalletAddress: "$ilp.chimoney.com/alice", ValueInUSD: 10}},
+			response:   `{"status":"error","message":"Wallet address not reachable"}`,
+			status:     http.StatusBadRequest,
+			wantAPIErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				if r.URL.Path != "/payouts/interledger-wallet-address" {
+					t.Errorf("unexpected path: got %v want /payouts/interledger-wallet-address", r.URL.Path)
+				}
+
+				var reqBody struct {
+					Wallets    []payouts.InterledgerPayload `json:"interledgerWallets"`
+					SubAccount string                       `json:"subAccount"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+					t.Errorf("failed to decode request body: %v", err)
+				}
+				if len(reqBody.Wallets) != len(tt.wallets) {
+					t.Errorf("unexpected number of wallets: got %v want %v", len(reqBody.Wallets), len(tt.wallets))
+				}
+				if reqBody.SubAccount != tt.subAccount {
+					t.Errorf("unexpected subAccount: got %v want %v", reqBody.SubAccount, tt.subAccount)
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+				}
+				w.Write([]byte(tt.response))
+			})
+			defer server.Close()
+
+			resp, err := client.Payouts.InterledgerWalletAddress(context.Background(), tt.wallets, tt.subAccount)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("InterledgerWalletAddress() error = %v, want %v", err, tt.wantErr)
+				}
+				if called {
+					t.Error("invalid payout was sent to the API")
+				}
+				return
+			}
+			if (err != nil) != tt.wantAPIErr {
+				t.Fatalf("InterledgerWalletAddress() error = %v, wantErr %v", err, tt.wantAPIErr)
+			}
+			if tt.wantAPIErr {
+				return
+			}
+
+			if resp.IssueID != "issue_1" || resp.PaymentLink == "" {
+				t.Errorf("unexpected issue or payment link: %q %q", resp.IssueID, resp.PaymentLink)
+			}
+			if len(resp.Results) != len(tt.wantChiRef) {
+				t.Fatalf("unexpected results: %+v", resp.Results)
+			}
+			for i, r := range resp.Results {
+				if r.ChiRef != tt.wantChiRef[i] || r.WalletAddress != tt.wallets[i].WalletAddress || r.IssueID != "issue_1" {
+					t.Errorf("unexpected result %d: %+v", i, r)
+				}
+			}
+		})
+	}
+}
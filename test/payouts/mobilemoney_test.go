@@ -0,0 +1,175 @@
+package payouts_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/chimoney/chimoney-go/modules/info"
+	"github.com/chimoney/chimoney-go/modules/payouts"
+)
+
+func TestPayoutsMobileMoney(t *testing.T) {
+	tests := []struct {
+		name       string
+		momos      []payouts.MobileMoneyPayload
+		subAccount string
+		response   string
+		wantErr    error
+		wantSent   bool
+		wantChiRef []string
+	}{
+		{
+			name: "successful payouts",
+			momos: []payouts.MobileMoneyPayload{
+				{CountryToSend: "GH", PhoneNumber: "+233241234567", MomoCode: "MTN", ValueInUSD: 10, Reference: "inv-1"},
+				{CountryToSend: "KE", PhoneNumber: "+254712345678", MomoCode: "mpesa", ValueInUSD: 5},
+			},
+			subAccount: "sub_123",
+			response: `{
+				"status": "success",
+				"data": {
+					"issueID": "issue_1",
+					"chimoneys": [
+						{"chiRef": "chi_1", "valueInUSD": 10, "status": "pending"},
+						{"chiRef": "chi_2", "valueInUSD": 5, "status": "pending"}
+					]
+				}
+			}`,
+			wantSent:   true,
+			wantChiRef: []string{"chi_1", "chi_2"},
+		},
+		{
+			name:    "provider not offered in country",
+			momos:   []payouts.MobileMoneyPayload{{CountryToSend: "KE", PhoneNumber: "+254712345678", MomoCode: "MTN", ValueInUSD: 5}},
+			wantErr: payouts.ErrUnsupportedProvider,
+		},
+		{
+			name:    "missing provider",
+			momos:   []payouts.MobileMoneyPayload{{CountryToSend: "GH", PhoneNumber: "+233241234567", ValueInUSD: 5}},
+			wantErr: payouts.ErrUnsupportedProvider,
+		},
+		{
+			name:    "missing phone number",
+			momos:   []payouts.MobileMoneyPayload{{CountryToSend: "GH", MomoCode: "MTN", ValueInUSD: 5}},
+			wantErr: payouts.ErrInvalidPhoneNumber,
+		},
+		{
+			name:    "invalid amount",
+			momos:   []payouts.MobileMoneyPayload{{CountryToSend: "GH", PhoneNumber: "+233241234567", MomoCode: "MTN"}},
+			wantErr: payouts.ErrInvalidPayoutAmount,
+		},
+		{
+			name:    "no payouts",
+			wantErr: payouts.ErrEmptyPayout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := map[string]int{}
+			server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				calls[r.URL.Path]++
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/info/mobile-money-codes":
+					w.Write([]byte(`{"status":"success","data":[
+						{"code":"MTN","name":"MTN Mobile Money","country":"GH"},
+						{"code":"MPESA","name":"M-Pesa","country":"KE"}
+					]}`))
+				case "/payouts/mobile-money":
+					var reqBody struct {
+						Momos      []payouts.MobileMoneyPayload `json:"momos"`
+						SubAccount string                       `json:"subAccount"`
+					}
+					if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+						t.Errorf("failed to decode request body: %v", err)
+					}
+					if len(reqBody.Momos) != len(tt.momos) {
+						t.Errorf("unexpected number of payouts: got %v want %v", len(reqBody.Momos), len(tt.momos))
+					}
+					if reqBody.SubAccount != tt.subAccount {
+						t.Errorf("unexpected subAccount: got %v want %v", reqBody.SubAccount, tt.subAccount)
+					}
+					w.Write([]byte(tt.response))
+				default:
+					t.Errorf("unexpected path: %v", r.URL.Path)
+					w.WriteHeader(http.StatusNotFound)
+				}
+			})
+			defer server.Close()
+
+			resp, err := client.Payouts.MobileMoney(context.Background(), tt.momos, tt.subAccount)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("MobileMoney() error = %v, want %v", err, tt.wantErr)
+			}
+			if sent := calls["/payouts/mobile-money"] > 0; sent != tt.wantSent {
+				t.Errorf("payout sent = %v, want %v", sent, tt.wantSent)
+			}
+			if err != nil {
+				return
+			}
+
+			if resp.IssueID != "issue_1" || len(resp.Results) != len(tt.wantChiRef) {
+				t.Fatalf("unexpected response: %+v", resp)
+			}
+			for i, r := range resp.Results {
+				if r.ChiRef != tt.wantChiRef[i] || r.PhoneNumber != tt.momos[i].PhoneNumber {
+					t.Errorf("unexpected result %d: %+v", i, r)
+				}
+			}
+
+			// Provider codes are cached across calls.
+			client.Payouts.MobileMoney(context.Background(), tt.momos, tt.subAccount)
+			if calls["/info/mobile-money-codes"] != 1 {
+				t.Errorf("mobile money codes fetched %d times, want 1", calls["/info/mobile-money-codes"])
+			}
+		})
+	}
+}
+
+func TestPayoutsMobileMoneySharedCache(t *testing.T) {
+	calls := map[string]int{}
+	server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls[r.URL.Path]++
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/info/mobile-money-codes":
+			w.Write([]byte(`{"status":"success","data":[{"code":"MPS","name":"M-Pesa","country":"KE"}]}`))
+		default:
+			w.Write([]byte(`{"status":"success","data":"accepted"}`))
+		}
+	})
+	defer server.Close()
+
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	cache := info.NewCache(client.Info, info.WithClock(func() time.Time { return now }))
+	client.Payouts.SetInfoCache(cache)
+	if client.Payouts.InfoCache() != cache {
+		t.Fatal("InfoCache() does not return the shared cache")
+	}
+
+	momos := []payouts.MobileMoneyPayload{{CountryToSend: "KE", PhoneNumber: "+254712345678", MomoCode: "MPS", ValueInUSD: 5}}
+	for i := 0; i < 2; i++ {
+		// Response data in an unexpected shape still returns the response.
+		resp, err := client.Payouts.MobileMoney(context.Background(), momos, "")
+		if err != nil || resp.Status != "success" {
+			t.Fatalf("MobileMoney() = %+v, %v", resp, err)
+		}
+	}
+	if calls["/info/mobile-money-codes"] != 1 {
+		t.Errorf("mobile money codes fetched %d times, want 1", calls["/info/mobile-money-codes"])
+	}
+
+	// The cache's clock decides when the codes are stale.
+	now = now.Add(2 * time.Hour)
+	if _, err := client.Payouts.MobileMoney(context.Background(), momos, ""); err != nil {
+		t.Fatalf("MobileMoney() error = %v", err)
+	}
+	if calls["/info/mobile-money-codes"] != 2 {
+		t.Errorf("mobile money codes fetched %d times, want 2", calls["/info/mobile-money-codes"])
+	}
+}
@@ -0,0 +1,145 @@
+package payouts_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/chimoney/chimoney-go/modules/payouts"
+)
+
+func TestQuote(t *testing.T) {
+	rates := map[string]float64{"NGN": 1500, "GHS": 12.5}
+	calls := map[string]int{}
+	server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/info/usd-in-local-amount" {
+			t.Errorf("unexpected path: got %v want /info/usd-in-local-amount", r.URL.Path)
+		}
+		var reqBody struct {
+			DestinationCurrency string  `json:"destinationCurrency"`
+			AmountInUSD         float64 `json:"amountInUSD"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			t.Errorf("failed to decode request body: %v", err)
+		}
+		calls[reqBody.DestinationCurrency]++
+
+		w.Header().Set("Content-Type", "application/json")
+		rate, ok := rates[reqBody.DestinationCurrency]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":"error","message":"Invalid currency"}`))
+			return
+		}
+		fmt.Fprintf(w, `{"status":"success","data":{"amountInDestinationCurrency":%v}}`, rate*reqBody.AmountInUSD)
+	})
+	defer server.Close()
+
+	items := []payouts.QuoteItem{
+		{Reference: "a", Currency: "ngn", ValueInUSD: 10},
+		{Reference: "b", Currency: "NGN", ValueInUSD: 2, Denominations: &payouts.Denominations{Min: 5000, Max: 50000}},
+		{Reference: "c", Currency: "GHS", ValueInUSD: 4, Denominations: &payouts.Denominations{Fixed: []float64{50, 100}}},
+		{Reference: "d", Currency: "GHS", ValueInUSD: 3, Denominations: &payouts.Denominations{Fixed: []float64{50, 100}}},
+	}
+
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	client.Payouts.SetClock(func() time.Time { return now })
+	ctx := context.Background()
+	q, err := client.Payouts.Quote(ctx, items)
+	if err != nil {
+		t.Fatalf("Quote() error = %v", err)
+	}
+
+	if calls["NGN"] != 1 || calls["GHS"] != 1 {
+		t.Errorf("rates were not deduplicated per currency: %v", calls)
+	}
+	if !q.CreatedAt.Equal(now) || !q.ExpiresAt.Equal(now.Add(payouts.QuoteTTL)) {
+		t.Errorf("unexpected quote validity: %v to %v", q.CreatedAt, q.ExpiresAt)
+	}
+	if q.TotalUSD != 19 {
+		t.Errorf("unexpected total USD: got %v want 19", q.TotalUSD)
+	}
+	if q.LocalTotals["NGN"] != 18000 || q.LocalTotals["GHS"] != 87.5 {
+		t.Errorf("unexpected local totals: %v", q.LocalTotals)
+	}
+	wantOutOfRange := []bool{false, true, false, true}
+	for i, line := range q.Lines {
+		if line.OutOfRange != wantOutOfRange[i] {
+			t.Errorf("line %s out of range = %v, want %v (%s)", line.Reference, line.OutOfRange, wantOutOfRange[i], line.RangeMessage)
+		}
+	}
+	if q.OutOfRange != 2 {
+		t.Errorf("unexpected out of range count: got %d want 2", q.OutOfRange)
+	}
+
+	// Rates are cached between quotes.
+	if _, err := client.Payouts.Quote(ctx, items[:1]); err != nil {
+		t.Fatalf("second Quote() error = %v", err)
+	}
+	if calls["NGN"] != 1 {
+		t.Errorf("rate was fetched again instead of cached: %v", calls)
+	}
+
+	if err := client.Payouts.CheckQuote(ctx, q, 0.01); err != nil {
+		t.Errorf("CheckQuote() error = %v", err)
+	}
+
+	rates["NGN"] = 1600
+	if err := client.Payouts.CheckQuote(ctx, q, 0.01); !errors.Is(err, payouts.ErrRateDrift) {
+		t.Errorf("CheckQuote() after drift error = %v, want %v", err, payouts.ErrRateDrift)
+	}
+
+	now = now.Add(payouts.QuoteTTL + time.Second)
+	if err := client.Payouts.CheckQuote(ctx, q, 0.5); !errors.Is(err, payouts.ErrQuoteExpired) {
+		t.Errorf("CheckQuote() after expiry error = %v, want %v", err, payouts.ErrQuoteExpired)
+	}
+
+	// Cached rates expire with the quotes built from them.
+	fetched := calls["GHS"]
+	if _, err := client.Payouts.Quote(ctx, items[2:3]); err != nil || calls["GHS"] != fetched+1 {
+		t.Errorf("Quote() after expiry = %v, GHS fetched %d more times", err, calls["GHS"]-fetched)
+	}
+
+	if _, err := client.Payouts.Quote(ctx, []payouts.QuoteItem{{Currency: "XXX", ValueInUSD: 1}}); err == nil {
+		t.Error("Quote() with unknown currency should fail")
+	}
+	if _, err := client.Payouts.Quote(ctx, nil); !errors.Is(err, payouts.ErrEmptyQuote) {
+		t.Errorf("Quote() with no items error = %v, want %v", err, payouts.ErrEmptyQuote)
+	}
+}
+
+func TestQuoteSlowRate(t *testing.T) {
+	fetching, unblock := make(chan struct{}), make(chan struct{})
+	server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		close(fetching)
+		<-unblock
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"success","data":{"amountInDestinationCurrency":1500}}`))
+	})
+	defer server.Close()
+
+	done := make(chan error)
+	go func() {
+		_, err := client.Payouts.Quote(context.Background(), []payouts.QuoteItem{{Currency: "NGN", ValueInUSD: 1}})
+		done <- err
+	}()
+	<-fetching
+
+	// Other calls sharing the module's lock go ahead while the rate is fetched.
+	audit := make(chan payouts.AuditTrail)
+	go func() { audit <- client.Payouts.AuditTrail() }()
+	select {
+	case <-audit:
+	case <-time.After(time.Second):
+		t.Error("AuditTrail() blocked behind the rate lookup")
+	}
+
+	close(unblock)
+	if err := <-done; err != nil {
+		t.Errorf("Quote() error = %v", err)
+	}
+}
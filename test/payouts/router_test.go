@@ -0,0 +1,178 @@
+package payouts_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/chimoney/chimoney-go/modules/info"
+	"github.com/chimoney/chimoney-go/modules/payouts/router"
+)
+
+func TestRouterSend(t *testing.T) {
+	tests := []struct {
+		name         string
+		recipient    router.Recipient
+		wantRail     router.Rail
+		wantAttempts int
+		wantErr      error
+	}{
+		{
+			name: "nigeria prefers bank",
+			recipient: router.Recipient{
+				Country:       "NG",
+				BankCode:      "044",
+				AccountNumber: "1234567890",
+				PhoneNumber:   "+2348123456789",
+			},
+			wantRail:     router.RailBank,
+			wantAttempts: 1,
+		},
+		{
+			name: "ghana prefers mobile money",
+			recipient: router.Recipient{
+				Country:         "GH",
+				PhoneNumber:     "+233241234567",
+				MobileMoneyCode: "MTN",
+			},
+			wantRail:     router.RailMobileMoney,
+			wantAttempts: 1,
+		},
+		{
+			name: "unsupported bank falls back to airtime",
+			recipient: router.Recipient{
+				Country:       "NG",
+				BankCode:      "999",
+				AccountNumber: "1234567890",
+				PhoneNumber:   "+2348123456789",
+			},
+			wantRail:     router.RailAirtime,
+			wantAttempts: 2,
+		},
+		{
+			name: "falls back to chimoney email claim",
+			recipient: router.Recipient{
+				Country: "ZA",
+				Email:   "jane@example.com",
+			},
+			wantRail:     router.RailChimoney,
+			wantAttempts: 4,
+		},
+		{
+			name:         "no route",
+			recipient:    router.Recipient{Country: "ZA"},
+			wantAttempts: 4,
+			wantErr:      router.ErrNoRoute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := map[string]int{}
+			server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				calls[r.URL.Path]++
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/info/country-banks":
+					w.Write([]byte(`{"status":"success","data":[{"code":"044","name":"Access Bank","country":"NG"}]}`))
+				case "/info/mobile-money-codes":
+					w.Write([]byte(`{"status":"success","data":[{"code":"MTN","name":"MTN","country":"GH"}]}`))
+				case "/info/airtime-countries":
+					w.Write([]byte(`{"status":"success","data":["NG","GH"]}`))
+				case "/payouts/bank", "/payouts/mobile-money", "/payouts/airtime", "/payouts/chimoney":
+					var reqBody map[string]interface{}
+					if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+						t.Errorf("failed to decode request body: %v", err)
+					}
+					w.Write([]byte(`{"status":"success","data":{"chimoneys":[]}}`))
+				default:
+					t.Errorf("unexpected path: %v", r.URL.Path)
+					w.WriteHeader(http.StatusNotFound)
+				}
+			})
+			defer server.Close()
+
+			rt := router.New(client.Info, client.Payouts)
+			result, err := rt.Send(context.Background(), tt.recipient, 25, "")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Send() error = %v, want %v", err, tt.wantErr)
+			}
+			if result.Rail != tt.wantRail {
+				t.Errorf("unexpected rail: got %v want %v", result.Rail, tt.wantRail)
+			}
+			if len(result.Attempts) != tt.wantAttempts {
+				t.Errorf("unexpected attempts: got %+v want %d", result.Attempts, tt.wantAttempts)
+			}
+			if tt.wantErr == nil {
+				if result.Response == nil {
+					t.Error("Send() got nil response")
+				}
+				path := "/payouts/" + strings.ReplaceAll(string(tt.wantRail), "_", "-")
+				if calls[path] != 1 {
+					t.Errorf("expected one %v payout, got calls %v", tt.wantRail, calls)
+				}
+			}
+
+			// Capability data is cached across calls.
+			before := calls["/info/airtime-countries"]
+			rt.Route(context.Background(), tt.recipient, 25)
+			if calls["/info/airtime-countries"] != before {
+				t.Error("airtime countries were fetched again instead of cached")
+			}
+		})
+	}
+}
+
+func TestRouterSharedInfoCache(t *testing.T) {
+	calls := map[string]int{}
+	server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls[r.URL.Path]++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"success","data":[{"code":"MTN","name":"MTN","country":"GH"}]}`))
+	})
+	defer server.Close()
+
+	cache := info.NewCache(client.Info)
+	recipient := router.Recipient{Country: "GH", PhoneNumber: "+233241234567", MobileMoneyCode: "MTN"}
+	for i := 0; i < 2; i++ {
+		rt := router.New(client.Info, client.Payouts, router.WithInfoCache(cache))
+		if d, err := rt.Route(context.Background(), recipient, 25); err != nil || d.Rail != router.RailMobileMoney {
+			t.Fatalf("Route() = %+v, %v", d, err)
+		}
+	}
+	if calls["/info/mobile-money-codes"] != 1 {
+		t.Errorf("routers sharing a cache fetched the codes %d times", calls["/info/mobile-money-codes"])
+	}
+}
+
+func TestRouterLookupFailureFallsBack(t *testing.T) {
+	server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/info/country-banks":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"status":"error","error":"unavailable"}`))
+		case "/info/airtime-countries":
+			w.Write([]byte(`{"status":"success","data":["NG"]}`))
+		default:
+			t.Errorf("unexpected path: %v", r.URL.Path)
+		}
+	})
+	defer server.Close()
+
+	rt := router.New(client.Info, client.Payouts)
+	recipient := router.Recipient{Country: "NG", BankCode: "044", AccountNumber: "1234567890", PhoneNumber: "+2348123456789"}
+	d, err := rt.Route(context.Background(), recipient, 25)
+	if err != nil {
+		t.Fatalf("Route() error = %v", err)
+	}
+	if d.Rail != router.RailAirtime || len(d.Attempts) != 2 {
+		t.Fatalf("unexpected decision: %+v", d)
+	}
+	if bank := d.Attempts[0]; bank.Eligible || !strings.Contains(bank.Reason, "could not list banks") {
+		t.Errorf("unexpected bank attempt: %+v", bank)
+	}
+}
@@ -0,0 +1,388 @@
+package payouts_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/chimoney/chimoney-go/modules/payouts"
+	"github.com/chimoney/chimoney-go/modules/payouts/schedule"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func TestParseSchedule(t *testing.T) {
+	lagos, _ := time.LoadLocation("Africa/Lagos")
+	from := time.Date(2024, 1, 1, 12, 0, 0, 0, lagos) // Monday
+
+	tests := []struct {
+		expr    string
+		want    time.Time
+		wantErr bool
+	}{
+		{expr: "0 9 * * 1", want: time.Date(2024, 1, 8, 9, 0, 0, 0, lagos)},
+		{expr: "*/15 * * * *", want: time.Date(2024, 1, 1, 12, 15, 0, 0, lagos)},
+		{expr: "0 0 1 * *", want: time.Date(2024, 2, 1, 0, 0, 0, 0, lagos)},
+		{expr: "30 8 * * 7", want: time.Date(2024, 1, 7, 8, 30, 0, 0, lagos)},
+		{expr: "0 10 15 * 3", want: time.Date(2024, 1, 3, 10, 0, 0, 0, lagos)},
+		{expr: "@daily", want: time.Date(2024, 1, 2, 0, 0, 0, 0, lagos)},
+		{expr: "0 9 * *", wantErr: true},
+		{expr: "61 * * * *", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			spec, err := schedule.Parse(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := spec.Next(from, lagos); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	var banks [][]payouts.BankPayload
+	server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/info/local-amount-in-usd":
+			w.Write([]byte(`{"status":"success","data":{"amountInUSD":20}}`))
+		case "/payouts/bank":
+			var reqBody struct {
+				Banks []payouts.BankPayload `json:"banks"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+				t.Errorf("failed to decode request body: %v", err)
+			}
+			banks = append(banks, reqBody.Banks)
+			w.Write([]byte(`{"status":"success","data":{}}`))
+		default:
+			t.Errorf("unexpected path: %v", r.URL.Path)
+		}
+	})
+	defer server.Close()
+
+	plan := func(policy schedule.MissedRunPolicy) schedule.Plan {
+		return schedule.Plan{
+			ID:       "weekly-contractors",
+			Schedule: "0 9 * * 1",
+			TimeZone: "Africa/Lagos",
+			StartAt:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			Entries: []schedule.Entry{
+				{Bank: &payouts.BankPayload{CountryToSend: "NG", AccountBank: "044", AccountNumber: "1", ValueInUSD: 100}},
+				{Bank: &payouts.BankPayload{CountryToSend: "NG", AccountBank: "044", AccountNumber: "2"}, LocalAmount: 30000, Currency: "NGN"},
+			},
+			MissedRuns: policy,
+		}
+	}
+
+	tests := []struct {
+		name        string
+		policy      schedule.MissedRunPolicy
+		wantRuns    int
+		wantSkipped int
+	}{
+		{name: "catch up all", policy: schedule.CatchUpAll, wantRuns: 3},
+		{name: "catch up latest", policy: schedule.CatchUpLatest, wantRuns: 3, wantSkipped: 2},
+		{name: "skip missed", policy: schedule.SkipMissed, wantRuns: 3, wantSkipped: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			banks = nil
+			store, err := schedule.NewFileStore(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+			s := schedule.New(client.Payouts, client.Info, schedule.WithStore(store), schedule.WithClock(clock))
+			if err := s.Add(plan(tt.policy)); err != nil {
+				t.Fatalf("Add() error = %v", err)
+			}
+
+			// Three Mondays were missed while the process was down.
+			clock.now = time.Date(2024, 1, 17, 12, 0, 0, 0, time.UTC)
+			runs, err := s.Tick(context.Background())
+			if err != nil {
+				t.Fatalf("Tick() error = %v", err)
+			}
+			if len(runs) != tt.wantRuns {
+				t.Fatalf("unexpected runs: got %d want %d", len(runs), tt.wantRuns)
+			}
+			skipped := 0
+			for _, r := range runs {
+				if r.Skipped {
+					skipped++
+				} else if r.Err != nil || r.Bank == nil {
+					t.Errorf("run at %v failed: %v", r.ScheduledAt, r.Err)
+				}
+			}
+			if skipped != tt.wantSkipped {
+				t.Errorf("unexpected skipped runs: got %d want %d", skipped, tt.wantSkipped)
+			}
+			if len(banks) != tt.wantRuns-tt.wantSkipped {
+				t.Errorf("unexpected payouts: got %d want %d", len(banks), tt.wantRuns-tt.wantSkipped)
+			}
+			for _, b := range banks {
+				if b[1].ValueInUSD != 20 {
+					t.Errorf("local amount not converted: got %v want 20", b[1].ValueInUSD)
+				}
+			}
+
+			// A restarted scheduler sharing the store does not execute the runs again.
+			restarted := schedule.New(client.Payouts, client.Info, schedule.WithStore(store), schedule.WithClock(clock))
+			if err := restarted.Add(plan(tt.policy)); err != nil {
+				t.Fatalf("Add() error = %v", err)
+			}
+			runs, err = restarted.Tick(context.Background())
+			if err != nil {
+				t.Fatalf("Tick() error = %v", err)
+			}
+			if len(runs) != 0 {
+				t.Errorf("restarted scheduler re-ran %d runs", len(runs))
+			}
+
+			// The next Monday runs on time.
+			clock.now = time.Date(2024, 1, 22, 8, 1, 0, 0, time.UTC)
+			runs, err = restarted.Tick(context.Background())
+			if err != nil {
+				t.Fatalf("Tick() error = %v", err)
+			}
+			if len(runs) != 1 || runs[0].Skipped {
+				t.Errorf("expected one on-time run, got %+v", runs)
+			}
+		})
+	}
+}
+
+func TestSchedulerRestartWithoutStartAt(t *testing.T) {
+	server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"success","data":{}}`))
+	})
+	defer server.Close()
+
+	plan := func(policy schedule.MissedRunPolicy) schedule.Plan {
+		return schedule.Plan{
+			ID:       "weekly-contractors",
+			Schedule: "0 9 * * 1",
+			TimeZone: "Africa/Lagos",
+			Entries: []schedule.Entry{
+				{Bank: &payouts.BankPayload{CountryToSend: "NG", AccountBank: "044", AccountNumber: "1", ValueInUSD: 100}},
+			},
+			MissedRuns: policy,
+		}
+	}
+
+	tests := []struct {
+		name        string
+		policy      schedule.MissedRunPolicy
+		wantRuns    int
+		wantSkipped int
+	}{
+		{name: "catch up all", policy: schedule.CatchUpAll, wantRuns: 2},
+		{name: "catch up latest", policy: schedule.CatchUpLatest, wantRuns: 2, wantSkipped: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := schedule.NewFileStore(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			clock := &fakeClock{now: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}
+			s := schedule.New(client.Payouts, client.Info, schedule.WithStore(store), schedule.WithClock(clock))
+			if err := s.Add(plan(tt.policy)); err != nil {
+				t.Fatalf("Add() error = %v", err)
+			}
+			clock.now = time.Date(2024, 1, 8, 12, 0, 0, 0, time.UTC)
+			if runs, err := s.Tick(context.Background()); err != nil || len(runs) != 1 {
+				t.Fatalf("Tick() = %d runs, %v", len(runs), err)
+			}
+
+			// The process was down for the next two Mondays and restarts
+			// with the plan added again, without StartAt.
+			clock.now = time.Date(2024, 1, 24, 12, 0, 0, 0, time.UTC)
+			restarted := schedule.New(client.Payouts, client.Info, schedule.WithStore(store), schedule.WithClock(clock))
+			if err := restarted.Add(plan(tt.policy)); err != nil {
+				t.Fatalf("Add() error = %v", err)
+			}
+			runs, err := restarted.Tick(context.Background())
+			if err != nil {
+				t.Fatalf("Tick() error = %v", err)
+			}
+			if len(runs) != tt.wantRuns {
+				t.Fatalf("unexpected runs after restart: got %d want %d", len(runs), tt.wantRuns)
+			}
+			skipped := 0
+			for _, r := range runs {
+				if r.Skipped {
+					skipped++
+				}
+			}
+			if skipped != tt.wantSkipped {
+				t.Errorf("unexpected skipped runs: got %d want %d", skipped, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestSchedulerRejectsInvalidPlans(t *testing.T) {
+	s := schedule.New(nil, nil)
+	entry := schedule.Entry{Chimoney: &payouts.ChimoneyPayload{ValueInUSD: 5, Email: "jane@example.com"}}
+
+	invalid := []schedule.Plan{
+		{ID: "", Schedule: "@daily", Entries: []schedule.Entry{entry}},
+		{ID: "p", Schedule: "@daily"},
+		{ID: "p", Schedule: "not a cron", Entries: []schedule.Entry{entry}},
+		{ID: "p", Schedule: "@daily", TimeZone: "Mars/Olympus", Entries: []schedule.Entry{entry}},
+		{ID: "p", Schedule: "@daily", Entries: []schedule.Entry{{}}},
+	}
+	for i, p := range invalid {
+		if err := s.Add(p); err == nil {
+			t.Errorf("Add() accepted invalid plan %d", i)
+		}
+	}
+
+	valid := schedule.Plan{ID: "p", Schedule: "@daily", Entries: []schedule.Entry{entry}}
+	if err := s.Add(valid); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := s.Add(valid); err != schedule.ErrDuplicatePlan {
+		t.Errorf("Add() duplicate error = %v, want %v", err, schedule.ErrDuplicatePlan)
+	}
+}
+
+func TestSchedulerCatchUpLatestBeyondLimit(t *testing.T) {
+	calls := 0
+	server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"success","data":{}}`))
+	})
+	defer server.Close()
+
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	s := schedule.New(client.Payouts, client.Info, schedule.WithClock(clock))
+	err := s.Add(schedule.Plan{
+		ID:         "every-minute",
+		Schedule:   "* * * * *",
+		TimeZone:   "UTC",
+		Entries:    []schedule.Entry{{Chimoney: &payouts.ChimoneyPayload{ValueInUSD: 5, Email: "jane@example.com"}}},
+		MissedRuns: schedule.CatchUpLatest,
+	})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	// Two days of minutes were missed, more than one tick considers.
+	clock.now = time.Date(2024, 1, 3, 0, 0, 30, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		if _, err := s.Tick(context.Background()); err != nil {
+			t.Fatalf("Tick() error = %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("unexpected payouts: got %d want 1", calls)
+	}
+}
+
+func TestSchedulerRetriesFailedRuns(t *testing.T) {
+	tests := []struct {
+		name      string
+		failPath  string
+		status    int
+		wantRetry bool
+	}{
+		{name: "conversion failed", failPath: "/info/local-amount-in-usd", status: http.StatusServiceUnavailable, wantRetry: true},
+		{name: "rate limited", failPath: "/payouts/bank", status: http.StatusTooManyRequests, wantRetry: true},
+		{name: "server error", failPath: "/payouts/bank", status: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		for _, useFiles := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/files=%v", tt.name, useFiles), func(t *testing.T) {
+				fail := true
+				paid := 0
+				server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "application/json")
+					if fail && r.URL.Path == tt.failPath {
+						fail = false
+						w.WriteHeader(tt.status)
+						w.Write([]byte(`{"status":"error","error":"try again"}`))
+						return
+					}
+					if r.URL.Path == "/payouts/bank" {
+						paid++
+					}
+					w.Write([]byte(`{"status":"success","data":{"amountInUSD":20}}`))
+				})
+				defer server.Close()
+
+				var store schedule.Store = schedule.NewMemoryStore()
+				if useFiles {
+					var err error
+					if store, err = schedule.NewFileStore(t.TempDir()); err != nil {
+						t.Fatal(err)
+					}
+				}
+				clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+				s := schedule.New(client.Payouts, client.Info, schedule.WithStore(store), schedule.WithClock(clock))
+				err := s.Add(schedule.Plan{
+					ID:       "daily",
+					Schedule: "@daily",
+					TimeZone: "UTC",
+					Entries: []schedule.Entry{
+						{Bank: &payouts.BankPayload{CountryToSend: "NG", AccountBank: "044", AccountNumber: "1"}, LocalAmount: 30000, Currency: "NGN"},
+					},
+				})
+				if err != nil {
+					t.Fatalf("Add() error = %v", err)
+				}
+
+				clock.now = time.Date(2024, 1, 2, 0, 1, 0, 0, time.UTC)
+				runs, err := s.Tick(context.Background())
+				if err != nil {
+					t.Fatalf("Tick() error = %v", err)
+				}
+				if len(runs) != 1 || runs[0].Err == nil || runs[0].Retry != tt.wantRetry {
+					t.Fatalf("unexpected first runs: %+v", runs)
+				}
+
+				// The next tick retries the failed run, and only that.
+				clock.now = clock.now.Add(time.Minute)
+				runs, err = s.Tick(context.Background())
+				if err != nil {
+					t.Fatalf("Tick() error = %v", err)
+				}
+				wantRuns, wantPaid := 0, 0
+				if tt.wantRetry {
+					wantRuns, wantPaid = 1, 1
+				}
+				if len(runs) != wantRuns || paid != wantPaid {
+					t.Fatalf("retry: got %d runs and %d payouts, want %d and %d", len(runs), paid, wantRuns, wantPaid)
+				}
+				if wantRuns == 1 && (runs[0].Err != nil || runs[0].Retry) {
+					t.Errorf("retried run failed: %+v", runs[0])
+				}
+
+				if runs, err := s.Tick(context.Background()); err != nil || len(runs) != 0 {
+					t.Errorf("third Tick() = %+v, %v", runs, err)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package policy_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chimoney/chimoney-go/modules/payouts"
+	"github.com/chimoney/chimoney-go/modules/policy"
+	"github.com/chimoney/chimoney-go/modules/wallet"
+	"github.com/chimoney/chimoney-go/test/testclient"
+)
+
+func setupTestServer(t *testing.T, handler http.HandlerFunc) (*httptest.Server, *testclient.Client) {
+	server := httptest.NewServer(handler)
+	client := testclient.New(
+		testclient.WithTestServer(server.URL),
+		testclient.WithAPIKey("test-api-key"),
+	)
+	return server, client
+}
+
+func TestEnforcer(t *testing.T) {
+	// Wednesday, 10:00 in Lagos.
+	wednesday := time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		policy   policy.Policy
+		now      time.Time
+		banks    []payouts.BankPayload
+		wantRule policy.Rule
+		wantSent int
+	}{
+		{
+			name:     "within limits",
+			policy:   policy.Policy{MaxItemUSD: 100},
+			now:      wednesday,
+			banks:    []payouts.BankPayload{{CountryToSend: "NG", AccountBank: "044", AccountNumber: "1", ValueInUSD: 50}},
+			wantSent: 1,
+		},
+		{
+			name:     "item cap",
+			policy:   policy.Policy{MaxItemUSD: 100},
+			now:      wednesday,
+			banks:    []payouts.BankPayload{{CountryToSend: "NG", AccountBank: "044", AccountNumber: "1", ValueInUSD: 50000}},
+			wantRule: policy.RuleMaxItem,
+		},
+		{
+			name:   "batch total",
+			policy: policy.Policy{MaxBatchUSD: 100},
+			now:    wednesday,
+			banks: []payouts.BankPayload{
+				{CountryToSend: "NG", AccountBank: "044", AccountNumber: "1", ValueInUSD: 60},
+				{CountryToSend: "NG", AccountBank: "044", AccountNumber: "2", ValueInUSD: 60},
+			},
+			wantRule: policy.RuleMaxBatch,
+		},
+		{
+			name:     "denied country",
+			policy:   policy.Policy{DeniedCountries: []string{"ng"}},
+			now:      wednesday,
+			banks:    []payouts.BankPayload{{CountryToSend: "NG", AccountBank: "044", AccountNumber: "1", ValueInUSD: 5}},
+			wantRule: policy.RuleCountryDenyList,
+		},
+		{
+			name:     "country not allowed",
+			policy:   policy.Policy{AllowedCountries: []string{"GH"}},
+			now:      wednesday,
+			banks:    []payouts.BankPayload{{CountryToSend: "NG", AccountBank: "044", AccountNumber: "1", ValueInUSD: 5}},
+			wantRule: policy.RuleCountryAllowList,
+		},
+		{
+			name: "outside business hours",
+			policy: policy.Policy{BusinessHours: &policy.BusinessHours{
+				TimeZone: "Africa/Lagos",
+				Start:    "09:00",
+				End:      "17:00",
+			}},
+			now:      time.Date(2024, 1, 6, 12, 0, 0, 0, time.UTC),
+			banks:    []payouts.BankPayload{{CountryToSend: "NG", AccountBank: "044", AccountNumber: "1", ValueInUSD: 5}},
+			wantRule: policy.RuleBusinessHours,
+		},
+		{
+			name: "within business hours",
+			policy: policy.Policy{BusinessHours: &policy.BusinessHours{
+				TimeZone: "Africa/Lagos",
+				Start:    "09:00",
+				End:      "17:00",
+			}},
+			now:      wednesday,
+			banks:    []payouts.BankPayload{{CountryToSend: "NG", AccountBank: "044", AccountNumber: "1", ValueInUSD: 5}},
+			wantSent: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sent := 0
+			server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				sent++
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"status":"success","data":{}}`))
+			})
+			defer server.Close()
+
+			engine, err := policy.NewEngine(tt.policy, policy.WithClock(func() time.Time { return tt.now }))
+			if err != nil {
+				t.Fatalf("NewEngine() error = %v", err)
+			}
+			p := payouts.New(policy.Wrap(client, engine))
+
+			_, err = p.Bank(context.Background(), tt.banks, "")
+			var violation *policy.Violation
+			if tt.wantRule != "" {
+				if !errors.As(err, &violation) || violation.Rule != tt.wantRule {
+					t.Errorf("Bank() error = %v, want %v violation", err, tt.wantRule)
+				}
+				if !errors.Is(err, policy.ErrViolation) {
+					t.Errorf("Bank() error = %v does not match ErrViolation", err)
+				}
+			} else if err != nil {
+				t.Errorf("Bank() unexpected error = %v", err)
+			}
+			if sent != tt.wantSent {
+				t.Errorf("unexpected requests sent: got %d want %d", sent, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestEnforcerDailyLimits(t *testing.T) {
+	status := http.StatusOK
+	server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(`{"status":"success","data":{}}`))
+	})
+	defer server.Close()
+
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	engine, err := policy.NewEngine(policy.Policy{
+		DailySubAccountUSD: 100,
+		DailyRecipientUSD:  60,
+	}, policy.WithClock(func() time.Time { return now }))
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	p := payouts.New(policy.Wrap(client, engine))
+	ctx := context.Background()
+
+	jane := []payouts.ChimoneyPayload{{ValueInUSD: 40, Email: "Jane@example.com"}}
+	if _, err := p.Chimoney(ctx, jane, "sub_1"); err != nil {
+		t.Fatalf("first payout error = %v", err)
+	}
+
+	var violation *policy.Violation
+	_, err = p.Chimoney(ctx, []payouts.ChimoneyPayload{{ValueInUSD: 40, Email: "jane@example.com"}}, "sub_2")
+	if !errors.As(err, &violation) || violation.Rule != policy.RuleDailyRecipient {
+		t.Errorf("second payout to same recipient error = %v, want %v", err, policy.RuleDailyRecipient)
+	}
+
+	// A failed call does not count towards the limits.
+	status = http.StatusBadRequest
+	if _, err := p.Chimoney(ctx, []payouts.ChimoneyPayload{{ValueInUSD: 50, Email: "bob@example.com"}}, "sub_1"); err == nil {
+		t.Fatal("expected API error")
+	}
+	status = http.StatusOK
+	if _, err := p.Chimoney(ctx, []payouts.ChimoneyPayload{{ValueInUSD: 50, Email: "bob@example.com"}}, "sub_1"); err != nil {
+		t.Fatalf("retried payout error = %v", err)
+	}
+
+	// A server error may come after the money moved, so the amount still counts.
+	status = http.StatusInternalServerError
+	if _, err := p.Chimoney(ctx, []payouts.ChimoneyPayload{{ValueInUSD: 10, Email: "dave@example.com"}}, "sub_1"); err == nil {
+		t.Fatal("expected API error")
+	}
+	status = http.StatusOK
+	_, err = p.Chimoney(ctx, []payouts.ChimoneyPayload{{ValueInUSD: 1, Email: "erin@example.com"}}, "sub_1")
+	if !errors.As(err, &violation) || violation.Rule != policy.RuleDailySubAccount {
+		t.Errorf("payout after unknown outcome error = %v, want %v", err, policy.RuleDailySubAccount)
+	}
+
+	_, err = p.Chimoney(ctx, []payouts.ChimoneyPayload{{ValueInUSD: 20, Email: "carol@example.com"}}, "sub_1")
+	if !errors.As(err, &violation) || violation.Rule != policy.RuleDailySubAccount {
+		t.Errorf("sub-account over daily limit error = %v, want %v", err, policy.RuleDailySubAccount)
+	}
+
+	now = now.Add(25 * time.Hour)
+	if _, err := p.Chimoney(ctx, []payouts.ChimoneyPayload{{ValueInUSD: 20, Email: "carol@example.com"}}, "sub_1"); err != nil {
+		t.Errorf("payout after window rolled over error = %v", err)
+	}
+
+	// Non money-moving calls are not checked.
+	w := wallet.New(policy.Wrap(client, engine))
+	if _, err := w.List(ctx, ""); err != nil {
+		t.Errorf("List() error = %v", err)
+	}
+}
+
+func TestEnforcerNonUSDAmounts(t *testing.T) {
+	sent := 0
+	server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		sent++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"success","data":{}}`))
+	})
+	defer server.Close()
+
+	wallets := []payouts.InterledgerPayload{{WalletAddress: "$ilp.example.com/alice", AmountToDeliver: 500000, Currency: "NGN"}}
+	now := func() time.Time { return time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC) }
+
+	limited, err := policy.NewEngine(policy.Policy{MaxItemUSD: 100}, policy.WithClock(now))
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	_, err = payouts.New(policy.Wrap(client, limited)).InterledgerWalletAddress(context.Background(), wallets, "")
+	var violation *policy.Violation
+	if !errors.As(err, &violation) || violation.Rule != policy.RuleUSDAmount {
+		t.Errorf("InterledgerWalletAddress() error = %v, want %v violation", err, policy.RuleUSDAmount)
+	}
+
+	// Without amount limits there is nothing to check the amount against.
+	unlimited, err := policy.NewEngine(policy.Policy{DeniedCountries: []string{"GH"}}, policy.WithClock(now))
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	if _, err := payouts.New(policy.Wrap(client, unlimited)).InterledgerWalletAddress(context.Background(), wallets, ""); err != nil {
+		t.Errorf("InterledgerWalletAddress() unexpected error = %v", err)
+	}
+	if sent != 1 {
+		t.Errorf("unexpected requests sent: got %d want 1", sent)
+	}
+}
+
+func TestBusinessHoursOvernight(t *testing.T) {
+	tests := []struct {
+		name string
+		at   time.Time
+		want bool
+	}{
+		{name: "Monday evening", at: time.Date(2024, 1, 1, 23, 0, 0, 0, time.UTC), want: true},
+		{name: "Friday night, into Saturday", at: time.Date(2024, 1, 6, 2, 0, 0, 0, time.UTC), want: true},
+		{name: "Monday early morning, after Sunday", at: time.Date(2024, 1, 1, 2, 0, 0, 0, time.UTC)},
+		{name: "Tuesday midday", at: time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)},
+		{name: "end is exclusive", at: time.Date(2024, 1, 3, 6, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, err := policy.NewEngine(policy.Policy{BusinessHours: &policy.BusinessHours{
+				TimeZone: "UTC",
+				Start:    "22:00",
+				End:      "06:00",
+			}}, policy.WithClock(func() time.Time { return tt.at }))
+			if err != nil {
+				t.Fatalf("NewEngine() error = %v", err)
+			}
+			err = engine.Check(&policy.Operation{Path: "/payouts/bank"})
+			if (err == nil) != tt.want {
+				t.Errorf("Check() error = %v, want open %v", err, tt.want)
+			}
+		})
+	}
+
+	_, err := policy.NewEngine(policy.Policy{BusinessHours: &policy.BusinessHours{TimeZone: "UTC", Start: "09:00", End: "09:00"}})
+	if err == nil {
+		t.Error("NewEngine() with empty business hours succeeded")
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	data := `{
+		"maxItemUSD": 500,
+		"deniedCountries": ["KP"],
+		"businessHours": {"timeZone": "Africa/Accra", "days": ["Mon", "Tuesday"], "start": "08:00", "end": "18:00"}
+	}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := policy.LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if p.MaxItemUSD != 500 || len(p.DeniedCountries) != 1 || p.BusinessHours == nil {
+		t.Errorf("unexpected policy: %+v", p)
+	}
+	if _, err := policy.NewEngine(p); err != nil {
+		t.Errorf("NewEngine() error = %v", err)
+	}
+
+	p.BusinessHours.Days = []string{"Someday"}
+	if _, err := policy.NewEngine(p); err == nil {
+		t.Error("NewEngine() accepted an invalid business day")
+	}
+}
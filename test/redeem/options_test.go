@@ -0,0 +1,193 @@
+package redeem_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/chimoney/chimoney-go/modules/redeem"
+)
+
+func TestTypedRedeemOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		field   string
+		call    func(ctx context.Context, r *redeem.Redeem) (*redeem.RedeemResponse, error)
+		want    map[string]interface{}
+		wantErr error
+	}{
+		{
+			name:  "gift card",
+			path:  "/redeem/gift-card",
+			field: "redeemOptions",
+			call: func(ctx context.Context, r *redeem.Redeem) (*redeem.RedeemResponse, error) {
+				return r.GiftCard(ctx, &redeem.GiftCardRedeemRequest{
+					ChiRef: "chi_123",
+					Options: &redeem.GiftCardOptions{
+						ProductID:   "amazon-us",
+						CountryCode: "US",
+						Amount:      50,
+						Email:       "test@example.com",
+						Extra:       redeem.Extra{"giftMessage": "Enjoy"},
+					},
+				})
+			},
+			want: map[string]interface{}{
+				"productId": "amazon-us", "countryCode": "US", "amount": 50.0,
+				"email": "test@example.com", "giftMessage": "Enjoy",
+			},
+		},
+		{
+			name:  "mobile money",
+			path:  "/redeem/mobile-money",
+			field: "redeemOptions",
+			call: func(ctx context.Context, r *redeem.Redeem) (*redeem.RedeemResponse, error) {
+				return r.MobileMoney(ctx, &redeem.MobileMoneyRedeemRequest{
+					ChiRef:  "chi_123",
+					Options: &redeem.MobileMoneyOptions{PhoneNumber: "+2348123456789", CountryCode: "NG", Provider: "mtn"},
+				})
+			},
+			want: map[string]interface{}{"phoneNumber": "+2348123456789", "countryCode": "NG", "provider": "mtn"},
+		},
+		{
+			name:  "airtime meta",
+			path:  "/redeem/airtime",
+			field: "meta",
+			call: func(ctx context.Context, r *redeem.Redeem) (*redeem.RedeemResponse, error) {
+				return r.Airtime(ctx, &redeem.AirtimeRedeemRequest{
+					ChiRef:        "chi_123",
+					PhoneNumber:   "+2348123456789",
+					CountryToSend: "NG",
+					Options:       &redeem.AirtimeMeta{Note: "Test redemption"},
+				})
+			},
+			want: map[string]interface{}{"note": "Test redemption"},
+		},
+		{
+			name: "missing required gift card field",
+			call: func(ctx context.Context, r *redeem.Redeem) (*redeem.RedeemResponse, error) {
+				return r.GiftCard(ctx, &redeem.GiftCardRedeemRequest{
+					ChiRef:  "chi_123",
+					Options: &redeem.GiftCardOptions{ProductID: "amazon-us", Amount: 50},
+				})
+			},
+			wantErr: redeem.ErrInvalidRedeemData,
+		},
+		{
+			name: "missing mobile money provider",
+			call: func(ctx context.Context, r *redeem.Redeem) (*redeem.RedeemResponse, error) {
+				return r.MobileMoney(ctx, &redeem.MobileMoneyRedeemRequest{
+					ChiRef:  "chi_123",
+					Options: &redeem.MobileMoneyOptions{PhoneNumber: "+2348123456789", CountryCode: "NG"},
+				})
+			},
+			wantErr: redeem.ErrInvalidRedeemData,
+		},
+		{
+			name: "extra field repeats a typed field",
+			call: func(ctx context.Context, r *redeem.Redeem) (*redeem.RedeemResponse, error) {
+				return r.MobileMoney(ctx, &redeem.MobileMoneyRedeemRequest{
+					ChiRef: "chi_123",
+					Options: &redeem.MobileMoneyOptions{
+						PhoneNumber: "+2348123456789", CountryCode: "NG", Provider: "mtn",
+						Extra: redeem.Extra{"amount": 10},
+					},
+				})
+			},
+			wantErr: redeem.ErrInvalidRedeemData,
+		},
+		{
+			name: "typed and untyped options",
+			call: func(ctx context.Context, r *redeem.Redeem) (*redeem.RedeemResponse, error) {
+				return r.Airtime(ctx, &redeem.AirtimeRedeemRequest{
+					ChiRef:  "chi_123",
+					Meta:    map[string]interface{}{"note": "a"},
+					Options: &redeem.AirtimeMeta{Note: "b"},
+				})
+			},
+			wantErr: redeem.ErrInvalidRedeemData,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if r.URL.Path != tt.path {
+					t.Errorf("unexpected path: got %v want %v", r.URL.Path, tt.path)
+				}
+				var reqBody map[string]interface{}
+				if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+					t.Errorf("failed to decode request body: %v", err)
+				}
+				if _, ok := reqBody["Options"]; ok {
+					t.Error("typed options leaked into the request body")
+				}
+				if got := reqBody[tt.field]; !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("unexpected %s: got %v want %v", tt.field, got, tt.want)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"status":"success","data":{}}`))
+			})
+			defer server.Close()
+
+			_, err := tt.call(context.Background(), client.Redeem)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				if calls != 0 {
+					t.Error("invalid options were sent to the API")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestChimoneyItems(t *testing.T) {
+	var got []interface{}
+	server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var reqBody map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			t.Errorf("failed to decode request body: %v", err)
+		}
+		got, _ = reqBody["chimoneys"].([]interface{})
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"success","data":{}}`))
+	})
+	defer server.Close()
+
+	ctx := context.Background()
+	_, err := client.Redeem.ChimoneyItems(ctx, []redeem.ChimoneyItem{
+		{ID: "chi_123", Amount: 50, Email: "test1@example.com"},
+		{ID: "chi_789", PhoneNumber: "+2348123456789"},
+		{ID: "chi_456", Amount: 25, Twitter: "@testuser", Extra: redeem.Extra{"redirect_url": "https://example.com"}},
+	}, "sub_123")
+	if err != nil {
+		t.Fatalf("ChimoneyItems() error = %v", err)
+	}
+	want := []interface{}{
+		map[string]interface{}{"id": "chi_123", "amount": 50.0, "email": "test1@example.com"},
+		map[string]interface{}{"id": "chi_789", "phoneNumber": "+2348123456789"},
+		map[string]interface{}{"id": "chi_456", "amount": 25.0, "twitter": "@testuser", "redirect_url": "https://example.com"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected chimoneys: got %v want %v", got, want)
+	}
+
+	if _, err := client.Redeem.ChimoneyItems(ctx, []redeem.ChimoneyItem{{Amount: 5}}, ""); !errors.Is(err, redeem.ErrInvalidRedeemData) {
+		t.Errorf("ChimoneyItems() error = %v, want %v", err, redeem.ErrInvalidRedeemData)
+	}
+	if _, err := client.Redeem.ChimoneyItems(ctx, nil, ""); !errors.Is(err, redeem.ErrInvalidRedeemData) {
+		t.Errorf("ChimoneyItems() error = %v, want %v", err, redeem.ErrInvalidRedeemData)
+	}
+}
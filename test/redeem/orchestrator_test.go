@@ -0,0 +1,196 @@
+package redeem_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/chimoney/chimoney-go/modules/redeem"
+)
+
+func TestOrchestrator(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	unredeemed := `{"status":"success","data":{"chiRef":"chi_123","status":"issued","valueInUSD":"20","expiresAt":"2026-04-01T00:00:00Z"}}`
+	redeemed := `{"status":"success","data":{"chiRef":"chi_123","status":"redeemed","valueInUSD":20}}`
+
+	tests := []struct {
+		name        string
+		chimoney    string
+		confirm     string
+		intent      redeem.RedeemIntent
+		selector    redeem.ChannelSelector
+		redeemResp  string
+		wantPath    string
+		wantChannel redeem.Channel
+		wantStatus  redeem.ReceiptStatus
+		wantErr     error
+		wantStep    string
+	}{
+		{
+			name:     "airtime",
+			chimoney: unredeemed,
+			confirm:  redeemed,
+			intent: redeem.RedeemIntent{
+				ChiRef: "chi_123", PhoneNumber: "+2348123456789", CountryToSend: "NG",
+			},
+			wantPath:    "/redeem/airtime",
+			wantChannel: redeem.ChannelAirtime,
+			wantStatus:  redeem.ReceiptRedeemed,
+		},
+		{
+			name:     "mobile money preferred over airtime",
+			chimoney: unredeemed,
+			confirm:  redeemed,
+			intent: redeem.RedeemIntent{
+				ChiRef: "chi_123", PhoneNumber: "+2348123456789", CountryToSend: "NG",
+				MobileMoney: &redeem.MobileMoneyOptions{PhoneNumber: "+2348123456789", CountryCode: "NG", Provider: "mtn"},
+			},
+			wantPath:    "/redeem/mobile-money",
+			wantChannel: redeem.ChannelMobileMoney,
+			wantStatus:  redeem.ReceiptRedeemed,
+		},
+		{
+			name:     "any with several items, not yet confirmed",
+			chimoney: unredeemed,
+			confirm:  unredeemed,
+			intent: redeem.RedeemIntent{
+				ChiRef: "chi_123",
+				RedeemData: []redeem.RedeemDataItem{
+					{CountryCode: "US", ProductID: "amazon-us", ValueInLocalCurrency: 10},
+					{CountryCode: "US", ProductID: "netflix-us", ValueInLocalCurrency: 10},
+				},
+			},
+			wantPath:    "/redeem/any",
+			wantChannel: redeem.ChannelAny,
+			wantStatus:  redeem.ReceiptPending,
+		},
+		{
+			name:     "custom selector",
+			chimoney: unredeemed,
+			confirm:  redeemed,
+			intent: redeem.RedeemIntent{
+				ChiRef:      "chi_123",
+				GiftCard:    &redeem.GiftCardOptions{ProductID: "amazon-us", CountryCode: "US", Amount: 20, Email: "ada@example.com"},
+				MobileMoney: &redeem.MobileMoneyOptions{PhoneNumber: "+2348123456789", CountryCode: "NG", Provider: "mtn"},
+			},
+			selector: redeem.ChannelSelectorFunc(func(ctx context.Context, intent *redeem.RedeemIntent, c *redeem.Chimoney) (redeem.Channel, error) {
+				if c.ValueInUSD < 50 {
+					return redeem.ChannelMobileMoney, nil
+				}
+				return redeem.ChannelGiftCard, nil
+			}),
+			wantPath:    "/redeem/mobile-money",
+			wantChannel: redeem.ChannelMobileMoney,
+			wantStatus:  redeem.ReceiptRedeemed,
+		},
+		{
+			name:     "already redeemed",
+			chimoney: redeemed,
+			intent:   redeem.RedeemIntent{ChiRef: "chi_123", PhoneNumber: "+2348123456789", CountryToSend: "NG"},
+			wantErr:  redeem.ErrAlreadyRedeemed,
+			wantStep: "check",
+		},
+		{
+			name:     "expired",
+			chimoney: `{"status":"success","data":{"chiRef":"chi_123","valueInUSD":20,"expiryDate":{"_seconds":1767225600}}}`,
+			intent:   redeem.RedeemIntent{ChiRef: "chi_123", PhoneNumber: "+2348123456789", CountryToSend: "NG"},
+			wantErr:  redeem.ErrExpired,
+			wantStep: "check",
+		},
+		{
+			name:     "insufficient value",
+			chimoney: unredeemed,
+			intent:   redeem.RedeemIntent{ChiRef: "chi_123", MinValueInUSD: 25, PhoneNumber: "+2348123456789", CountryToSend: "NG"},
+			wantErr:  redeem.ErrInsufficientValue,
+			wantStep: "check",
+		},
+		{
+			name:     "no value",
+			chimoney: `{"status":"success","data":{"chiRef":"chi_123","status":"issued"}}`,
+			intent:   redeem.RedeemIntent{ChiRef: "chi_123", PhoneNumber: "+2348123456789", CountryToSend: "NG"},
+			wantErr:  redeem.ErrInsufficientValue,
+			wantStep: "check",
+		},
+		{
+			name:     "no channel",
+			chimoney: unredeemed,
+			intent:   redeem.RedeemIntent{ChiRef: "chi_123"},
+			wantErr:  redeem.ErrNoChannel,
+			wantStep: "select",
+		},
+		{
+			name:     "forced channel without details",
+			chimoney: unredeemed,
+			intent:   redeem.RedeemIntent{ChiRef: "chi_123", Channel: redeem.ChannelGiftCard},
+			wantErr:  redeem.ErrNoChannel,
+			wantStep: "execute",
+		},
+		{
+			name:     "invalid typed options",
+			chimoney: unredeemed,
+			intent: redeem.RedeemIntent{
+				ChiRef: "chi_123", MobileMoney: &redeem.MobileMoneyOptions{PhoneNumber: "+2348123456789"},
+			},
+			wantErr:  redeem.ErrInvalidRedeemData,
+			wantStep: "execute",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetches := 0
+			var redeemPath string
+			server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				if r.URL.Path == "/redeem/chimoney/get" {
+					fetches++
+					if fetches == 1 {
+						w.Write([]byte(tt.chimoney))
+					} else {
+						w.Write([]byte(tt.confirm))
+					}
+					return
+				}
+				redeemPath = r.URL.Path
+				w.Write([]byte(`{"status":"success","data":{"id":"red_123","status":"pending"}}`))
+			})
+			defer server.Close()
+
+			opts := []redeem.OrchestratorOption{redeem.WithClock(func() time.Time { return now })}
+			if tt.selector != nil {
+				opts = append(opts, redeem.WithChannelSelector(tt.selector))
+			}
+			o := redeem.NewOrchestrator(client.Redeem, opts...)
+
+			receipt, err := o.Redeem(context.Background(), &tt.intent)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Redeem() error = %v, want %v", err, tt.wantErr)
+				}
+				var rerr *redeem.RedemptionError
+				if !errors.As(err, &rerr) || rerr.Step != tt.wantStep {
+					t.Errorf("unexpected failed step: %v, want %s", err, tt.wantStep)
+				}
+				if redeemPath != "" {
+					t.Errorf("redemption was sent to %s", redeemPath)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Redeem() error = %v", err)
+			}
+			if redeemPath != tt.wantPath {
+				t.Errorf("unexpected path: got %v want %v", redeemPath, tt.wantPath)
+			}
+			if receipt.Channel != tt.wantChannel || receipt.Status != tt.wantStatus ||
+				receipt.ValueInUSD != 20 || receipt.ChiRef != "chi_123" {
+				t.Errorf("unexpected receipt: %+v", receipt)
+			}
+			if !receipt.RedeemedAt.Equal(now) {
+				t.Errorf("unexpected redeemedAt: %v", receipt.RedeemedAt)
+			}
+		})
+	}
+}
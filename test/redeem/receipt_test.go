@@ -0,0 +1,161 @@
+package redeem_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chimoney/chimoney-go/modules/payouts"
+	"github.com/chimoney/chimoney-go/modules/redeem"
+	"github.com/chimoney/chimoney-go/modules/redeem/receipt"
+)
+
+var giftCardRedemption = &redeem.Receipt{
+	ChiRef:     "chi_123",
+	Channel:    redeem.ChannelGiftCard,
+	Status:     redeem.ReceiptRedeemed,
+	ValueInUSD: 50,
+	Recipient:  "test@example.com",
+	RedeemedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+	Response: &redeem.RedeemResponse{
+		Status: "success",
+		Data: json.RawMessage(`{"id":"red_123","status":"pending","giftCard":{
+			"code":"ABCD-EFGH-1234","pin":"9876","amount":"50","currency":"USD","email":"test@example.com","productName":"Amazon US"}}`),
+	},
+}
+
+func TestReceiptText(t *testing.T) {
+	rc, err := receipt.FromRedemption(giftCardRedemption)
+	if err != nil {
+		t.Fatalf("FromRedemption() error = %v", err)
+	}
+
+	r, err := receipt.New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	text, err := r.Text(rc)
+	if err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+	want := `Chimoney redemption receipt
+ChiRef:    chi_123
+Channel:   gift_card
+Status:    redeemed
+Amount:    50.00 USD (50.00 USD)
+Date:      2026-03-01 12:00:00 UTC
+Recipient: test@example.com
+Reference: red_123
+Gift card: Amazon US
+Sent to: test@example.com
+Gift card code: ****-****-1234
+PIN: ****
+`
+	if text != want {
+		t.Errorf("unexpected text receipt:\n%s\nwant:\n%s", text, want)
+	}
+
+	revealed, _ := receipt.New(receipt.Reveal())
+	text, _ = revealed.Text(rc)
+	if !strings.Contains(text, "ABCD-EFGH-1234") || !strings.Contains(text, "PIN: 9876") {
+		t.Errorf("expected revealed values:\n%s", text)
+	}
+}
+
+func TestReceiptHTMLAndJSON(t *testing.T) {
+	rc, err := receipt.FromRedemption(&redeem.Receipt{
+		ChiRef:     "chi_456",
+		Channel:    redeem.ChannelMobileMoney,
+		ValueInUSD: 10,
+		Recipient:  "<script>alert(1)</script>",
+		Response: &redeem.RedeemResponse{Data: json.RawMessage(`{"id":"red_456",
+			"transaction":{"reference":"mm_ref_1","amount":15000,"currency":"NGN","provider":"mtn"}}`)},
+	})
+	if err != nil {
+		t.Fatalf("FromRedemption() error = %v", err)
+	}
+
+	r, _ := receipt.New(receipt.WithLocation(time.FixedZone("WAT", 3600)))
+	html, err := r.HTML(rc)
+	if err != nil {
+		t.Fatalf("HTML() error = %v", err)
+	}
+	if strings.Contains(html, "<script>") || !strings.Contains(html, "10.00 USD (15000.00 NGN)") {
+		t.Errorf("unexpected HTML receipt:\n%s", html)
+	}
+
+	data, err := r.JSON(rc)
+	if err != nil {
+		t.Fatalf("JSON() error = %v", err)
+	}
+	var v receipt.View
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatalf("invalid JSON receipt: %v", err)
+	}
+	if v.Reference != "red_456" || v.LocalAmount != 15000 || v.LocalCurrency != "NGN" || v.Channel != "mobile_money" ||
+		len(v.Details) != 1 || v.Details[0].Value != "mtn" {
+		t.Errorf("unexpected JSON receipt: %s", data)
+	}
+}
+
+func TestReceiptTemplates(t *testing.T) {
+	rc, _ := receipt.FromRedemption(giftCardRedemption)
+
+	r, err := receipt.New(receipt.WithTextTemplate(`{{.ChiRef}} {{money .ValueInUSD "USD"}}{{range .Details}}|{{.Value}}{{end}}`))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	text, _ := r.Text(rc)
+	if text != "chi_123 50.00 USD|Amazon US|test@example.com|****-****-1234|****" {
+		t.Errorf("unexpected custom receipt: %q", text)
+	}
+
+	if _, err := receipt.New(receipt.WithHTMLTemplate(`{{.ChiRef`)); err == nil {
+		t.Error("expected an error for an invalid template")
+	}
+}
+
+func TestPayoutReceipts(t *testing.T) {
+	resp := &payouts.PayoutResponse{Data: json.RawMessage(`{"issueID":"iss_1","chimoneys":[
+		{"chiRef":"chi_1","valueInUSD":25,"status":"paid","email":"ada@example.com","redeemLink":"https://dash.chimoney.io/redeem?chi=chi_1"},
+		{"chiRef":"chi_2","valueInUSD":5,"status":"failed","phoneNumber":"+2348123456789","error":"invalid number"}
+	]}`)}
+	result, err := resp.Result()
+	if err != nil {
+		t.Fatalf("Result() error = %v", err)
+	}
+
+	receipts := receipt.FromPayout(result, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	if len(receipts) != 2 {
+		t.Fatalf("unexpected receipts: %+v", receipts)
+	}
+
+	r, _ := receipt.New()
+	text, err := r.Text(receipts[0])
+	if err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+	if !strings.HasPrefix(text, "Chimoney payout receipt\n") || !strings.Contains(text, "Reference: iss_1") ||
+		!strings.Contains(text, "Recipient: ada@example.com") || strings.Contains(text, "chi=chi_1") {
+		t.Errorf("unexpected payout receipt:\n%s", text)
+	}
+	text, _ = r.Text(receipts[1])
+	if !strings.Contains(text, "Recipient: +2348123456789") || !strings.Contains(text, "Error: invalid number") {
+		t.Errorf("unexpected payout receipt:\n%s", text)
+	}
+}
+
+func TestMask(t *testing.T) {
+	tests := map[string]string{
+		"":               "",
+		"1234":           "****",
+		"ABCDEFGH":       "****EFGH",
+		"ABCD EFGH 1234": "**** **** 1234",
+	}
+	for in, want := range tests {
+		if got := receipt.Mask(in); got != want {
+			t.Errorf("Mask(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
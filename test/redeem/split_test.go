@@ -0,0 +1,220 @@
+package redeem_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/chimoney/chimoney-go/modules/payouts/giftcards"
+	"github.com/chimoney/chimoney-go/modules/redeem"
+	"github.com/chimoney/chimoney-go/modules/redeem/split"
+)
+
+const splitAssets = `{
+	"status": "success",
+	"data": {
+		"benefitsList": [
+			{
+				"productId": 5,
+				"productName": "Amazon US",
+				"brand": {"brandId": 2, "brandName": "Amazon"},
+				"type": "giftcard",
+				"countryCode": "US",
+				"recipientCurrencyCode": "USD",
+				"fixedRecipientDenominations": [10, 25, 50]
+			},
+			{
+				"productId": "18",
+				"productName": "Jumia Nigeria",
+				"brand": "Jumia",
+				"type": "giftcard",
+				"countryCode": "NG",
+				"recipientCurrencyCode": "NGN",
+				"minRecipientDenomination": 5000,
+				"maxRecipientDenomination": 100000
+			},
+			{
+				"productId": 99,
+				"productName": "MTN Airtime",
+				"brand": "MTN",
+				"type": "airtime",
+				"countryCode": "NG",
+				"recipientCurrencyCode": "NGN"
+			}
+		]
+	}
+}`
+
+func TestSplitBuilder(t *testing.T) {
+	airtime := split.Allocation{Kind: split.Airtime, ValueInUSD: 10, Brand: "MTN", Country: "NG", PhoneNumber: "+2348012345678"}
+
+	tests := []struct {
+		name          string
+		chimoney      string
+		split         split.Split
+		wantItems     []redeem.RedeemDataItem
+		wantRemainder float64
+		wantErr       error
+	}{
+		{
+			name: "allocations add up",
+			split: split.Split{Allocations: []split.Allocation{
+				airtime,
+				{ValueInUSD: 40, ProductID: "18"},
+			}},
+			wantItems: []redeem.RedeemDataItem{
+				{CountryCode: "NG", ProductID: "99", ValueInLocalCurrency: 15000, PhoneNumber: "+2348012345678"},
+				{CountryCode: "NG", ProductID: "18", ValueInLocalCurrency: 60000},
+			},
+		},
+		{
+			name:    "remainder rejected",
+			split:   split.Split{Allocations: []split.Allocation{airtime}},
+			wantErr: split.ErrUnallocated,
+		},
+		{
+			name: "remainder left unredeemed",
+			split: split.Split{
+				Allocations: []split.Allocation{
+					airtime,
+					{ValueInUSD: 40, Brand: "amazon", Snap: giftcards.Down},
+				},
+				Remainder: split.Leave,
+			},
+			wantItems: []redeem.RedeemDataItem{
+				{CountryCode: "NG", ProductID: "99", ValueInLocalCurrency: 15000, PhoneNumber: "+2348012345678"},
+				{CountryCode: "US", ProductID: "5", ValueInLocalCurrency: 25},
+			},
+			wantRemainder: 15,
+		},
+		{
+			name: "remainder to default channel",
+			split: split.Split{
+				Allocations: []split.Allocation{
+					airtime,
+					{ValueInUSD: 25, Brand: "Amazon", Country: "US"},
+				},
+				Remainder: split.ToDefault,
+				Default:   &split.Allocation{ProductID: "18"},
+			},
+			wantItems: []redeem.RedeemDataItem{
+				{CountryCode: "NG", ProductID: "99", ValueInLocalCurrency: 15000, PhoneNumber: "+2348012345678"},
+				{CountryCode: "US", ProductID: "5", ValueInLocalCurrency: 25},
+				{CountryCode: "NG", ProductID: "18", ValueInLocalCurrency: 22500},
+			},
+		},
+		{
+			name:    "allocations exceed the value",
+			split:   split.Split{Allocations: []split.Allocation{airtime, {ValueInUSD: 45, ProductID: "18"}}},
+			wantErr: redeem.ErrInsufficientValue,
+		},
+		{
+			name: "snapping up exceeds the value",
+			split: split.Split{Allocations: []split.Allocation{
+				{ValueInUSD: 45, ProductID: "5", Snap: giftcards.Up},
+				{Kind: split.Airtime, ValueInUSD: 5, ProductID: "99", Country: "NG", PhoneNumber: "+2348012345678"},
+			}},
+			wantErr: redeem.ErrInsufficientValue,
+		},
+		{
+			name:    "amount not allowed",
+			split:   split.Split{Allocations: []split.Allocation{{ValueInUSD: 40, ProductID: "5"}, {ValueInUSD: 10, ProductID: "18"}}},
+			wantErr: giftcards.ErrInvalidDenomination,
+		},
+		{
+			name:    "allocation without value",
+			split:   split.Split{Allocations: []split.Allocation{{ValueInUSD: 50, ProductID: "18"}, {ProductID: "5"}}},
+			wantErr: split.ErrInvalidAllocation,
+		},
+		{
+			name:    "unknown product",
+			split:   split.Split{Allocations: []split.Allocation{{ValueInUSD: 50, ProductID: "404"}}},
+			wantErr: giftcards.ErrProductNotFound,
+		},
+		{
+			name:     "already redeemed",
+			chimoney: `{"status":"success","data":{"chiRef":"chi_123","status":"redeemed","valueInUSD":50}}`,
+			split:    split.Split{Allocations: []split.Allocation{{ValueInUSD: 50, ProductID: "18"}}},
+			wantErr:  redeem.ErrAlreadyRedeemed,
+		},
+		{
+			name:     "expired",
+			chimoney: `{"status":"success","data":{"chiRef":"chi_123","valueInUSD":50,"expiresAt":"2020-01-01T00:00:00Z"}}`,
+			split:    split.Split{Allocations: []split.Allocation{{ValueInUSD: 50, ProductID: "18"}}},
+			wantErr:  redeem.ErrExpired,
+		},
+		{
+			name:    "airtime without a phone number",
+			split:   split.Split{Allocations: []split.Allocation{{Kind: split.Airtime, ValueInUSD: 50, ProductID: "99", Country: "NG"}}},
+			wantErr: split.ErrInvalidAllocation,
+		},
+		{
+			name: "airtime default without a country",
+			split: split.Split{
+				Allocations: []split.Allocation{{ValueInUSD: 40, ProductID: "18"}},
+				Remainder:   split.ToDefault,
+				Default:     &split.Allocation{Kind: split.Airtime, ProductID: "99", PhoneNumber: "+2348012345678"},
+			},
+			wantErr: split.ErrInvalidAllocation,
+		},
+		{
+			name:    "default missing",
+			split:   split.Split{Allocations: []split.Allocation{airtime}, Remainder: split.ToDefault},
+			wantErr: split.ErrInvalidAllocation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/redeem/chimoney/get":
+					if tt.chimoney != "" {
+						w.Write([]byte(tt.chimoney))
+						return
+					}
+					w.Write([]byte(`{"status":"success","data":{"chiRef":"chi_123","status":"issued","valueInUSD":50}}`))
+				case "/info/assets":
+					w.Write([]byte(splitAssets))
+				case "/info/usd-in-local-amount":
+					var reqBody struct {
+						AmountInUSD float64 `json:"amountInUSD"`
+					}
+					json.NewDecoder(r.Body).Decode(&reqBody)
+					fmt.Fprintf(w, `{"status":"success","data":{"amountInDestinationCurrency":%v}}`, 1500*reqBody.AmountInUSD)
+				default:
+					t.Errorf("unexpected path: %v", r.URL.Path)
+				}
+			})
+			defer server.Close()
+
+			tt.split.ChiRef = "chi_123"
+			plan, err := split.New(client.Redeem, client.Info).Build(context.Background(), tt.split)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Build() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+
+			req := plan.Request()
+			if req.ChiRef != "chi_123" || !reflect.DeepEqual(req.RedeemData, tt.wantItems) {
+				t.Errorf("unexpected redeem data: got %+v want %+v", req.RedeemData, tt.wantItems)
+			}
+			if plan.Remainder != tt.wantRemainder {
+				t.Errorf("unexpected remainder: got %v want %v", plan.Remainder, tt.wantRemainder)
+			}
+			if intent := plan.Intent(); intent.Channel != redeem.ChannelAny || len(intent.RedeemData) != len(tt.wantItems) {
+				t.Errorf("unexpected intent: %+v", intent)
+			}
+		})
+	}
+}
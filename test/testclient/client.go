@@ -7,16 +7,31 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	neturl "net/url"
 
 	"github.com/chimoney/chimoney-go/modules/account"
 	"github.com/chimoney/chimoney-go/modules/info"
 	"github.com/chimoney/chimoney-go/modules/mobilemoney"
+	"github.com/chimoney/chimoney-go/modules/payments"
 	"github.com/chimoney/chimoney-go/modules/payouts"
 	"github.com/chimoney/chimoney-go/modules/redeem"
 	"github.com/chimoney/chimoney-go/modules/subaccount"
 	"github.com/chimoney/chimoney-go/modules/wallet"
 )
 
+// StatusError mirrors chimoney.StatusError for the test client.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("chimoney: request failed with status %d", e.StatusCode)
+}
+
+func (e *StatusError) HTTPStatus() int {
+	return e.StatusCode
+}
+
 type Client struct {
 	apiKey  string
 	baseURL string
@@ -25,6 +40,7 @@ type Client struct {
 	Account     *account.Account
 	Info        *info.Info
 	MobileMoney *mobilemoney.MobileMoney
+	Payments    *payments.Payments
 	Payouts     *payouts.Payouts
 	Redeem      *redeem.Redeem
 	SubAccount  *subaccount.SubAccount
@@ -47,6 +63,7 @@ func New(options ...Option) *Client {
 	c.Account = account.New(c)
 	c.Info = info.New(c)
 	c.MobileMoney = mobilemoney.New(c)
+	c.Payments = payments.New(c)
 	c.Payouts = payouts.New(c)
 	c.Redeem = redeem.New(c)
 	c.SubAccount = subaccount.New(c)
@@ -74,11 +91,35 @@ func WithHTTPClient(client *http.Client) Option {
 }
 
 func (c *Client) Do(ctx context.Context, method, path string, body interface{}, v interface{}, params map[string]string) error {
+	resp, err := c.send(ctx, method, path, body, params)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if v != nil {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Client) Stream(ctx context.Context, method, path string, body interface{}, params map[string]string) (io.ReadCloser, error) {
+	resp, err := c.send(ctx, method, path, body, params)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (c *Client) send(ctx context.Context, method, path string, body interface{}, params map[string]string) (*http.Response, error) {
 	var reqBody io.Reader
 	if body != nil {
 		b, err := json.Marshal(body)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		reqBody = bytes.NewReader(b)
 	}
@@ -87,14 +128,14 @@ func (c *Client) Do(ctx context.Context, method, path string, body interface{},
 	if params != nil && len(params) > 0 {
 		url += "?"
 		for k, v := range params {
-			url += fmt.Sprintf("%s=%s&", k, v)
+			url += fmt.Sprintf("%s=%s&", neturl.QueryEscape(k), neturl.QueryEscape(v))
 		}
 		url = url[:len(url)-1]
 	}
 
 	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -102,19 +143,13 @@ func (c *Client) Do(ctx context.Context, method, path string, body interface{},
 
 	resp, err := c.http.Do(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
-		return fmt.Errorf("chimoney: request failed with status %d", resp.StatusCode)
-	}
-
-	if v != nil {
-		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
-			return err
-		}
+		resp.Body.Close()
+		return nil, &StatusError{StatusCode: resp.StatusCode}
 	}
 
-	return nil
+	return resp, nil
 }
@@ -0,0 +1,291 @@
+package webhooks_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/chimoney/chimoney-go/modules/webhooks"
+)
+
+func TestStoreDeduplication(t *testing.T) {
+	stores := map[string]func(t *testing.T) webhooks.Store{
+		"memory": func(t *testing.T) webhooks.Store { return webhooks.NewMemoryStore() },
+		"file": func(t *testing.T) webhooks.Store {
+			s, err := webhooks.NewFileStore(t.TempDir())
+			if err != nil {
+				t.Fatalf("NewFileStore() error = %v", err)
+			}
+			return s
+		},
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			calls := 0
+			h := webhooks.New(secret, webhooks.WithClock(func() time.Time { return now }), webhooks.WithStore(store))
+			h.Handle(webhooks.PayoutCompleted, func(ctx context.Context, e *webhooks.Event) error {
+				calls++
+				return nil
+			})
+
+			// Chimoney signs every delivery of the same event again.
+			body := `{"id":"evt_1","type":"payout.completed","data":{"chiRef":"chi_1"}}`
+			for i := 0; i < 3; i++ {
+				if w := deliver(t, h, body, now.Add(time.Duration(i)*time.Second), secret); w.Code != http.StatusOK {
+					t.Fatalf("delivery %d: got %v", i, w.Code)
+				}
+			}
+			if calls != 1 {
+				t.Errorf("unexpected handler calls: got %d want 1", calls)
+			}
+
+			records, err := store.List(webhooks.Filter{})
+			if err != nil || len(records) != 1 {
+				t.Fatalf("List() = %v, %v", records, err)
+			}
+			r := records[0]
+			if r.EventID != "evt_1" || r.Status != webhooks.StatusProcessed || r.Deliveries != 3 ||
+				r.Attempts != 1 || string(r.Payload) != body {
+				t.Errorf("unexpected record: %+v", r)
+			}
+		})
+	}
+}
+
+func TestStoreDeadLetterAndReplay(t *testing.T) {
+	clock := now
+	store, err := webhooks.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	broken := true
+	var handled []string
+	h := webhooks.New(secret,
+		webhooks.WithClock(func() time.Time { return clock }),
+		webhooks.WithStore(store),
+		webhooks.WithMaxAttempts(2),
+	)
+	h.Handle(webhooks.WalletCredited, func(ctx context.Context, e *webhooks.Event) error {
+		if broken {
+			return errors.New("bug")
+		}
+		handled = append(handled, e.ID)
+		return nil
+	})
+	h.Handle(webhooks.PayoutCompleted, func(ctx context.Context, e *webhooks.Event) error {
+		handled = append(handled, e.ID)
+		return nil
+	})
+
+	wallet := `{"id":"evt_w","type":"wallet.credited","data":{"walletId":"w_1","amount":5}}`
+	if w := deliver(t, h, wallet, clock, secret); w.Code != http.StatusInternalServerError {
+		t.Fatalf("first attempt: got %v", w.Code)
+	}
+	clock = clock.Add(time.Minute)
+	// The last attempt moves the event to the dead-letter queue and stops retries.
+	if w := deliver(t, h, wallet, clock, secret); w.Code != http.StatusOK {
+		t.Fatalf("last attempt: got %v", w.Code)
+	}
+	if w := deliver(t, h, wallet, clock.Add(time.Second), secret); w.Code != http.StatusOK {
+		t.Fatalf("delivery after dead letter: got %v", w.Code)
+	}
+
+	dead, err := h.DeadLetters()
+	if err != nil || len(dead) != 1 || dead[0].EventID != "evt_w" || dead[0].LastError != "bug" {
+		t.Fatalf("DeadLetters() = %+v, %v", dead, err)
+	}
+
+	clock = clock.Add(time.Hour)
+	payout := `{"id":"evt_p","type":"payout.completed","data":{"chiRef":"chi_1"}}`
+	if w := deliver(t, h, payout, clock, secret); w.Code != http.StatusOK {
+		t.Fatalf("payout delivery: got %v", w.Code)
+	}
+
+	// Replaying by type only picks the wallet event, even though it is dead.
+	broken = false
+	handled = nil
+	result, err := h.Replay(context.Background(), webhooks.Filter{Types: []webhooks.EventType{webhooks.WalletCredited}})
+	if err != nil || len(result.Processed) != 1 || len(result.Failed) != 0 {
+		t.Fatalf("Replay() = %+v, %v", result, err)
+	}
+	if len(handled) != 1 || handled[0] != "evt_w" {
+		t.Errorf("unexpected replayed events: %v", handled)
+	}
+	if dead, _ := h.DeadLetters(); len(dead) != 0 {
+		t.Errorf("expected the dead-letter queue to be empty, got %+v", dead)
+	}
+
+	// Replaying by time range picks the payout only.
+	handled = nil
+	result, err = h.Replay(context.Background(), webhooks.Filter{From: now.Add(30 * time.Minute)})
+	if err != nil || len(result.Processed) != 1 || len(handled) != 1 || handled[0] != "evt_p" {
+		t.Errorf("Replay() = %+v, %v, handled %v", result, err, handled)
+	}
+}
+
+func TestStoreLease(t *testing.T) {
+	store := webhooks.NewMemoryStore()
+	h := webhooks.New(secret, webhooks.WithClock(func() time.Time { return now }), webhooks.WithStore(store))
+
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	var calls int32
+	h.Handle(webhooks.PayoutCompleted, func(ctx context.Context, e *webhooks.Event) error {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			close(started)
+			<-unblock
+		}
+		return nil
+	})
+
+	body := `{"id":"evt_1","type":"payout.completed","data":{"chiRef":"chi_1"}}`
+	first := make(chan int)
+	go func() {
+		first <- deliver(t, h, body, now, secret).Code
+	}()
+	<-started
+
+	// While the first delivery is handled, neither a retry nor a replay runs the handler.
+	if w := deliver(t, h, body, now.Add(time.Second), secret); w.Code != http.StatusConflict {
+		t.Errorf("delivery in flight: got %v want %v", w.Code, http.StatusConflict)
+	}
+	result, err := h.ProcessPending(context.Background())
+	if err != nil || len(result.Skipped) != 1 || len(result.Processed) != 0 {
+		t.Errorf("ProcessPending() in flight = %+v, %v", result, err)
+	}
+
+	close(unblock)
+	if code := <-first; code != http.StatusOK {
+		t.Fatalf("first delivery: got %v", code)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("unexpected handler calls: got %d want 1", n)
+	}
+
+	// The delivery counted while the event was handled is kept.
+	records, err := store.List(webhooks.Filter{})
+	if err != nil || len(records) != 1 {
+		t.Fatalf("List() = %v, %v", records, err)
+	}
+	if r := records[0]; r.Deliveries != 2 || r.Attempts != 1 || r.Status != webhooks.StatusProcessed || !r.LeasedUntil.IsZero() {
+		t.Errorf("unexpected record: %+v", r)
+	}
+}
+
+func TestProcessPending(t *testing.T) {
+	dir := t.TempDir()
+	ready := false
+	h, err := webhooks.Open(dir, secret, webhooks.WithClock(func() time.Time { return now }))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	h.Handle(webhooks.CollectionSuccessful, func(ctx context.Context, e *webhooks.Event) error {
+		if !ready {
+			return errors.New("collection not committed yet")
+		}
+		return nil
+	})
+
+	body := `{"type":"collection.successful","data":{"id":"mm_1","tx_ref":"tx_1"}}`
+	if w := deliver(t, h, body, now, secret); w.Code != http.StatusInternalServerError {
+		t.Fatalf("delivery: got %v", w.Code)
+	}
+
+	// A new handler on the same directory resumes from the stored checkpoint.
+	ready = true
+	h2, err := webhooks.Open(dir, secret, webhooks.WithClock(func() time.Time { return now }))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	h2.Handle(webhooks.CollectionSuccessful, func(ctx context.Context, e *webhooks.Event) error { return nil })
+
+	result, err := h2.ProcessPending(context.Background())
+	if err != nil || len(result.Processed) != 1 {
+		t.Fatalf("ProcessPending() = %+v, %v", result, err)
+	}
+	if result, _ := h2.ProcessPending(context.Background()); len(result.Processed) != 0 {
+		t.Errorf("expected nothing left to process, got %+v", result)
+	}
+
+	if _, err := webhooks.New(secret).Replay(context.Background(), webhooks.Filter{}); !errors.Is(err, webhooks.ErrNoStore) {
+		t.Errorf("Replay() error = %v, want %v", err, webhooks.ErrNoStore)
+	}
+}
+
+func TestStorePrune(t *testing.T) {
+	dir := t.TempDir()
+	stores := map[string]func(t *testing.T) webhooks.Store{
+		"memory": func(t *testing.T) webhooks.Store { return webhooks.NewMemoryStore() },
+		"file": func(t *testing.T) webhooks.Store {
+			s, err := webhooks.NewFileStore(dir)
+			if err != nil {
+				t.Fatalf("NewFileStore() error = %v", err)
+			}
+			return s
+		},
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			records := []webhooks.Record{
+				{Key: "old-processed", Status: webhooks.StatusProcessed, ReceivedAt: now.Add(-48 * time.Hour)},
+				{Key: "old-dead", Status: webhooks.StatusDead, ReceivedAt: now.Add(-48 * time.Hour)},
+				{Key: "old-pending", Status: webhooks.StatusPending, ReceivedAt: now.Add(-48 * time.Hour)},
+				{Key: "new-processed", Status: webhooks.StatusProcessed, ReceivedAt: now},
+			}
+			for _, r := range records {
+				if _, _, err := store.Insert(r); err != nil {
+					t.Fatalf("Insert() error = %v", err)
+				}
+			}
+
+			h := webhooks.New(secret, webhooks.WithStore(store))
+			n, err := h.Prune(webhooks.Filter{To: now.Add(-24 * time.Hour)})
+			if err != nil || n != 2 {
+				t.Fatalf("Prune() = %d, %v", n, err)
+			}
+			left, err := store.List(webhooks.Filter{})
+			if err != nil || len(left) != 2 || left[0].Key != "old-pending" || left[1].Key != "new-processed" {
+				t.Errorf("List() after prune = %+v, %v", left, err)
+			}
+		})
+	}
+
+	if _, err := webhooks.New(secret).Prune(webhooks.Filter{}); !errors.Is(err, webhooks.ErrNoStore) {
+		t.Errorf("Prune() without store error = %v, want %v", err, webhooks.ErrNoStore)
+	}
+}
+
+func TestFileStoreCorruptFiles(t *testing.T) {
+	dir := t.TempDir()
+	store, err := webhooks.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	if _, _, err := store.Insert(webhooks.Record{Key: "evt_1", Status: webhooks.StatusProcessed, ReceivedAt: now}); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "truncated.json"), []byte(`{"key":"evt_`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// A corrupt file is set aside rather than failing every listing.
+	for i := 0; i < 2; i++ {
+		records, err := store.List(webhooks.Filter{})
+		if err != nil || len(records) != 1 || records[0].Key != "evt_1" {
+			t.Fatalf("List() = %+v, %v", records, err)
+		}
+	}
+	corrupt, err := store.Corrupt()
+	if err != nil || len(corrupt) != 1 || filepath.Base(corrupt[0]) != "truncated.json.corrupt" {
+		t.Errorf("Corrupt() = %v, %v", corrupt, err)
+	}
+}
@@ -0,0 +1,271 @@
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chimoney/chimoney-go/modules/webhooks"
+)
+
+const secret = "whsec_test"
+
+var now = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func deliver(t *testing.T, h http.Handler, body string, signedAt time.Time, secret string) *httptest.ResponseRecorder {
+	t.Helper()
+	ts := strconv.FormatInt(signedAt.Unix(), 10)
+	r := httptest.NewRequest(http.MethodPost, "/webhooks/chimoney", strings.NewReader(body))
+	r.Header.Set(webhooks.TimestampHeader, ts)
+	r.Header.Set(webhooks.SignatureHeader, "sha256="+webhooks.Sign(secret, ts, []byte(body)))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestHandlerStatusCodes(t *testing.T) {
+	payout := `{"id":"evt_1","type":"payout.completed","createdAt":"2026-03-01T11:59:00Z",
+		"data":{"chiRef":"chi_1","issueID":"iss_1","status":"paid","valueInUSD":25}}`
+
+	tests := []struct {
+		name     string
+		body     string
+		signedAt time.Time
+		secret   string
+		fail     bool
+		want     int
+	}{
+		{name: "valid", body: payout, signedAt: now, secret: secret, want: http.StatusOK},
+		{name: "wrong secret", body: payout, signedAt: now, secret: "other", want: http.StatusUnauthorized},
+		{name: "stale timestamp", body: payout, signedAt: now.Add(-10 * time.Minute), secret: secret, want: http.StatusUnauthorized},
+		{name: "future timestamp", body: payout, signedAt: now.Add(10 * time.Minute), secret: secret, want: http.StatusUnauthorized},
+		{name: "malformed event", body: `{"data":{}}`, signedAt: now, secret: secret, want: http.StatusBadRequest},
+		{name: "unhandled type is acknowledged", body: `{"type":"account.updated","data":{}}`, signedAt: now, secret: secret, want: http.StatusOK},
+		{name: "handler error is retried", body: payout, signedAt: now, secret: secret, fail: true, want: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := webhooks.New(secret, webhooks.WithClock(func() time.Time { return now }))
+			h.Handle(webhooks.PayoutCompleted, func(ctx context.Context, e *webhooks.Event) error {
+				if tt.fail {
+					return errors.New("database down")
+				}
+				return nil
+			})
+			if w := deliver(t, h, tt.body, tt.signedAt, tt.secret); w.Code != tt.want {
+				t.Errorf("unexpected status: got %v want %v (%s)", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandlerRequests(t *testing.T) {
+	h := webhooks.New(secret, webhooks.WithClock(func() time.Time { return now }), webhooks.WithMaxBodySize(64))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET: got %v want %v", w.Code, http.StatusMethodNotAllowed)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("unsigned: got %v want %v", w.Code, http.StatusUnauthorized)
+	}
+
+	if w := deliver(t, h, `{"type":"payout.completed","data":{"chiRef":"`+strings.Repeat("x", 64)+`"}}`, now, secret); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("large body: got %v want %v", w.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func TestHandlerReplay(t *testing.T) {
+	calls := 0
+	fail := true
+	h := webhooks.New(secret, webhooks.WithClock(func() time.Time { return now }))
+	h.Handle(webhooks.WalletCredited, func(ctx context.Context, e *webhooks.Event) error {
+		calls++
+		if fail {
+			return errors.New("try again")
+		}
+		return nil
+	})
+
+	body := `{"id":"evt_2","type":"wallet.credited","data":{"walletId":"w_1","amount":10}}`
+	if w := deliver(t, h, body, now, secret); w.Code != http.StatusInternalServerError {
+		t.Fatalf("first delivery: got %v", w.Code)
+	}
+	// A failed delivery can be retried with the same signature.
+	fail = false
+	if w := deliver(t, h, body, now, secret); w.Code != http.StatusOK {
+		t.Fatalf("retry: got %v", w.Code)
+	}
+	// A handled delivery sent again is acknowledged without being handled.
+	if w := deliver(t, h, body, now, secret); w.Code != http.StatusOK {
+		t.Errorf("replay: got %v want %v", w.Code, http.StatusOK)
+	}
+	if calls != 2 {
+		t.Errorf("unexpected handler calls: got %d want 2", calls)
+	}
+
+	// The same signature in upper case is still a replay.
+	ts := strconv.FormatInt(now.Unix(), 10)
+	r := httptest.NewRequest(http.MethodPost, "/webhooks/chimoney", strings.NewReader(body))
+	r.Header.Set(webhooks.TimestampHeader, ts)
+	r.Header.Set(webhooks.SignatureHeader, "sha256="+strings.ToUpper(webhooks.Sign(secret, ts, []byte(body))))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Errorf("re-cased replay: got %v want %v", w.Code, http.StatusOK)
+	}
+	if calls != 2 {
+		t.Errorf("unexpected handler calls after re-cased replay: got %d want 2", calls)
+	}
+}
+
+func TestHandlerReplayInFlight(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []webhooks.Option
+	}{
+		{name: "without store"},
+		{name: "with store", options: []webhooks.Option{webhooks.WithStore(webhooks.NewMemoryStore())}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started, unblock := make(chan struct{}), make(chan struct{})
+			calls := 0
+			h := webhooks.New(secret, append(tt.options, webhooks.WithClock(func() time.Time { return now }))...)
+			h.Handle(webhooks.WalletCredited, func(ctx context.Context, e *webhooks.Event) error {
+				calls++
+				close(started)
+				<-unblock
+				return nil
+			})
+
+			body := `{"id":"evt_3","type":"wallet.credited","data":{"walletId":"w_1","amount":10}}`
+			first := make(chan int)
+			go func() {
+				first <- deliver(t, h, body, now, secret).Code
+			}()
+			<-started
+
+			if w := deliver(t, h, body, now, secret); w.Code != http.StatusConflict {
+				t.Errorf("delivery in flight: got %v want %v", w.Code, http.StatusConflict)
+			}
+			close(unblock)
+			if code := <-first; code != http.StatusOK {
+				t.Fatalf("first delivery: got %v", code)
+			}
+			if w := deliver(t, h, body, now, secret); w.Code != http.StatusOK {
+				t.Errorf("delivery after handling: got %v want %v", w.Code, http.StatusOK)
+			}
+			if calls != 1 {
+				t.Errorf("unexpected handler calls: got %d want 1", calls)
+			}
+		})
+	}
+}
+
+func TestTypedEvents(t *testing.T) {
+	got := map[webhooks.EventType]interface{}{}
+	h := webhooks.New(secret, webhooks.WithClock(func() time.Time { return now }))
+	h.Handle(webhooks.PayoutFailed, func(ctx context.Context, e *webhooks.Event) error {
+		p, err := e.Payout()
+		got[e.Type] = p
+		return err
+	})
+	h.Handle(webhooks.CollectionSuccessful, func(ctx context.Context, e *webhooks.Event) error {
+		c, err := e.Collection()
+		got[e.Type] = c
+		return err
+	})
+	h.HandleDefault(func(ctx context.Context, e *webhooks.Event) error {
+		var v interface{}
+		var err error
+		switch e.Type {
+		case webhooks.RedeemCompleted:
+			v, err = e.Redeem()
+		case webhooks.WalletCredited:
+			v, err = e.Wallet()
+		}
+		got[e.Type] = v
+		return err
+	})
+
+	deliveries := []string{
+		`{"eventId":"evt_3","event":"PAYOUT.FAILED","createdAt":1772366400,"data":{"chiRef":"chi_2","status":"failed","reason":"invalid account"}}`,
+		`{"id":"evt_4","type":"collection.successful","data":{"id":"mm_1","tx_ref":"tx_1","status":"successful","amount":50,"currency":"GHS"}}`,
+		`{"id":"evt_5","type":"redeem.completed","data":{"chiRef":"chi_3","valueInUSD":5,"redeemedBy":"ada@example.com"}}`,
+		`{"id":"evt_6","type":"wallet.credited","data":{"walletId":"w_1","amount":10,"currency":"USD","balance":110}}`,
+	}
+	for i, body := range deliveries {
+		if w := deliver(t, h, body, now.Add(time.Duration(i)*time.Second), secret); w.Code != http.StatusOK {
+			t.Fatalf("delivery %d: got %v (%s)", i, w.Code, w.Body.String())
+		}
+	}
+
+	if p, ok := got[webhooks.PayoutFailed].(*webhooks.PayoutEvent); !ok || p.ChiRef != "chi_2" || p.Reason != "invalid account" {
+		t.Errorf("unexpected payout event: %+v", got[webhooks.PayoutFailed])
+	}
+	if c, ok := got[webhooks.CollectionSuccessful].(*webhooks.CollectionEvent); !ok || c.TxRef != "tx_1" || c.Amount != 50 {
+		t.Errorf("unexpected collection event: %+v", got[webhooks.CollectionSuccessful])
+	}
+	if r, ok := got[webhooks.RedeemCompleted].(*webhooks.RedeemEvent); !ok || r.ChiRef != "chi_3" || r.ValueInUSD != 5 {
+		t.Errorf("unexpected redeem event: %+v", got[webhooks.RedeemCompleted])
+	}
+	if w, ok := got[webhooks.WalletCredited].(*webhooks.WalletEvent); !ok || w.WalletID != "w_1" || w.Balance != 110 {
+		t.Errorf("unexpected wallet event: %+v", got[webhooks.WalletCredited])
+	}
+
+	e := &webhooks.Event{Type: webhooks.WalletCredited, Data: []byte(`{}`)}
+	if _, err := e.Payout(); err == nil {
+		t.Error("expected an error decoding a wallet event as a payout")
+	}
+}
+
+func TestEventCreatedAt(t *testing.T) {
+	want := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		createdAt string
+		want      time.Time
+	}{
+		{name: "RFC 3339", createdAt: `"2026-03-01T12:00:00Z"`, want: want},
+		{name: "without zone", createdAt: `"2026-03-01 12:00:00"`, want: want},
+		{name: "epoch seconds", createdAt: `1772366400`, want: want},
+		{name: "epoch milliseconds", createdAt: `1772366400000`, want: want},
+		{name: "epoch seconds as string", createdAt: `"1772366400"`, want: want},
+		{name: "firestore timestamp", createdAt: `{"_seconds":1772366400,"_nanoseconds":0}`, want: want},
+		{name: "unreadable", createdAt: `"yesterday"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var e webhooks.Event
+			body := `{"id":"evt_1","type":"wallet.credited","createdAt":` + tt.createdAt + `}`
+			if err := json.Unmarshal([]byte(body), &e); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if !e.CreatedAt.Equal(tt.want) {
+				t.Errorf("CreatedAt = %v, want %v", e.CreatedAt, tt.want)
+			}
+		})
+	}
+
+	// A signed event is accepted whatever its createdAt.
+	h := webhooks.New(secret, webhooks.WithClock(func() time.Time { return now }))
+	if w := deliver(t, h, `{"id":"evt_9","type":"wallet.credited","createdAt":"yesterday"}`, now, secret); w.Code != http.StatusOK {
+		t.Errorf("delivery with unreadable createdAt: got %v want %v", w.Code, http.StatusOK)
+	}
+}